@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+// 获取RPC节点健康状态
+type GetRpcHealthReq struct {
+	g.Meta `path:"/admin/rpc/health" method:"get" tags:"运维管理" summary:"RPC节点健康状态"`
+}
+
+type GetRpcHealthRes struct {
+	List []*model.RpcEndpointHealth `json:"list" dc:"节点列表, 仅包含已创建连接的链"`
+}
+
+// 获取后台任务状态
+type GetWorkerJobsReq struct {
+	g.Meta `path:"/admin/worker/jobs" method:"get" tags:"运维管理" summary:"后台任务状态"`
+}
+
+type GetWorkerJobsRes struct {
+	List []*model.WorkerJob `json:"list" dc:"任务列表, holder为当前leader实例, state为running/idle/stopped/lost"`
+}
+
+// 创建重新索引任务
+type CreateReindexReq struct {
+	g.Meta     `path:"/admin/reindex/create" method:"post" tags:"运维管理" summary:"创建重新索引任务"`
+	ChainId    uint64 `dc:"链ID, 指定合约时可不填"`
+	ContractId uint64 `dc:"合约ID, 不填表示链上全部事件来源(包括跨链桥)"`
+	Event      string `dc:"事件名称, 需同时指定合约, 不填表示全部事件"`
+	FromBlock  uint64 `v:"required" dc:"起始区块"`
+	ToBlock    uint64 `dc:"结束区块, 不填表示当前已确认高度"`
+}
+
+type CreateReindexRes struct {
+	Job *model.ReindexJob `json:"job" dc:"重新索引任务, 由worker在后台执行"`
+}
+
+// 获取重新索引任务详情
+type GetReindexReq struct {
+	g.Meta `path:"/admin/reindex/detail" method:"get" tags:"运维管理" summary:"重新索引任务详情"`
+	Id     uint64 `v:"required" dc:"任务ID"`
+}
+
+type GetReindexRes struct {
+	Job *model.ReindexJob `json:"job" dc:"重新索引任务, nextBlock为下一个待处理的区块"`
+}
+
+// 获取重新索引任务列表
+type GetReindexesReq struct {
+	g.Meta   `path:"/admin/reindex/list" method:"get" tags:"运维管理" summary:"重新索引任务列表"`
+	Page     int `d:"1" dc:"页码"`
+	PageSize int `d:"10" dc:"每页数量"`
+}
+
+type GetReindexesRes struct {
+	List  []*model.ReindexJob `json:"list" dc:"任务列表"`
+	Total int                 `json:"total" dc:"总数"`
+}
+
+// 取消重新索引任务
+type CancelReindexReq struct {
+	g.Meta `path:"/admin/reindex/cancel" method:"post" tags:"运维管理" summary:"取消重新索引任务"`
+	Id     uint64 `v:"required" dc:"任务ID"`
+}
+
+type CancelReindexRes struct{}
+
+// 获取处理失败的事件日志列表
+type GetDeadLettersReq struct {
+	g.Meta   `path:"/admin/deadletter/list" method:"get" tags:"运维管理" summary:"事件死信列表"`
+	ChainId  uint64 `dc:"链ID, 不填表示全部链"`
+	Status   int    `d:"-1" dc:"状态: 0待重试 1重试成功 2已丢弃 3达到最大重试次数 4重试中, 不填表示全部"`
+	Page     int    `d:"1" dc:"页码"`
+	PageSize int    `d:"10" dc:"每页数量"`
+}
+
+type GetDeadLettersRes struct {
+	List  []*model.EventDeadLetter `json:"list" dc:"死信列表"`
+	Total int                      `json:"total" dc:"总数"`
+}
+
+// 获取处理失败的事件日志详情
+type GetDeadLetterReq struct {
+	g.Meta `path:"/admin/deadletter/detail" method:"get" tags:"运维管理" summary:"事件死信详情"`
+	Id     uint64 `v:"required" dc:"死信ID"`
+}
+
+type GetDeadLetterRes struct {
+	Letter *model.EventDeadLetter `json:"letter" dc:"死信, 包含日志内容、最近一次错误与执行次数"`
+}
+
+// 立即重试处理失败的事件日志
+type RetryDeadLetterReq struct {
+	g.Meta `path:"/admin/deadletter/retry" method:"post" tags:"运维管理" summary:"重试事件死信"`
+	Id     uint64 `v:"required" dc:"死信ID"`
+}
+
+type RetryDeadLetterRes struct {
+	Letter *model.EventDeadLetter `json:"letter" dc:"重试后的死信, status为1表示重试成功"`
+}
+
+// 丢弃处理失败的事件日志
+type DiscardDeadLetterReq struct {
+	g.Meta `path:"/admin/deadletter/discard" method:"post" tags:"运维管理" summary:"丢弃事件死信"`
+	Id     uint64 `v:"required" dc:"死信ID"`
+}
+
+type DiscardDeadLetterRes struct{}
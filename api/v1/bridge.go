@@ -10,7 +10,8 @@ type CrossTransferReq struct {
 	FromAddress  string `v:"required" dc:"来源地址"`
 	ToAddress    string `v:"required" dc:"目标地址"`
 	TokenAddress string `dc:"代币地址(空表示原生代币)"`
-	Amount       string `v:"required" dc:"金额"`
+	Amount       string `v:"required" dc:"金额, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit         string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 }
 
 type CrossTransferRes struct {
@@ -35,16 +36,18 @@ type GetCrossTransferRes struct {
 }
 
 type CrossTransferInfo struct {
-	Id           uint64 `json:"id" dc:"ID"`
-	FromChainId  uint64 `json:"fromChainId" dc:"来源链ID"`
-	ToChainId    uint64 `json:"toChainId" dc:"目标链ID"`
-	FromAddress  string `json:"fromAddress" dc:"来源地址"`
-	ToAddress    string `json:"toAddress" dc:"目标地址"`
-	TokenAddress string `json:"tokenAddress" dc:"代币地址"`
-	Amount       string `json:"amount" dc:"金额"`
-	Fee          string `json:"fee" dc:"手续费"`
-	FromHash     string `json:"fromHash" dc:"来源链交易哈希"`
-	ToHash       string `json:"toHash" dc:"目标链交易哈希"`
-	Status       int    `json:"status" dc:"状态"`
-	CreatedAt    int64  `json:"createdAt" dc:"创建时间"`
+	Id              uint64 `json:"id" dc:"ID"`
+	FromChainId     uint64 `json:"fromChainId" dc:"来源链ID"`
+	ToChainId       uint64 `json:"toChainId" dc:"目标链ID"`
+	FromAddress     string `json:"fromAddress" dc:"来源地址"`
+	ToAddress       string `json:"toAddress" dc:"目标地址"`
+	TokenAddress    string `json:"tokenAddress" dc:"代币地址"`
+	Amount          string `json:"amount" dc:"金额(最小单位)"`
+	FormattedAmount string `json:"formattedAmount" dc:"按精度换算后的金额"`
+	Symbol          string `json:"symbol" dc:"代币符号"`
+	Fee             string `json:"fee" dc:"手续费"`
+	FromHash        string `json:"fromHash" dc:"来源链交易哈希"`
+	ToHash          string `json:"toHash" dc:"目标链交易哈希"`
+	Status          int    `json:"status" dc:"状态"`
+	CreatedAt       int64  `json:"createdAt" dc:"创建时间"`
 }
@@ -1,16 +1,19 @@
 package v1
 
-import "github.com/gogf/gf/v2/frame/g"
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
 
 // DeployContractReq 部署合约
 type DeployContractReq struct {
 	g.Meta   `path:"/contract/deploy" method:"post" tags:"合约管理" summary:"部署合约"`
+	ChainId  uint64 `v:"required" dc:"链ID"`
 	Name     string `v:"required" dc:"合约名称"`
 	ABI      string `v:"required" dc:"合约ABI"`
 	Bytecode string `v:"required" dc:"合约字节码"`
 	From     string `v:"required" dc:"部署地址"`
 	Args     string `dc:"构造参数(JSON)"`
-	Network  string `dc:"网络,默认ethereum"`
 }
 
 type DeployContractRes struct {
@@ -21,21 +24,25 @@ type DeployContractRes struct {
 // CallContractReq 调用合约
 type CallContractReq struct {
 	g.Meta  `path:"/contract/call" method:"post" tags:"合约管理" summary:"调用合约"`
+	ChainId uint64 `v:"required" dc:"链ID"`
 	Address string `v:"required" dc:"合约地址"`
 	Method  string `v:"required" dc:"方法名称"`
 	Args    string `dc:"调用参数(JSON)"`
 	From    string `v:"required" dc:"调用地址"`
 	Value   string `dc:"调用金额(ETH)"`
+	DryRun  bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run)"`
 }
 
 type CallContractRes struct {
-	Hash   string      `json:"hash,omitempty" dc:"交易哈希(写入方法)"`
-	Result interface{} `json:"result,omitempty" dc:"返回结果(读取方法)"`
+	Hash       string                  `json:"hash,omitempty" dc:"交易哈希(写入方法)"`
+	Result     interface{}             `json:"result,omitempty" dc:"返回结果(读取方法)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // GetContractEventsReq 获取合约事件
 type GetContractEventsReq struct {
 	g.Meta    `path:"/contract/events" method:"get" tags:"合约管理" summary:"获取合约事件"`
+	ChainId   uint64 `v:"required" dc:"链ID"`
 	Address   string `v:"required" dc:"合约地址"`
 	EventName string `dc:"事件名称"`
 	FromBlock int64  `dc:"起始区块"`
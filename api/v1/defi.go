@@ -1,6 +1,9 @@
 package v1
 
-import "github.com/gogf/gf/v2/frame/g"
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
 
 // SwapTokenReq 代币兑换请求
 type SwapTokenReq struct {
@@ -8,15 +11,19 @@ type SwapTokenReq struct {
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	FromToken   string `v:"required" dc:"支付代币地址"`
 	ToToken     string `v:"required" dc:"获得代币地址"`
-	Amount      string `v:"required" dc:"兑换数量"`
+	Amount      string `v:"required" dc:"兑换数量, 默认为最小单位整数, unit为decimal时按支付代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"支付地址"`
 	SlippageBps int    `d:"30" dc:"滑点(万分之)"`
 	Type        string `d:"EXACT_INPUT" dc:"类型(EXACT_INPUT/EXACT_OUTPUT)"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type SwapTokenRes struct {
-	Hash      string `json:"hash" dc:"交易哈希"`
-	AmountOut string `json:"amountOut" dc:"获得数量"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	AmountOut  string                  `json:"amountOut" dc:"获得数量"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // AddLiquidityReq 添加流动性请求
@@ -25,15 +32,20 @@ type AddLiquidityReq struct {
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	TokenA      string `v:"required" dc:"代币A地址"`
 	TokenB      string `v:"required" dc:"代币B地址"`
-	AmountA     string `v:"required" dc:"代币A数量"`
-	AmountB     string `v:"required" dc:"代币B数量"`
+	AmountA     string `v:"required" dc:"代币A数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数"`
+	AmountB     string `v:"required" dc:"代币B数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"支付地址"`
 	SlippageBps int    `d:"30" dc:"滑点(万分之)"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type AddLiquidityRes struct {
-	Hash      string `json:"hash" dc:"交易哈希"`
-	Liquidity string `json:"liquidity" dc:"LP代币数量"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Liquidity  string                  `json:"liquidity" dc:"LP代币数量"`
+	InputA     *model.TokenAmount      `json:"inputA" dc:"代币A输入金额(最小单位与格式化金额)"`
+	InputB     *model.TokenAmount      `json:"inputB" dc:"代币B输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // RemoveLiquidityReq 移除流动性请求
@@ -41,15 +53,19 @@ type RemoveLiquidityReq struct {
 	g.Meta      `path:"/defi/liquidity/remove" method:"post" tags:"DeFi" summary:"移除流动性"`
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Pair        string `v:"required" dc:"交易对地址"`
-	Liquidity   string `v:"required" dc:"LP代币数量"`
+	Liquidity   string `v:"required" dc:"LP代币数量, 默认为最小单位整数, unit为decimal时按LP代币精度的十进制数"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"地址"`
 	SlippageBps int    `d:"30" dc:"滑点(万分之)"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type RemoveLiquidityRes struct {
-	Hash    string `json:"hash" dc:"交易哈希"`
-	Amount0 string `json:"amount0" dc:"代币0数量"`
-	Amount1 string `json:"amount1" dc:"代币1数量"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Amount0    string                  `json:"amount0" dc:"代币0数量"`
+	Amount1    string                  `json:"amount1" dc:"代币1数量"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // SupplyReq 存款请求
@@ -58,12 +74,16 @@ type SupplyReq struct {
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Pool        string `v:"required" dc:"借贷池地址"`
 	Token       string `v:"required" dc:"代币地址"`
-	Amount      string `v:"required" dc:"数量"`
+	Amount      string `v:"required" dc:"数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type SupplyRes struct {
-	Hash string `json:"hash" dc:"交易哈希"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // BorrowReq 借款请求
@@ -72,13 +92,17 @@ type BorrowReq struct {
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Pool        string `v:"required" dc:"借贷池地址"`
 	Token       string `v:"required" dc:"代币地址"`
-	Amount      string `v:"required" dc:"数量"`
+	Amount      string `v:"required" dc:"数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	RateMode    int    `d:"2" dc:"利率模式 1:稳定 2:浮动"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type BorrowRes struct {
-	Hash string `json:"hash" dc:"交易哈希"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // RepayReq 还款请求
@@ -87,13 +111,17 @@ type RepayReq struct {
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Pool        string `v:"required" dc:"借贷池地址"`
 	Token       string `v:"required" dc:"代币地址"`
-	Amount      string `v:"required" dc:"数量"`
+	Amount      string `v:"required" dc:"数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	RateMode    int    `d:"2" dc:"利率模式 1:稳定 2:浮动"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type RepayRes struct {
-	Hash string `json:"hash" dc:"交易哈希"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // StakeReq 质押请求
@@ -101,12 +129,16 @@ type StakeReq struct {
 	g.Meta      `path:"/defi/farm/stake" method:"post" tags:"DeFi" summary:"质押"`
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Pool        string `v:"required" dc:"矿池地址"`
-	Amount      string `v:"required" dc:"数量"`
+	Amount      string `v:"required" dc:"数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type StakeRes struct {
-	Hash string `json:"hash" dc:"交易哈希"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // UnstakeReq 解质押请求
@@ -114,12 +146,16 @@ type UnstakeReq struct {
 	g.Meta      `path:"/defi/farm/unstake" method:"post" tags:"DeFi" summary:"解质押"`
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Pool        string `v:"required" dc:"矿池地址"`
-	Amount      string `v:"required" dc:"数量"`
+	Amount      string `v:"required" dc:"数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type UnstakeRes struct {
-	Hash string `json:"hash" dc:"交易哈希"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // ClaimRewardReq 领取奖励请求
@@ -128,11 +164,13 @@ type ClaimRewardReq struct {
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Pool        string `v:"required" dc:"矿池地址"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type ClaimRewardRes struct {
-	Hash   string `json:"hash" dc:"交易哈希"`
-	Reward string `json:"reward" dc:"奖励数量"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Reward     string                  `json:"reward" dc:"奖励数量"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // DepositVaultReq 存入机枪池请求
@@ -140,13 +178,17 @@ type DepositVaultReq struct {
 	g.Meta      `path:"/defi/vault/deposit" method:"post" tags:"DeFi" summary:"存入机枪池"`
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Vault       string `v:"required" dc:"机枪池地址"`
-	Amount      string `v:"required" dc:"数量"`
+	Amount      string `v:"required" dc:"数量, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type DepositVaultRes struct {
-	Hash   string `json:"hash" dc:"交易哈希"`
-	Shares string `json:"shares" dc:"份额数量"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Shares     string                  `json:"shares" dc:"份额数量"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // WithdrawVaultReq 提取机枪池请求
@@ -154,11 +196,15 @@ type WithdrawVaultReq struct {
 	g.Meta      `path:"/defi/vault/withdraw" method:"post" tags:"DeFi" summary:"提取机枪池"`
 	ChainId     uint64 `v:"required" dc:"链ID"`
 	Vault       string `v:"required" dc:"机枪池地址"`
-	Shares      string `v:"required" dc:"份额数量"`
+	Shares      string `v:"required" dc:"份额数量, 默认为最小单位整数, unit为decimal时按机枪池份额精度的十进制数"`
+	Unit        string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 	FromAddress string `v:"required" dc:"地址"`
+	DryRun      bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run); 需先授权代币时返回授权交易的模拟结果, 主交易不模拟"`
 }
 
 type WithdrawVaultRes struct {
-	Hash   string `json:"hash" dc:"交易哈希"`
-	Amount string `json:"amount" dc:"提取数量"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Amount     string                  `json:"amount" dc:"提取数量"`
+	Input      *model.TokenAmount      `json:"input" dc:"输入金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+// 创建批量付款任务
+type CreatePayoutReq struct {
+	g.Meta  `path:"/payout/create" method:"post" tags:"批量付款" summary:"创建批量付款任务"`
+	ChainId uint64            `dc:"链ID, 默认使用链注册表的默认链"`
+	From    string            `v:"required" dc:"付款地址"`
+	Mode    string            `d:"SEQUENTIAL" dc:"执行方式 SEQUENTIAL:逐笔转账 DISPERSE:Disperse合约批量分发"`
+	Csv     string            `dc:"CSV内容, 列为 address,amount,token, 首行可为表头, token为空表示原生代币"`
+	Rows    []model.PayoutRow `dc:"付款行, 与Csv二选一"`
+}
+
+type CreatePayoutRes struct {
+	Job    *model.PayoutJob        `json:"job" dc:"批量付款任务, 存在无效行时为空"`
+	Errors []*model.PayoutRowError `json:"errors" dc:"无效行"`
+}
+
+// 获取批量付款任务详情
+type GetPayoutReq struct {
+	g.Meta `path:"/payout/detail" method:"get" tags:"批量付款" summary:"批量付款任务详情"`
+	Id     uint64 `v:"required" dc:"任务ID"`
+}
+
+type GetPayoutRes struct {
+	Job   *model.PayoutJob    `json:"job" dc:"批量付款任务"`
+	Items []*model.PayoutItem `json:"items" dc:"明细行"`
+}
+
+// 获取批量付款任务列表
+type GetPayoutsReq struct {
+	g.Meta   `path:"/payout/list" method:"get" tags:"批量付款" summary:"批量付款任务列表"`
+	UserId   uint64 `v:"required" dc:"用户ID"`
+	Page     int    `d:"1" dc:"页码"`
+	PageSize int    `d:"10" dc:"每页数量"`
+}
+
+type GetPayoutsRes struct {
+	List  []*model.PayoutJob `json:"list" dc:"任务列表"`
+	Total int                `json:"total" dc:"总数"`
+}
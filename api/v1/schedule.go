@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+// 创建定时转账
+type CreateScheduleReq struct {
+	g.Meta        `path:"/schedule/create" method:"post" tags:"定时转账" summary:"创建定时转账"`
+	ChainId       uint64 `v:"required" dc:"链ID"`
+	From          string `v:"required" dc:"付款地址"`
+	To            string `v:"required" dc:"收款地址"`
+	Token         string `dc:"代币合约地址, 为空时转账ETH"`
+	Amount        string `v:"required" dc:"每次转账金额, 默认为最小单位整数, unit为decimal时按代币或原生代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit          string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
+	Cron          string `v:"required" dc:"cron表达式: 分 时 日 月 周, 如 0 9 1 * * 表示每月1日9点, 按服务器时区"`
+	StartAt       int64  `dc:"开始时间(unix秒), 默认立即"`
+	EndAt         int64  `dc:"结束时间(unix秒), 默认不限"`
+	MaxRuns       int    `dc:"最大执行次数, 默认不限"`
+	MaxRetries    int    `d:"3" dc:"单次执行失败后的最大重试次数"`
+	RetryInterval int64  `d:"60" dc:"首次重试间隔(秒), 之后按指数退避"`
+	GasStrategy   string `d:"STANDARD" dc:"Gas策略 FASTEST/FAST/STANDARD/SLOW"`
+}
+
+type CreateScheduleRes struct {
+	Schedule *model.ScheduledTransfer `json:"schedule" dc:"定时转账"`
+	Amount   *model.TokenAmount       `json:"amount" dc:"每次转账金额"`
+}
+
+// 暂停定时转账
+type PauseScheduleReq struct {
+	g.Meta `path:"/schedule/pause" method:"post" tags:"定时转账" summary:"暂停定时转账"`
+	Id     uint64 `v:"required" dc:"定时转账ID"`
+}
+
+type PauseScheduleRes struct{}
+
+// 恢复定时转账
+type ResumeScheduleReq struct {
+	g.Meta `path:"/schedule/resume" method:"post" tags:"定时转账" summary:"恢复定时转账"`
+	Id     uint64 `v:"required" dc:"定时转账ID"`
+}
+
+type ResumeScheduleRes struct{}
+
+// 取消定时转账
+type CancelScheduleReq struct {
+	g.Meta `path:"/schedule/cancel" method:"post" tags:"定时转账" summary:"取消定时转账"`
+	Id     uint64 `v:"required" dc:"定时转账ID"`
+}
+
+type CancelScheduleRes struct{}
+
+// 获取定时转账列表
+type GetSchedulesReq struct {
+	g.Meta   `path:"/schedule/list" method:"get" tags:"定时转账" summary:"定时转账列表"`
+	UserId   uint64 `v:"required" dc:"用户ID"`
+	Page     int    `d:"1" dc:"页码"`
+	PageSize int    `d:"10" dc:"每页数量"`
+}
+
+type GetSchedulesRes struct {
+	List  []*model.ScheduledTransfer `json:"list" dc:"定时转账列表"`
+	Total int                        `json:"total" dc:"总数"`
+}
+
+// 获取定时转账执行记录
+type GetScheduleRunsReq struct {
+	g.Meta   `path:"/schedule/runs" method:"get" tags:"定时转账" summary:"定时转账执行记录"`
+	Id       uint64 `v:"required" dc:"定时转账ID"`
+	Page     int    `d:"1" dc:"页码"`
+	PageSize int    `d:"10" dc:"每页数量"`
+}
+
+type GetScheduleRunsRes struct {
+	List  []*model.ScheduledRun `json:"list" dc:"执行记录"`
+	Total int                   `json:"total" dc:"总数"`
+}
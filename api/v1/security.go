@@ -53,11 +53,13 @@ type CreateWhitelistRes struct{}
 type CreateTransactionLimitReq struct {
 	g.Meta       `path:"/security/limit/create" method:"post"`
 	UserId       uint64 `json:"user_id"        v:"required"`
+	ChainId      uint64 `json:"chain_id"       dc:"链ID, 用于解析代币精度, 默认使用链注册表的默认链"`
 	TokenAddress string `json:"token_address"  v:"required"`
-	SingleLimit  string `json:"single_limit"   v:"required"`
-	DailyLimit   string `json:"daily_limit"    v:"required"`
-	WeeklyLimit  string `json:"weekly_limit"   v:"required"`
-	MonthlyLimit string `json:"monthly_limit"  v:"required"`
+	SingleLimit  string `json:"single_limit"   v:"required" dc:"单笔限额, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	DailyLimit   string `json:"daily_limit"    v:"required" dc:"日限额, 默认为最小单位整数, unit为decimal时按代币精度的十进制数"`
+	WeeklyLimit  string `json:"weekly_limit"   v:"required" dc:"周限额, 默认为最小单位整数, unit为decimal时按代币精度的十进制数"`
+	MonthlyLimit string `json:"monthly_limit"  v:"required" dc:"月限额, 默认为最小单位整数, unit为decimal时按代币精度的十进制数"`
+	Unit         string `json:"unit"           v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
 }
 
 type CreateTransactionLimitRes struct{}
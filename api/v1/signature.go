@@ -0,0 +1,43 @@
+package v1
+
+import "github.com/gogf/gf/v2/frame/g"
+
+// SignMessageReq EIP-191消息签名
+type SignMessageReq struct {
+	g.Meta  `path:"/signature/sign-message" method:"post" tags:"签名管理" summary:"EIP-191消息签名(personal_sign)"`
+	Address string `v:"required" dc:"签名钱包地址"`
+	Message string `v:"required" dc:"消息内容, 0x开头的十六进制按字节签名"`
+}
+
+type SignMessageRes struct {
+	Signature string `json:"signature" dc:"签名"`
+}
+
+// SignTypedDataReq EIP-712结构化数据签名
+type SignTypedDataReq struct {
+	g.Meta    `path:"/signature/sign-typed-data" method:"post" tags:"签名管理" summary:"EIP-712结构化数据签名"`
+	Address   string `v:"required" dc:"签名钱包地址"`
+	TypedData string `v:"required|json" dc:"EIP-712结构化数据JSON"`
+}
+
+type SignTypedDataRes struct {
+	Signature string `json:"signature" dc:"签名"`
+	Hash      string `json:"hash" dc:"EIP-712摘要"`
+}
+
+// VerifySignatureReq 验证签名
+type VerifySignatureReq struct {
+	g.Meta    `path:"/signature/verify" method:"post" tags:"签名管理" summary:"验证签名"`
+	ChainId   uint64 `dc:"链ID, EIP-1271合约钱包验证时必填"`
+	Address   string `dc:"期望的签名地址, 为空时只恢复签名地址"`
+	Message   string `v:"required-without:TypedData" dc:"EIP-191消息内容"`
+	TypedData string `v:"required-without:Message" dc:"EIP-712结构化数据JSON"`
+	Signature string `v:"required" dc:"签名"`
+}
+
+type VerifySignatureRes struct {
+	Valid     bool   `json:"valid" dc:"签名是否有效"`
+	Method    string `json:"method" dc:"验证方式 ecrecover/eip1271"`
+	Recovered string `json:"recovered" dc:"ecrecover恢复出的地址"`
+	Hash      string `json:"hash" dc:"消息哈希"`
+}
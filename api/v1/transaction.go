@@ -2,34 +2,83 @@ package v1
 
 import (
 	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
 )
 
 // 转账ETH
 type TransferEthReq struct {
-	g.Meta   `path:"/transaction/transfer-eth" method:"post" tags:"交易管理" summary:"ETH转账"`
-	From     string `v:"required" dc:"发送地址"`
-	To       string `v:"required" dc:"接收地址"`
-	Amount   string `v:"required" dc:"转账金额(ETH)"`
-	GasPrice string `dc:"gas价格(gwei),默认建议价格"`
-	GasLimit uint64 `dc:"gas限制,默认21000"`
+	g.Meta               `path:"/transaction/transfer-eth" method:"post" tags:"交易管理" summary:"ETH转账"`
+	ChainId              uint64 `v:"required" dc:"链ID"`
+	From                 string `v:"required" dc:"发送地址"`
+	To                   string `v:"required" dc:"接收地址"`
+	Amount               string `v:"required" dc:"转账金额, 默认为最小单位整数, unit为decimal时按原生代币精度的十进制数, 如 1.5 或 1.5 ETH"`
+	Unit                 string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
+	GasPrice             string `dc:"gas价格(wei),指定时使用legacy交易"`
+	MaxFeePerGas         string `dc:"EIP-1559最大费用(wei),默认按Gas策略估算"`
+	MaxPriorityFeePerGas string `dc:"EIP-1559最大优先费用(wei),默认按Gas策略估算"`
+	GasStrategy          string `d:"STANDARD" dc:"Gas策略 FASTEST/FAST/STANDARD/SLOW"`
+	GasLimit             uint64 `dc:"gas限制,默认21000"`
+	DryRun               bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run)"`
 }
 
 type TransferEthRes struct {
-	Hash string `json:"hash" dc:"交易哈希"`
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Amount     *model.TokenAmount      `json:"amount" dc:"转账金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
 }
 
 // 转账代币
 type TransferTokenReq struct {
-	g.Meta   `path:"/transaction/transfer-token" method:"post" tags:"交易管理" summary:"代币转账"`
-	From     string `v:"required" dc:"发送地址"`
-	To       string `v:"required" dc:"接收地址"`
-	Amount   string `v:"required" dc:"转账金额"`
-	Token    string `v:"required" dc:"代币合约地址"`
-	GasPrice string `dc:"gas价格(gwei)"`
-	GasLimit uint64 `dc:"gas限制"`
+	g.Meta               `path:"/transaction/transfer-token" method:"post" tags:"交易管理" summary:"代币转账"`
+	ChainId              uint64 `v:"required" dc:"链ID"`
+	From                 string `v:"required" dc:"发送地址"`
+	To                   string `v:"required" dc:"接收地址"`
+	Amount               string `v:"required" dc:"转账金额, 默认为最小单位整数, unit为decimal时按代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit                 string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
+	Token                string `v:"required" dc:"代币合约地址"`
+	GasPrice             string `dc:"gas价格(wei),指定时使用legacy交易"`
+	MaxFeePerGas         string `dc:"EIP-1559最大费用(wei),默认按Gas策略估算"`
+	MaxPriorityFeePerGas string `dc:"EIP-1559最大优先费用(wei),默认按Gas策略估算"`
+	GasStrategy          string `d:"STANDARD" dc:"Gas策略 FASTEST/FAST/STANDARD/SLOW"`
+	GasLimit             uint64 `dc:"gas限制"`
+	DryRun               bool   `dc:"仅模拟执行, 返回模拟结果不发送交易(dry_run)"`
 }
 
 type TransferTokenRes struct {
+	Hash       string                  `json:"hash" dc:"交易哈希"`
+	Amount     *model.TokenAmount      `json:"amount" dc:"转账金额(最小单位与格式化金额)"`
+	Simulation *model.SimulationResult `json:"simulation,omitempty" dc:"模拟结果(dry_run)"`
+}
+
+// 生成待签名交易
+type BuildOfflineTxReq struct {
+	g.Meta               `path:"/transaction/offline/build" method:"post" tags:"交易管理" summary:"生成离线签名交易"`
+	ChainId              uint64 `dc:"链ID, 默认使用链注册表的默认链"`
+	From                 string `v:"required" dc:"发送地址"`
+	To                   string `dc:"接收地址或合约地址, 为空且Data非空时为合约创建"`
+	Token                string `dc:"代币合约地址, 非空时构建ERC20转账"`
+	Amount               string `dc:"金额, 默认为最小单位整数, unit为decimal时按代币或原生代币精度的十进制数, 如 1.5 或 1.5 USDC"`
+	Unit                 string `v:"in:decimal,raw" dc:"金额单位: decimal 按代币精度的十进制数, raw 最小单位整数(升级前的解析方式); 不填时使用配置amount.defaultUnit, 默认raw"`
+	Data                 string `dc:"合约调用数据(0x十六进制), 与Token互斥"`
+	GasPrice             string `dc:"gas价格(wei),指定时使用legacy交易"`
+	MaxFeePerGas         string `dc:"EIP-1559最大费用(wei),默认按Gas策略估算"`
+	MaxPriorityFeePerGas string `dc:"EIP-1559最大优先费用(wei),默认按Gas策略估算"`
+	GasStrategy          string `d:"STANDARD" dc:"Gas策略 FASTEST/FAST/STANDARD/SLOW"`
+	GasLimit             uint64 `dc:"gas限制,默认自动估算"`
+}
+
+type BuildOfflineTxRes struct {
+	Envelope *model.TxEnvelope `json:"envelope" dc:"待签名交易信封"`
+}
+
+// 提交外部签名交易
+type SubmitOfflineTxReq struct {
+	g.Meta `path:"/transaction/offline/submit" method:"post" tags:"交易管理" summary:"提交离线签名交易"`
+	Id     uint64 `v:"required" dc:"信封ID"`
+	RawTx  string `v:"required" dc:"已签名的原始交易(0x十六进制)"`
+}
+
+type SubmitOfflineTxRes struct {
 	Hash string `json:"hash" dc:"交易哈希"`
 }
 
@@ -47,13 +96,21 @@ type GetTransactionsRes struct {
 }
 
 type TransactionInfo struct {
-	Hash        string `json:"hash" dc:"交易哈希"`
-	From        string `json:"from" dc:"发送地址"`
-	To          string `json:"to" dc:"接收地址"`
-	Amount      string `json:"amount" dc:"转账金额"`
-	Token       string `json:"token" dc:"代币地址"`
-	Status      int    `json:"status" dc:"状态 0:待处理 1:已确认 2:失败"`
-	BlockNumber int64  `json:"blockNumber" dc:"区块高度"`
-	BlockTime   int64  `json:"blockTime" dc:"区块时间"`
-	CreatedAt   int64  `json:"createdAt" dc:"创建时间"`
+	Hash                 string `json:"hash" dc:"交易哈希"`
+	From                 string `json:"from" dc:"发送地址"`
+	To                   string `json:"to" dc:"接收地址"`
+	Amount               string `json:"amount" dc:"转账金额(最小单位)"`
+	FormattedAmount      string `json:"formattedAmount" dc:"按精度换算后的转账金额"`
+	Symbol               string `json:"symbol" dc:"代币符号"`
+	Token                string `json:"token" dc:"代币地址"`
+	Direction            string `json:"direction" dc:"方向 OUT:转出 IN:转入 INTERNAL:托管钱包之间"`
+	ChainId              uint64 `json:"chainId" dc:"链ID"`
+	TxType               int    `json:"txType" dc:"交易类型 0:legacy 2:EIP-1559"`
+	GasPrice             string `json:"gasPrice" dc:"gas价格(wei)"`
+	MaxFeePerGas         string `json:"maxFeePerGas" dc:"EIP-1559最大费用(wei)"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas" dc:"EIP-1559最大优先费用(wei)"`
+	Status               int    `json:"status" dc:"状态 0:待处理 1:已确认 2:失败"`
+	BlockNumber          int64  `json:"blockNumber" dc:"区块高度"`
+	BlockTime            int64  `json:"blockTime" dc:"区块时间"`
+	CreatedAt            int64  `json:"createdAt" dc:"创建时间"`
 }
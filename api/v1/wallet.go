@@ -2,6 +2,7 @@ package v1
 
 import (
 	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
 )
 
 type CreateWalletReq struct {
@@ -10,45 +11,156 @@ type CreateWalletReq struct {
 }
 
 type CreateWalletRes struct {
-	Address  string `json:"address" dc:"钱包地址"`
-	Mnemonic string `json:"mnemonic" dc:"助记词"`
+	Address        string `json:"address" dc:"钱包地址"`
+	Mnemonic       string `json:"mnemonic" dc:"助记词"`
+	DerivationPath string `json:"derivationPath" dc:"派生路径"`
 }
 
 type ImportWalletReq struct {
 	g.Meta   `path:"/wallet/import" method:"post" tags:"钱包管理" summary:"导入钱包"`
 	Chain    string `v:"required" dc:"链名称 ETH/BSC"`
 	Mnemonic string `v:"required" dc:"助记词"`
+	Index    uint32 `d:"0" dc:"账户索引 m/44'/60'/0'/0/index"`
 }
 
 type ImportWalletRes struct {
+	Address        string `json:"address" dc:"钱包地址"`
+	DerivationPath string `json:"derivationPath" dc:"派生路径"`
+}
+
+// 派生账户
+type DeriveWalletReq struct {
+	g.Meta  `path:"/wallet/derive" method:"post" tags:"钱包管理" summary:"从助记词派生账户"`
+	Address string `v:"required" dc:"已有助记词钱包地址"`
+	Index   uint32 `dc:"账户索引 m/44'/60'/0'/0/index"`
+}
+
+type DeriveWalletRes struct {
+	Address        string `json:"address" dc:"钱包地址"`
+	DerivationPath string `json:"derivationPath" dc:"派生路径"`
+}
+
+// 导入私钥
+type ImportPrivateKeyReq struct {
+	g.Meta     `path:"/wallet/import-private-key" method:"post" tags:"钱包管理" summary:"导入私钥"`
+	Chain      string `v:"required" dc:"链名称 ETH/BSC"`
+	PrivateKey string `v:"required" dc:"十六进制私钥"`
+}
+
+type ImportPrivateKeyRes struct {
 	Address string `json:"address" dc:"钱包地址"`
 }
 
+// 导入Keystore
+type ImportKeystoreReq struct {
+	g.Meta   `path:"/wallet/import-keystore" method:"post" tags:"钱包管理" summary:"导入V3 Keystore"`
+	Chain    string `v:"required" dc:"链名称 ETH/BSC"`
+	Keystore string `v:"required|json" dc:"Keystore JSON"`
+	Password string `v:"required" dc:"Keystore密码"`
+}
+
+type ImportKeystoreRes struct {
+	Address string `json:"address" dc:"钱包地址"`
+}
+
+// 导出Keystore
+type ExportKeystoreReq struct {
+	g.Meta   `path:"/wallet/export-keystore" method:"post" tags:"钱包管理" summary:"导出V3 Keystore"`
+	Address  string `v:"required" dc:"钱包地址"`
+	Password string `v:"required|length:8,128" dc:"Keystore加密密码"`
+}
+
+type ExportKeystoreRes struct {
+	Keystore string `json:"keystore" dc:"Keystore JSON"`
+}
+
 // 钱包列表
 type ListWalletReq struct {
-	g.Meta   `path:"/wallet/list" method:"get" tags:"钱包管理" summary:"钱包列表"`
-	Page     int `json:"page" d:"1" v:"min:1" dc:"页码"`
-	PageSize int `json:"pageSize" d:"10" v:"max:50" dc:"每页数量"`
+	g.Meta     `path:"/wallet/list" method:"get" tags:"钱包管理" summary:"钱包列表"`
+	Page       int    `json:"page" d:"1" v:"min:1" dc:"页码"`
+	PageSize   int    `json:"pageSize" d:"10" v:"max:50" dc:"每页数量"`
+	UserId     uint64 `json:"userId" dc:"用户ID"`
+	Chain      string `json:"chain" dc:"链名称"`
+	WalletType int    `json:"walletType" dc:"钱包类型 1:助记词 2:私钥导入 3:Keystore导入 4:观察钱包"`
+	GroupId    uint64 `json:"groupId" dc:"分组ID"`
+	Tag        string `json:"tag" dc:"标签"`
+	Label      string `json:"label" dc:"标签名称关键字"`
 }
 
 type ListWalletRes struct {
-	List []WalletInfo `json:"list" dc:"钱包列表"`
+	List  []WalletInfo `json:"list" dc:"钱包列表"`
+	Total int          `json:"total" dc:"总数"`
 }
 
 type WalletInfo struct {
-	Id        uint64 `json:"id"`
-	Address   string `json:"address"`
-	Chain     string `json:"chain"`
-	Balance   string `json:"balance"`
-	CreatedAt int64  `json:"createdAt"`
+	Id         uint64   `json:"id"`
+	Address    string   `json:"address"`
+	Chain      string   `json:"chain"`
+	Balance    string   `json:"balance"`
+	WalletType int      `json:"walletType"`
+	Label      string   `json:"label"`
+	Tags       []string `json:"tags"`
+	GroupId    uint64   `json:"groupId"`
+	CreatedAt  int64    `json:"createdAt"`
+}
+
+// 添加观察钱包
+type AddWatchWalletReq struct {
+	g.Meta  `path:"/wallet/watch" method:"post" tags:"钱包管理" summary:"添加观察钱包"`
+	Chain   string   `v:"required" dc:"链名称 ETH/BSC"`
+	Address string   `v:"required" dc:"观察地址"`
+	Label   string   `dc:"标签名称"`
+	Tags    []string `dc:"标签列表"`
+	GroupId uint64   `dc:"分组ID"`
+}
+
+type AddWatchWalletRes struct {
+	Address string `json:"address" dc:"钱包地址"`
+}
+
+// 更新钱包标签与分组
+type UpdateWalletMetaReq struct {
+	g.Meta  `path:"/wallet/meta" method:"post" tags:"钱包管理" summary:"更新钱包标签与分组"`
+	Address string   `v:"required" dc:"钱包地址"`
+	Label   string   `dc:"标签名称"`
+	Tags    []string `dc:"标签列表"`
+	GroupId uint64   `dc:"分组ID"`
+}
+
+type UpdateWalletMetaRes struct{}
+
+// 创建钱包分组
+type CreateWalletGroupReq struct {
+	g.Meta      `path:"/wallet/group/create" method:"post" tags:"钱包管理" summary:"创建钱包分组"`
+	UserId      uint64 `dc:"用户ID"`
+	Name        string `v:"required" dc:"分组名称"`
+	Description string `dc:"分组描述"`
+}
+
+type CreateWalletGroupRes struct {
+	Id uint64 `json:"id" dc:"分组ID"`
+}
+
+// 钱包分组列表
+type ListWalletGroupReq struct {
+	g.Meta `path:"/wallet/group/list" method:"get" tags:"钱包管理" summary:"钱包分组列表"`
+	UserId uint64 `json:"userId" dc:"用户ID"`
+}
+
+type ListWalletGroupRes struct {
+	List []*model.WalletGroup `json:"list" dc:"分组列表"`
 }
 
 // 查询余额
 type GetBalanceReq struct {
 	g.Meta  `path:"/wallet/balance" method:"get" tags:"钱包管理" summary:"查询余额"`
 	Address string `v:"required" dc:"钱包地址"`
+	Fiat    string `dc:"法币币种, 如USD, 为空时不计算估值"`
 }
 
 type GetBalanceRes struct {
-	Balance string `json:"balance" dc:"余额"`
+	Address      string                `json:"address" dc:"钱包地址"`
+	FiatCurrency string                `json:"fiatCurrency" dc:"法币币种"`
+	FiatValue    string                `json:"fiatValue" dc:"法币估值合计"`
+	Chains       []*model.ChainBalance `json:"chains" dc:"各链余额"`
 }
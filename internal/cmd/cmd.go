@@ -7,7 +7,9 @@ import (
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/gogf/gf/v2/os/gcmd"
 
+	"go-wallet-defi/internal/controller"
 	"go-wallet-defi/internal/controller/hello"
+	"go-wallet-defi/internal/service"
 )
 
 var (
@@ -16,15 +18,41 @@ var (
 		Usage: "main",
 		Brief: "start http server",
 		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
-			s := g.Server()
-			s.Group("/", func(group *ghttp.RouterGroup) {
-				group.Middleware(ghttp.MiddlewareHandlerResponse)
-				group.Bind(
-					hello.NewV1(),
-				)
-			})
-			s.Run()
+			if err := service.Chain().SyncRegistry(ctx); err != nil {
+				return err
+			}
+			if err := service.Bridge().CheckValidator(ctx); err != nil {
+				return err
+			}
+			runServer()
 			return nil
 		},
 	}
 )
+
+// runServer 注册路由并启动HTTP服务, 阻塞直到服务退出
+func runServer() {
+	s := g.Server()
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareHandlerResponse)
+		group.Bind(
+			hello.NewV1(),
+			&controller.AdminController{},
+			&controller.AnalysisController{},
+			&controller.BridgeController{},
+			&controller.ContractController{},
+			&controller.DecentralizedController{},
+			&controller.DefiController{},
+			&controller.GasController{},
+			&controller.NFTController{},
+			&controller.PayoutController{},
+			&controller.ProtocolController{},
+			&controller.ScheduleController{},
+			&controller.SecurityController{},
+			&controller.SignatureController{},
+			&controller.TransactionController{},
+			&controller.WalletController{},
+		)
+	})
+	s.Run()
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+
+	"github.com/gogf/gf/contrib/drivers/sqlite/v2"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/util/gconv"
+
+	"go-wallet-defi/internal/pkg/devchain"
+	"go-wallet-defi/internal/service"
+	"go-wallet-defi/internal/task"
+)
+
+// defaultDevConfig dev命令默认使用的配置文件
+const defaultDevConfig = "config.dev.yaml"
+
+var (
+	Dev = gcmd.Command{
+		Name:  "dev",
+		Usage: "dev [-config config.dev.yaml]",
+		Brief: "start http server against an in-process simulated chain with mock contracts and sqlite",
+		Arguments: []gcmd.Argument{
+			{Name: "config", Short: "c", Brief: "config file name, default config.dev.yaml"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			//1.切换到dev配置文件, 注册dev模式的SQLite驱动
+			adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+			if !ok {
+				return errors.New("dev command requires the file config adapter")
+			}
+			adapter.SetFileName(parser.GetOpt("config", defaultDevConfig).String())
+			if err := gdb.Register("sqlite", &devSqliteDriver{}); err != nil {
+				return err
+			}
+
+			//2.启动模拟链并部署模拟合约
+			mnemonic := g.Cfg().MustGet(ctx, "dev.mnemonic", devchain.DefaultMnemonic).String()
+			balance := new(big.Int).Mul(
+				big.NewInt(g.Cfg().MustGet(ctx, "dev.balance", 10000).Int64()),
+				big.NewInt(1e18),
+			)
+			node, err := devchain.Start(devchain.Config{
+				Mnemonic:  mnemonic,
+				Accounts:  g.Cfg().MustGet(ctx, "dev.accounts", 10).Int(),
+				Balance:   balance,
+				Host:      g.Cfg().MustGet(ctx, "dev.host", "127.0.0.1").String(),
+				HTTPPort:  g.Cfg().MustGet(ctx, "dev.httpPort", 8545).Int(),
+				WSPort:    g.Cfg().MustGet(ctx, "dev.wsPort", 8546).Int(),
+				BlockTime: g.Cfg().MustGet(ctx, "dev.blockTime", "2s").Duration(),
+			})
+			if err != nil {
+				return err
+			}
+			defer node.Close()
+
+			deployments, err := node.DeployMocks(ctx)
+			if err != nil {
+				return err
+			}
+			deployments = append(node.Predeployed, deployments...)
+
+			//3.初始化数据库
+			if err := service.Dev().Setup(ctx, node, deployments, mnemonic); err != nil {
+				return err
+			}
+			if err := service.Bridge().CheckValidator(ctx); err != nil {
+				return err
+			}
+
+			g.Log().Infof(ctx, "dev chain %s running at %s / %s", node.ChainId, node.HTTPURL, node.WSURL)
+			for _, account := range node.Accounts {
+				g.Log().Infof(ctx, "dev account #%d %s", account.Index, account.Address.Hex())
+			}
+			for _, deployment := range deployments {
+				g.Log().Infof(ctx, "dev contract %-22s %s", deployment.Name, deployment.Address.Hex())
+			}
+
+			//4.后台任务
+			if g.Cfg().MustGet(ctx, "dev.tasks", true).Bool() {
+				go func() {
+					if err := task.RunWorker(ctx, nil); err != nil {
+						g.Log().Error(ctx, err)
+					}
+				}()
+			}
+
+			runServer()
+			return nil
+		},
+	}
+)
+
+func init() {
+	if err := Main.AddCommand(&Dev); err != nil {
+		panic(err)
+	}
+}
+
+// devSqliteDriver dev模式使用的SQLite驱动, 仅由dev命令注册;
+// 模型写入时主键为0, MySQL按自增处理, SQLite会原样写入0导致后续插入冲突, 此处去掉值为0的主键交由SQLite自增
+type devSqliteDriver struct {
+	*sqlite.Driver
+}
+
+// New 创建数据库对象
+func (d *devSqliteDriver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	return &devSqliteDriver{Driver: &sqlite.Driver{Core: core}}, nil
+}
+
+// DoInsert 去掉值为0的主键后写入
+func (d *devSqliteDriver) DoInsert(ctx context.Context, link gdb.Link, table string, list gdb.List, option gdb.DoInsertOption) (sql.Result, error) {
+	for _, item := range list {
+		if id, ok := item["id"]; ok && gconv.Uint64(id) == 0 {
+			delete(item, "id")
+		}
+	}
+	return d.Driver.DoInsert(ctx, link, table, list, option)
+}
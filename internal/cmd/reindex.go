@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcmd"
+
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/service"
+)
+
+var (
+	Reindex = gcmd.Command{
+		Name:  "reindex",
+		Usage: "reindex -from 100 [-to 200] [-chain 1] [-contract 5] [-event Transfer] [-detach]",
+		Brief: "replay indexed events of a chain, contract or event over a block range without side effects such as bridge unlocks",
+		Arguments: []gcmd.Argument{
+			{Name: "chain", Brief: "chain id, optional when contract is set"},
+			{Name: "contract", Brief: "contract id, default all event sources on the chain"},
+			{Name: "event", Brief: "event name, requires contract"},
+			{Name: "from", Brief: "first block"},
+			{Name: "to", Brief: "last block, default the confirmed head"},
+			{Name: "detach", Brief: "only create the job and leave it to the worker", Orphan: true},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			//1.收到退出信号时停止执行, 任务交还给worker从已处理的区块之后继续
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			//2.创建任务
+			if parser.GetOpt("from") == nil {
+				return errors.New("from block is required")
+			}
+			job, err := service.Reindex().Create(ctx, &model.ReindexParams{
+				ChainId:    parser.GetOpt("chain").Uint64(),
+				ContractId: parser.GetOpt("contract").Uint64(),
+				Event:      parser.GetOpt("event").String(),
+				FromBlock:  parser.GetOpt("from").Uint64(),
+				ToBlock:    parser.GetOpt("to").Uint64(),
+			})
+			if err != nil {
+				return err
+			}
+			g.Log().Infof(ctx, "reindex job %d created for chain %d blocks %d-%d", job.Id, job.ChainId, job.FromBlock, job.ToBlock)
+			if parser.GetOpt("detach") != nil {
+				return nil
+			}
+
+			//3.在当前进程中执行
+			job, err = service.Reindex().Run(ctx, job.Id)
+			if err != nil {
+				return err
+			}
+			g.Log().Infof(ctx, "reindex job %d finished with status %d: %d logs, %d failures", job.Id, job.Status, job.Logs, job.Failures)
+			return nil
+		},
+	}
+)
+
+func init() {
+	if err := Main.AddCommand(&Reindex); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcmd"
+
+	"go-wallet-defi/internal/service"
+)
+
+var (
+	RotateKeys = gcmd.Command{
+		Name:  "rotate-keys",
+		Usage: "rotate-keys [-batch 100]",
+		Brief: "re-encrypt wallet secrets with the current primary key",
+		Arguments: []gcmd.Argument{
+			{Name: "batch", Short: "b", Brief: "rows per batch, default 100"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			result, err := service.Crypto.RotateWalletKeys(ctx, parser.GetOpt("batch", 100).Int())
+			if result != nil {
+				g.Log().Infof(ctx, "rotate-keys scanned=%d rotated=%d skipped=%d failed=%d",
+					result.Scanned, result.Rotated, result.Skipped, result.Failed)
+			}
+			return err
+		},
+	}
+)
+
+func init() {
+	if err := Main.AddCommand(&RotateKeys); err != nil {
+		panic(err)
+	}
+}
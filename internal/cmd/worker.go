@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/text/gstr"
+
+	"go-wallet-defi/internal/service"
+	"go-wallet-defi/internal/task"
+)
+
+var (
+	Worker = gcmd.Command{
+		Name:  "worker",
+		Usage: "worker [-jobs tracker,indexer,...]",
+		Brief: "run background jobs, each job runs on the replica holding its database lease",
+		Arguments: []gcmd.Argument{
+			{Name: "jobs", Short: "j", Brief: "comma separated job names, default all registered jobs"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			//1.收到退出信号时停止任务并释放租约, 其他副本无需等待租约过期即可接管
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := service.Chain().SyncRegistry(ctx); err != nil {
+				return err
+			}
+			if err := service.Bridge().CheckValidator(ctx); err != nil {
+				return err
+			}
+
+			//2.运行任务
+			var names []string
+			if jobs := parser.GetOpt("jobs").String(); jobs != "" {
+				names = gstr.SplitAndTrim(jobs, ",")
+			}
+			return task.RunWorker(ctx, names)
+		},
+	}
+)
+
+func init() {
+	if err := Main.AddCommand(&Worker); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/service"
+)
+
+type AdminController struct{}
+
+// GetRpcHealth 获取RPC节点健康状态
+func (c *AdminController) GetRpcHealth(ctx context.Context, req *v1.GetRpcHealthReq) (res *v1.GetRpcHealthRes, err error) {
+	return &v1.GetRpcHealthRes{
+		List: service.Admin().RpcHealth(ctx),
+	}, nil
+}
+
+// GetWorkerJobs 获取后台任务状态
+func (c *AdminController) GetWorkerJobs(ctx context.Context, req *v1.GetWorkerJobsReq) (res *v1.GetWorkerJobsRes, err error) {
+	list, err := service.Admin().WorkerJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetWorkerJobsRes{
+		List: list,
+	}, nil
+}
+
+// CreateReindex 创建重新索引任务
+func (c *AdminController) CreateReindex(ctx context.Context, req *v1.CreateReindexReq) (res *v1.CreateReindexRes, err error) {
+	job, err := service.Reindex().Create(ctx, &model.ReindexParams{
+		ChainId:    req.ChainId,
+		ContractId: req.ContractId,
+		Event:      req.Event,
+		FromBlock:  req.FromBlock,
+		ToBlock:    req.ToBlock,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateReindexRes{
+		Job: job,
+	}, nil
+}
+
+// GetReindex 获取重新索引任务详情
+func (c *AdminController) GetReindex(ctx context.Context, req *v1.GetReindexReq) (res *v1.GetReindexRes, err error) {
+	job, err := service.Reindex().Get(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetReindexRes{
+		Job: job,
+	}, nil
+}
+
+// GetReindexes 获取重新索引任务列表
+func (c *AdminController) GetReindexes(ctx context.Context, req *v1.GetReindexesReq) (res *v1.GetReindexesRes, err error) {
+	list, total, err := service.Reindex().GetList(ctx, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetReindexesRes{
+		List:  list,
+		Total: total,
+	}, nil
+}
+
+// CancelReindex 取消重新索引任务
+func (c *AdminController) CancelReindex(ctx context.Context, req *v1.CancelReindexReq) (res *v1.CancelReindexRes, err error) {
+	err = service.Reindex().Cancel(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CancelReindexRes{}, nil
+}
+
+// GetDeadLetters 获取事件死信列表
+func (c *AdminController) GetDeadLetters(ctx context.Context, req *v1.GetDeadLettersReq) (res *v1.GetDeadLettersRes, err error) {
+	list, total, err := service.DeadLetter().GetList(ctx, req.ChainId, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetDeadLettersRes{
+		List:  list,
+		Total: total,
+	}, nil
+}
+
+// GetDeadLetter 获取事件死信详情
+func (c *AdminController) GetDeadLetter(ctx context.Context, req *v1.GetDeadLetterReq) (res *v1.GetDeadLetterRes, err error) {
+	letter, err := service.DeadLetter().Get(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetDeadLetterRes{
+		Letter: letter,
+	}, nil
+}
+
+// RetryDeadLetter 立即重试事件死信
+func (c *AdminController) RetryDeadLetter(ctx context.Context, req *v1.RetryDeadLetterReq) (res *v1.RetryDeadLetterRes, err error) {
+	letter, err := service.DeadLetter().Retry(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.RetryDeadLetterRes{
+		Letter: letter,
+	}, nil
+}
+
+// DiscardDeadLetter 丢弃事件死信
+func (c *AdminController) DiscardDeadLetter(ctx context.Context, req *v1.DiscardDeadLetterReq) (res *v1.DiscardDeadLetterRes, err error) {
+	err = service.DeadLetter().Discard(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.DiscardDeadLetterRes{}, nil
+}
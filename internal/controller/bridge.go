@@ -17,6 +17,7 @@ func (c *BridgeController) CrossTransfer(ctx context.Context, req *v1.CrossTrans
 		req.ToAddress,
 		req.TokenAddress,
 		req.Amount,
+		req.Unit,
 	)
 	if err != nil {
 		return nil, err
@@ -44,19 +45,22 @@ func (c *BridgeController) GetCrossTransfer(ctx context.Context, req *v1.GetCros
 
 	list := make([]v1.CrossTransferInfo, 0, len(transfers))
 	for _, transfer := range transfers {
+		amount := service.Amount().Format(ctx, transfer.FromChainId, transfer.TokenAddress, transfer.Amount)
 		list = append(list, v1.CrossTransferInfo{
-			Id:           transfer.Id,
-			FromChainId:  transfer.FromChainId,
-			ToChainId:    transfer.ToChainId,
-			FromAddress:  transfer.FromAddress,
-			ToAddress:    transfer.ToAddress,
-			TokenAddress: transfer.TokenAddress,
-			Amount:       transfer.Amount,
-			Fee:          transfer.Fee,
-			FromHash:     transfer.FromHash,
-			ToHash:       transfer.ToHash,
-			Status:       transfer.Status,
-			CreatedAt:    transfer.CreatedAt,
+			Id:              transfer.Id,
+			FromChainId:     transfer.FromChainId,
+			ToChainId:       transfer.ToChainId,
+			FromAddress:     transfer.FromAddress,
+			ToAddress:       transfer.ToAddress,
+			TokenAddress:    transfer.TokenAddress,
+			Amount:          transfer.Amount,
+			FormattedAmount: amount.Formatted,
+			Symbol:          amount.Symbol,
+			Fee:             transfer.Fee,
+			FromHash:        transfer.FromHash,
+			ToHash:          transfer.ToHash,
+			Status:          transfer.Status,
+			CreatedAt:       transfer.CreatedAt,
 		})
 	}
 
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/pkg/simulate"
 	"go-wallet-defi/internal/service"
 )
 
@@ -10,7 +11,7 @@ type ContractController struct{}
 
 // Deploy 部署合约
 func (c *ContractController) Deploy(ctx context.Context, req *v1.DeployContractReq) (res *v1.DeployContractRes, err error) {
-	hash, address, err := service.Contract().Deploy(ctx, req.Name, req.ABI, req.Bytecode, req.From, req.Args, req.Network)
+	hash, address, err := service.Contract().Deploy(ctx, req.ChainId, req.Name, req.ABI, req.Bytecode, req.From, req.Args)
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +24,11 @@ func (c *ContractController) Deploy(ctx context.Context, req *v1.DeployContractR
 
 // Call 调用合约
 func (c *ContractController) Call(ctx context.Context, req *v1.CallContractReq) (res *v1.CallContractRes, err error) {
-	result, err := service.Contract().Call(ctx, req.Address, req.Method, req.Args, req.From, req.Value)
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
+	result, err := service.Contract().Call(ctx, req.ChainId, req.Address, req.Method, req.Args, req.From, req.Value)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.CallContractRes{Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +41,7 @@ func (c *ContractController) Call(ctx context.Context, req *v1.CallContractReq)
 
 // GetEvents 获取合约事件
 func (c *ContractController) GetEvents(ctx context.Context, req *v1.GetContractEventsReq) (res *v1.GetContractEventsRes, err error) {
-	events, total, err := service.Contract().GetEvents(ctx, req.Address, req.EventName, req.FromBlock, req.ToBlock, req.Page, req.PageSize)
+	events, total, err := service.Contract().GetEvents(ctx, req.ChainId, req.Address, req.EventName, req.FromBlock, req.ToBlock, req.Page, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
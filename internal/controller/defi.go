@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/pkg/simulate"
 	"go-wallet-defi/internal/service"
 )
 
@@ -10,15 +11,23 @@ type DefiController struct{}
 
 // SwapToken 代币兑换
 func (c *DefiController) SwapToken(ctx context.Context, req *v1.SwapTokenReq) (res *v1.SwapTokenRes, err error) {
+	input, err := service.Amount().Parse(ctx, req.ChainId, req.FromToken, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, amountOut, err := service.Defi().Swap(ctx,
 		req.ChainId,
 		req.FromToken,
 		req.ToToken,
-		req.Amount,
+		input.Raw,
 		req.FromAddress,
 		req.SlippageBps,
 		req.Type,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.SwapTokenRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -26,20 +35,33 @@ func (c *DefiController) SwapToken(ctx context.Context, req *v1.SwapTokenReq) (r
 	return &v1.SwapTokenRes{
 		Hash:      hash,
 		AmountOut: amountOut,
+		Input:     input,
 	}, nil
 }
 
 // AddLiquidity 添加流动性
 func (c *DefiController) AddLiquidity(ctx context.Context, req *v1.AddLiquidityReq) (res *v1.AddLiquidityRes, err error) {
+	inputA, err := service.Amount().Parse(ctx, req.ChainId, req.TokenA, req.AmountA, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	inputB, err := service.Amount().Parse(ctx, req.ChainId, req.TokenB, req.AmountB, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, liquidity, err := service.Defi().AddLiquidity(ctx,
 		req.ChainId,
 		req.TokenA,
 		req.TokenB,
-		req.AmountA,
-		req.AmountB,
+		inputA.Raw,
+		inputB.Raw,
 		req.FromAddress,
 		req.SlippageBps,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.AddLiquidityRes{InputA: inputA, InputB: inputB, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -47,18 +69,28 @@ func (c *DefiController) AddLiquidity(ctx context.Context, req *v1.AddLiquidityR
 	return &v1.AddLiquidityRes{
 		Hash:      hash,
 		Liquidity: liquidity,
+		InputA:    inputA,
+		InputB:    inputB,
 	}, nil
 }
 
 // RemoveLiquidity 移除流动性
 func (c *DefiController) RemoveLiquidity(ctx context.Context, req *v1.RemoveLiquidityReq) (res *v1.RemoveLiquidityRes, err error) {
+	input, err := service.Amount().Parse(ctx, req.ChainId, req.Pair, req.Liquidity, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, amount0, amount1, err := service.Defi().RemoveLiquidity(ctx,
 		req.ChainId,
 		req.Pair,
-		req.Liquidity,
+		input.Raw,
 		req.FromAddress,
 		req.SlippageBps,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.RemoveLiquidityRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -67,96 +99,149 @@ func (c *DefiController) RemoveLiquidity(ctx context.Context, req *v1.RemoveLiqu
 		Hash:    hash,
 		Amount0: amount0,
 		Amount1: amount1,
+		Input:   input,
 	}, nil
 }
 
 // Supply 存款
 func (c *DefiController) Supply(ctx context.Context, req *v1.SupplyReq) (res *v1.SupplyRes, err error) {
+	input, err := service.Amount().Parse(ctx, req.ChainId, req.Token, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, err := service.Defi().Supply(ctx,
 		req.ChainId,
 		req.Pool,
 		req.Token,
-		req.Amount,
+		input.Raw,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.SupplyRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &v1.SupplyRes{Hash: hash}, nil
+	return &v1.SupplyRes{Hash: hash, Input: input}, nil
 }
 
 // Borrow 借款
 func (c *DefiController) Borrow(ctx context.Context, req *v1.BorrowReq) (res *v1.BorrowRes, err error) {
+	input, err := service.Amount().Parse(ctx, req.ChainId, req.Token, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, err := service.Defi().Borrow(ctx,
 		req.ChainId,
 		req.Pool,
 		req.Token,
-		req.Amount,
+		input.Raw,
 		req.RateMode,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.BorrowRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &v1.BorrowRes{Hash: hash}, nil
+	return &v1.BorrowRes{Hash: hash, Input: input}, nil
 }
 
 // Repay 还款
 func (c *DefiController) Repay(ctx context.Context, req *v1.RepayReq) (res *v1.RepayRes, err error) {
+	input, err := service.Amount().Parse(ctx, req.ChainId, req.Token, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, err := service.Defi().Repay(ctx,
 		req.ChainId,
 		req.Pool,
 		req.Token,
-		req.Amount,
+		input.Raw,
 		req.RateMode,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.RepayRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &v1.RepayRes{Hash: hash}, nil
+	return &v1.RepayRes{Hash: hash, Input: input}, nil
 }
 
 // Stake 质押
 func (c *DefiController) Stake(ctx context.Context, req *v1.StakeReq) (res *v1.StakeRes, err error) {
+	stakeToken, err := service.Defi().StakeToken(ctx, req.ChainId, req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	input, err := service.Amount().Parse(ctx, req.ChainId, stakeToken, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, err := service.Defi().Stake(ctx,
 		req.ChainId,
 		req.Pool,
-		req.Amount,
+		input.Raw,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.StakeRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &v1.StakeRes{Hash: hash}, nil
+	return &v1.StakeRes{Hash: hash, Input: input}, nil
 }
 
 // Unstake 解质押
 func (c *DefiController) Unstake(ctx context.Context, req *v1.UnstakeReq) (res *v1.UnstakeRes, err error) {
+	stakeToken, err := service.Defi().StakeToken(ctx, req.ChainId, req.Pool)
+	if err != nil {
+		return nil, err
+	}
+	input, err := service.Amount().Parse(ctx, req.ChainId, stakeToken, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, err := service.Defi().Unstake(ctx,
 		req.ChainId,
 		req.Pool,
-		req.Amount,
+		input.Raw,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.UnstakeRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &v1.UnstakeRes{Hash: hash}, nil
+	return &v1.UnstakeRes{Hash: hash, Input: input}, nil
 }
 
 // ClaimReward 领取奖励
 func (c *DefiController) ClaimReward(ctx context.Context, req *v1.ClaimRewardReq) (res *v1.ClaimRewardRes, err error) {
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, reward, err := service.Defi().ClaimReward(ctx,
 		req.ChainId,
 		req.Pool,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.ClaimRewardRes{Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -169,12 +254,24 @@ func (c *DefiController) ClaimReward(ctx context.Context, req *v1.ClaimRewardReq
 
 // DepositVault 存入机枪池
 func (c *DefiController) DepositVault(ctx context.Context, req *v1.DepositVaultReq) (res *v1.DepositVaultRes, err error) {
+	vaultToken, err := service.Defi().VaultToken(ctx, req.ChainId, req.Vault)
+	if err != nil {
+		return nil, err
+	}
+	input, err := service.Amount().Parse(ctx, req.ChainId, vaultToken, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, shares, err := service.Defi().DepositVault(ctx,
 		req.ChainId,
 		req.Vault,
-		req.Amount,
+		input.Raw,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.DepositVaultRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -182,17 +279,26 @@ func (c *DefiController) DepositVault(ctx context.Context, req *v1.DepositVaultR
 	return &v1.DepositVaultRes{
 		Hash:   hash,
 		Shares: shares,
+		Input:  input,
 	}, nil
 }
 
 // WithdrawVault 提取机枪池
 func (c *DefiController) WithdrawVault(ctx context.Context, req *v1.WithdrawVaultReq) (res *v1.WithdrawVaultRes, err error) {
+	input, err := service.Amount().Parse(ctx, req.ChainId, req.Vault, req.Shares, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
 	hash, amount, err := service.Defi().WithdrawVault(ctx,
 		req.ChainId,
 		req.Vault,
-		req.Shares,
+		input.Raw,
 		req.FromAddress,
 	)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.WithdrawVaultRes{Input: input, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -200,5 +306,6 @@ func (c *DefiController) WithdrawVault(ctx context.Context, req *v1.WithdrawVaul
 	return &v1.WithdrawVaultRes{
 		Hash:   hash,
 		Amount: amount,
+		Input:  input,
 	}, nil
 }
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/service"
+)
+
+type PayoutController struct{}
+
+// Create 创建批量付款任务
+func (c *PayoutController) Create(ctx context.Context, req *v1.CreatePayoutReq) (res *v1.CreatePayoutRes, err error) {
+	job, rowErrors, err := service.Payout().Create(ctx, &model.PayoutParams{
+		ChainId: req.ChainId,
+		From:    req.From,
+		Mode:    req.Mode,
+		Csv:     req.Csv,
+		Rows:    req.Rows,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CreatePayoutRes{
+		Job:    job,
+		Errors: rowErrors,
+	}, nil
+}
+
+// GetPayout 获取批量付款任务详情
+func (c *PayoutController) GetPayout(ctx context.Context, req *v1.GetPayoutReq) (res *v1.GetPayoutRes, err error) {
+	job, items, err := service.Payout().Detail(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetPayoutRes{
+		Job:   job,
+		Items: items,
+	}, nil
+}
+
+// GetPayouts 获取批量付款任务列表
+func (c *PayoutController) GetPayouts(ctx context.Context, req *v1.GetPayoutsReq) (res *v1.GetPayoutsRes, err error) {
+	list, total, err := service.Payout().GetJobs(ctx, req.UserId, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetPayoutsRes{
+		List:  list,
+		Total: total,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/service"
+)
+
+type ScheduleController struct{}
+
+// Create 创建定时转账
+func (c *ScheduleController) Create(ctx context.Context, req *v1.CreateScheduleReq) (res *v1.CreateScheduleRes, err error) {
+	amount, err := service.Amount().Parse(ctx, req.ChainId, req.Token, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := service.Schedule.Create(ctx, &model.ScheduleParams{
+		ChainId:       req.ChainId,
+		From:          req.From,
+		To:            req.To,
+		Token:         req.Token,
+		Amount:        amount.Raw,
+		Cron:          req.Cron,
+		GasStrategy:   req.GasStrategy,
+		StartAt:       req.StartAt,
+		EndAt:         req.EndAt,
+		MaxRuns:       req.MaxRuns,
+		MaxRetries:    req.MaxRetries,
+		RetryInterval: req.RetryInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateScheduleRes{
+		Schedule: schedule,
+		Amount:   amount,
+	}, nil
+}
+
+// Pause 暂停定时转账
+func (c *ScheduleController) Pause(ctx context.Context, req *v1.PauseScheduleReq) (res *v1.PauseScheduleRes, err error) {
+	err = service.Schedule.Pause(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.PauseScheduleRes{}, nil
+}
+
+// Resume 恢复定时转账
+func (c *ScheduleController) Resume(ctx context.Context, req *v1.ResumeScheduleReq) (res *v1.ResumeScheduleRes, err error) {
+	err = service.Schedule.Resume(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ResumeScheduleRes{}, nil
+}
+
+// Cancel 取消定时转账
+func (c *ScheduleController) Cancel(ctx context.Context, req *v1.CancelScheduleReq) (res *v1.CancelScheduleRes, err error) {
+	err = service.Schedule.Cancel(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CancelScheduleRes{}, nil
+}
+
+// GetSchedules 获取定时转账列表
+func (c *ScheduleController) GetSchedules(ctx context.Context, req *v1.GetSchedulesReq) (res *v1.GetSchedulesRes, err error) {
+	list, total, err := service.Schedule.GetList(ctx, req.UserId, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetSchedulesRes{
+		List:  list,
+		Total: total,
+	}, nil
+}
+
+// GetRuns 获取定时转账执行记录
+func (c *ScheduleController) GetRuns(ctx context.Context, req *v1.GetScheduleRunsReq) (res *v1.GetScheduleRunsRes, err error) {
+	list, total, err := service.Schedule.GetRuns(ctx, req.Id, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.GetScheduleRunsRes{
+		List:  list,
+		Total: total,
+	}, nil
+}
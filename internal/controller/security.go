@@ -82,11 +82,13 @@ func (c *SecurityController) CreateTransactionLimit(ctx context.Context, req *v1
 	err = service.Security.CreateTransactionLimit(
 		ctx,
 		req.UserId,
+		req.ChainId,
 		req.TokenAddress,
 		req.SingleLimit,
 		req.DailyLimit,
 		req.WeeklyLimit,
 		req.MonthlyLimit,
+		req.Unit,
 	)
 	if err != nil {
 		return nil, err
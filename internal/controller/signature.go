@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/service"
+)
+
+type SignatureController struct{}
+
+// SignMessage EIP-191消息签名
+func (c *SignatureController) SignMessage(ctx context.Context, req *v1.SignMessageReq) (res *v1.SignMessageRes, err error) {
+	signature, err := service.Signature().SignMessage(ctx, req.Address, req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.SignMessageRes{
+		Signature: signature,
+	}, nil
+}
+
+// SignTypedData EIP-712结构化数据签名
+func (c *SignatureController) SignTypedData(ctx context.Context, req *v1.SignTypedDataReq) (res *v1.SignTypedDataRes, err error) {
+	signature, hash, err := service.Signature().SignTypedData(ctx, req.Address, req.TypedData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.SignTypedDataRes{
+		Signature: signature,
+		Hash:      hash,
+	}, nil
+}
+
+// Verify 验证签名
+func (c *SignatureController) Verify(ctx context.Context, req *v1.VerifySignatureReq) (res *v1.VerifySignatureRes, err error) {
+	result, err := service.Signature().Verify(ctx, req.ChainId, req.Address, req.Message, req.TypedData, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.VerifySignatureRes{
+		Valid:     result.Valid,
+		Method:    result.Method,
+		Recovered: result.Recovered,
+		Hash:      result.Hash,
+	}, nil
+}
@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/simulate"
 	"go-wallet-defi/internal/service"
 )
 
@@ -10,24 +12,97 @@ type TransactionController struct{}
 
 // TransferEth ETH转账
 func (c *TransactionController) TransferEth(ctx context.Context, req *v1.TransferEthReq) (res *v1.TransferEthRes, err error) {
-	hash, err := service.Transaction().TransferEth(ctx, req.From, req.To, req.Amount, req.GasPrice, req.GasLimit)
+	amount, err := service.Amount().Parse(ctx, req.ChainId, "", req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.Security.CheckTransfer(ctx, req.From, req.To, "", amount.Raw); err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
+	hash, err := service.Transaction().TransferEth(ctx, req.ChainId, req.From, req.To, amount.Raw, &model.TxFeeParams{
+		Strategy:             req.GasStrategy,
+		GasPrice:             req.GasPrice,
+		MaxFeePerGas:         req.MaxFeePerGas,
+		MaxPriorityFeePerGas: req.MaxPriorityFeePerGas,
+	}, req.GasLimit)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.TransferEthRes{Amount: amount, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &v1.TransferEthRes{
-		Hash: hash,
+		Hash:   hash,
+		Amount: amount,
 	}, nil
 }
 
 // TransferToken 代币转账
 func (c *TransactionController) TransferToken(ctx context.Context, req *v1.TransferTokenReq) (res *v1.TransferTokenRes, err error) {
-	hash, err := service.Transaction().TransferToken(ctx, req.From, req.To, req.Amount, req.Token, req.GasPrice, req.GasLimit)
+	amount, err := service.Amount().Parse(ctx, req.ChainId, req.Token, req.Amount, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.Security.CheckTransfer(ctx, req.From, req.To, req.Token, amount.Raw); err != nil {
+		return nil, err
+	}
+	ctx = simulate.WithDryRun(ctx, req.DryRun)
+	hash, err := service.Transaction().TransferToken(ctx, req.ChainId, req.From, req.To, amount.Raw, req.Token, &model.TxFeeParams{
+		Strategy:             req.GasStrategy,
+		GasPrice:             req.GasPrice,
+		MaxFeePerGas:         req.MaxFeePerGas,
+		MaxPriorityFeePerGas: req.MaxPriorityFeePerGas,
+	}, req.GasLimit)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		return &v1.TransferTokenRes{Amount: amount, Simulation: simulation}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &v1.TransferTokenRes{
+		Hash:   hash,
+		Amount: amount,
+	}, nil
+}
+
+// BuildOfflineTx 生成离线签名交易
+func (c *TransactionController) BuildOfflineTx(ctx context.Context, req *v1.BuildOfflineTxReq) (res *v1.BuildOfflineTxRes, err error) {
+	envelope, err := service.Offline().Build(ctx, &model.OfflineTxParams{
+		ChainId:  req.ChainId,
+		From:     req.From,
+		To:       req.To,
+		Token:    req.Token,
+		Amount:   req.Amount,
+		Unit:     req.Unit,
+		Data:     req.Data,
+		GasLimit: req.GasLimit,
+		Fee: &model.TxFeeParams{
+			Strategy:             req.GasStrategy,
+			GasPrice:             req.GasPrice,
+			MaxFeePerGas:         req.MaxFeePerGas,
+			MaxPriorityFeePerGas: req.MaxPriorityFeePerGas,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.BuildOfflineTxRes{
+		Envelope: envelope,
+	}, nil
+}
+
+// SubmitOfflineTx 提交离线签名交易
+func (c *TransactionController) SubmitOfflineTx(ctx context.Context, req *v1.SubmitOfflineTxReq) (res *v1.SubmitOfflineTxRes, err error) {
+	hash, err := service.Offline().Submit(ctx, req.Id, req.RawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.SubmitOfflineTxRes{
 		Hash: hash,
 	}, nil
 }
@@ -41,16 +116,25 @@ func (c *TransactionController) GetTransactions(ctx context.Context, req *v1.Get
 
 	list := make([]v1.TransactionInfo, 0, len(transactions))
 	for _, tx := range transactions {
+		amount := service.Amount().Format(ctx, tx.ChainId, tx.TokenAddress, tx.Amount)
 		list = append(list, v1.TransactionInfo{
-			Hash:        tx.Hash,
-			From:        tx.FromAddress,
-			To:          tx.ToAddress,
-			Amount:      tx.Amount,
-			Token:       tx.TokenAddress,
-			Status:      tx.Status,
-			BlockNumber: tx.BlockNumber,
-			BlockTime:   tx.BlockTime,
-			CreatedAt:   tx.CreatedAt,
+			Hash:                 tx.Hash,
+			From:                 tx.FromAddress,
+			To:                   tx.ToAddress,
+			Amount:               tx.Amount,
+			FormattedAmount:      amount.Formatted,
+			Symbol:               amount.Symbol,
+			Token:                tx.TokenAddress,
+			Direction:            tx.Direction,
+			ChainId:              tx.ChainId,
+			TxType:               tx.TxType,
+			GasPrice:             tx.GasPrice,
+			MaxFeePerGas:         tx.MaxFeePerGas,
+			MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+			Status:               tx.Status,
+			BlockNumber:          tx.BlockNumber,
+			BlockTime:            tx.BlockTime,
+			CreatedAt:            tx.CreatedAt,
 		})
 	}
 
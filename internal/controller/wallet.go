@@ -2,7 +2,9 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	v1 "go-wallet-defi/api/v1"
+	"go-wallet-defi/internal/model"
 	"go-wallet-defi/internal/service"
 )
 
@@ -15,51 +17,160 @@ func (c *WalletController) Create(ctx context.Context, req *v1.CreateWalletReq)
 	}
 
 	return &v1.CreateWalletRes{
-		Address:  wallet.Address,
-		Mnemonic: wallet.Mnemonic,
+		Address:        wallet.Address,
+		Mnemonic:       wallet.Mnemonic,
+		DerivationPath: wallet.DerivationPath,
 	}, nil
 }
 
 func (c *WalletController) Import(ctx context.Context, req *v1.ImportWalletReq) (res *v1.ImportWalletRes, err error) {
-	wallet, err := service.Wallet().Import(ctx, req.Chain, req.Mnemonic)
+	wallet, err := service.Wallet().Import(ctx, req.Chain, req.Mnemonic, req.Index)
 	if err != nil {
 		return nil, err
 	}
 
 	return &v1.ImportWalletRes{
+		Address:        wallet.Address,
+		DerivationPath: wallet.DerivationPath,
+	}, nil
+}
+
+func (c *WalletController) Derive(ctx context.Context, req *v1.DeriveWalletReq) (res *v1.DeriveWalletRes, err error) {
+	wallet, err := service.Wallet().Derive(ctx, req.Address, req.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.DeriveWalletRes{
+		Address:        wallet.Address,
+		DerivationPath: wallet.DerivationPath,
+	}, nil
+}
+
+func (c *WalletController) ImportPrivateKey(ctx context.Context, req *v1.ImportPrivateKeyReq) (res *v1.ImportPrivateKeyRes, err error) {
+	wallet, err := service.Wallet().ImportPrivateKey(ctx, req.Chain, req.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ImportPrivateKeyRes{
 		Address: wallet.Address,
 	}, nil
 }
 
+func (c *WalletController) ImportKeystore(ctx context.Context, req *v1.ImportKeystoreReq) (res *v1.ImportKeystoreRes, err error) {
+	wallet, err := service.Wallet().ImportKeystore(ctx, req.Chain, req.Keystore, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ImportKeystoreRes{
+		Address: wallet.Address,
+	}, nil
+}
+
+func (c *WalletController) ExportKeystore(ctx context.Context, req *v1.ExportKeystoreReq) (res *v1.ExportKeystoreRes, err error) {
+	keystore, err := service.Wallet().ExportKeystore(ctx, req.Address, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ExportKeystoreRes{
+		Keystore: keystore,
+	}, nil
+}
+
 func (c *WalletController) List(ctx context.Context, req *v1.ListWalletReq) (res *v1.ListWalletRes, err error) {
-	wallets, err := service.Wallet().GetList(ctx, req.Page, req.PageSize)
+	filter := &model.WalletFilter{
+		UserId:     req.UserId,
+		Chain:      req.Chain,
+		WalletType: req.WalletType,
+		GroupId:    req.GroupId,
+		Tag:        req.Tag,
+		Label:      req.Label,
+	}
+	wallets, total, err := service.Wallet().GetList(ctx, filter, req.Page, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
 
 	list := make([]v1.WalletInfo, 0, len(wallets))
 	for _, w := range wallets {
+		var tags []string
+		if w.Tags != "" {
+			_ = json.Unmarshal([]byte(w.Tags), &tags)
+		}
 		list = append(list, v1.WalletInfo{
-			Id:      w.Id,
-			Address: w.Address,
-			Chain:   w.Chain,
-			//Balance:   w.Balance,
-			CreatedAt: w.CreatedAt,
+			Id:         w.Id,
+			Address:    w.Address,
+			Chain:      w.Chain,
+			Balance:    w.Balance,
+			WalletType: w.WalletType,
+			Label:      w.Label,
+			Tags:       tags,
+			GroupId:    w.GroupId,
+			CreatedAt:  w.CreatedAt,
 		})
 	}
 
 	return &v1.ListWalletRes{
-		List: list,
+		List:  list,
+		Total: total,
+	}, nil
+}
+
+func (c *WalletController) AddWatch(ctx context.Context, req *v1.AddWatchWalletReq) (res *v1.AddWatchWalletRes, err error) {
+	wallet, err := service.Wallet().AddWatchOnly(ctx, req.Chain, req.Address, req.Label, req.Tags, req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.AddWatchWalletRes{
+		Address: wallet.Address,
+	}, nil
+}
+
+func (c *WalletController) UpdateMeta(ctx context.Context, req *v1.UpdateWalletMetaReq) (res *v1.UpdateWalletMetaRes, err error) {
+	err = service.Wallet().UpdateMeta(ctx, req.Address, req.Label, req.Tags, req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.UpdateWalletMetaRes{}, nil
+}
+
+func (c *WalletController) CreateGroup(ctx context.Context, req *v1.CreateWalletGroupReq) (res *v1.CreateWalletGroupRes, err error) {
+	group, err := service.Wallet().CreateGroup(ctx, req.UserId, req.Name, req.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateWalletGroupRes{
+		Id: group.Id,
+	}, nil
+}
+
+func (c *WalletController) ListGroup(ctx context.Context, req *v1.ListWalletGroupReq) (res *v1.ListWalletGroupRes, err error) {
+	groups, err := service.Wallet().GetGroups(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ListWalletGroupRes{
+		List: groups,
 	}, nil
 }
 
 func (c *WalletController) GetBalance(ctx context.Context, req *v1.GetBalanceReq) (res *v1.GetBalanceRes, err error) {
-	balance, err := service.Wallet().GetBalance(ctx, req.Address)
+	balance, err := service.Wallet().GetBalance(ctx, req.Address, req.Fiat)
 	if err != nil {
 		return nil, err
 	}
 
 	return &v1.GetBalanceRes{
-		Balance: balance,
+		Address:      balance.Address,
+		FiatCurrency: balance.FiatCurrency,
+		FiatValue:    balance.FiatValue,
+		Chains:       balance.Chains,
 	}, nil
 }
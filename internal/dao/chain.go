@@ -2,6 +2,8 @@ package dao
 
 import (
 	"context"
+	"time"
+
 	"github.com/gogf/gf/v2/frame/g"
 	"go-wallet-defi/internal/model"
 )
@@ -20,10 +22,48 @@ func (d *ChainDao) GetById(ctx context.Context, id uint64) (*model.Chain, error)
 // GetByChainId 根据ID获取链信息
 func (d *ChainDao) GetByChainId(ctx context.Context, chainId uint64) (*model.Chain, error) {
 	var chain *model.Chain
-	err := g.DB().Model("chain").Where("chainId", chainId).Scan(&chain)
+	err := g.DB().Model("chain").Ctx(ctx).Where("chain_id", chainId).Scan(&chain)
 	return chain, err
 }
 
+// Save 按链ID写入链信息, 已存在时更新配置字段
+func (d *ChainDao) Save(ctx context.Context, chain *model.Chain) error {
+	existing, err := d.GetByChainId(ctx, chain.ChainId)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		id, err := g.DB().Model("chain").Ctx(ctx).Data(chain).InsertAndGetId()
+		if err != nil {
+			return err
+		}
+		chain.Id = uint64(id)
+		return nil
+	}
+	chain.Id = existing.Id
+	_, err = g.DB().Model("chain").Ctx(ctx).Where("id", existing.Id).Data(g.Map{
+		"name":           chain.Name,
+		"symbol":         chain.Symbol,
+		"decimals":       chain.Decimals,
+		"explorer_url":   chain.ExplorerUrl,
+		"rpc_urls":       chain.RpcUrls,
+		"bridge_address": chain.BridgeAddress,
+		"bridge_height":  chain.BridgeHeight,
+		"legacy_tx":      chain.LegacyTx,
+		"confirmations":  chain.Confirmations,
+		"status":         chain.Status,
+		"updated_at":     chain.UpdatedAt,
+	}).Update()
+	return err
+}
+
+// GetActiveList 获取所有启用的链
+func (d *ChainDao) GetActiveList(ctx context.Context) ([]*model.Chain, error) {
+	var chains []*model.Chain
+	err := g.DB().Model("chain").Ctx(ctx).Where("status", 1).Order("id ASC").Scan(&chains)
+	return chains, err
+}
+
 // GetMapping 获取合约地址映射
 func (d *ChainDao) GetMapping(ctx context.Context, fromChainId uint64, fromAddress string, toChainId uint64) (*model.ContractMapping, error) {
 	var mapping *model.ContractMapping
@@ -35,10 +75,14 @@ func (d *ChainDao) GetMapping(ctx context.Context, fromChainId uint64, fromAddre
 	return mapping, err
 }
 
-// InsertCrossTransfer 插入跨链交易
+// InsertCrossTransfer 插入跨链交易, 并回填记录ID
 func (d *ChainDao) InsertCrossTransfer(ctx context.Context, transfer *model.CrossTransfer) error {
-	_, err := g.DB().Model("cross_transfer").Data(transfer).Insert()
-	return err
+	id, err := g.DB().Model("cross_transfer").Ctx(ctx).Data(transfer).InsertAndGetId()
+	if err != nil {
+		return err
+	}
+	transfer.Id = uint64(id)
+	return nil
 }
 
 // UpdateCrossTransfer 更新跨链交易
@@ -47,6 +91,35 @@ func (d *ChainDao) UpdateCrossTransfer(ctx context.Context, id uint64, data g.Ma
 	return err
 }
 
+// ClaimUnlock 广播前保存解锁交易, 仅在尚未保存解锁交易时生效, 防止同一锁定事件发出多笔解锁交易
+func (d *ChainDao) ClaimUnlock(ctx context.Context, id uint64, toHash, unlockTx string) (bool, error) {
+	result, err := g.DB().Model("cross_transfer").Ctx(ctx).
+		Where("id", id).
+		Where("to_hash", "").
+		Data(g.Map{
+			"to_hash":    toHash,
+			"unlock_tx":  unlockTx,
+			"updated_at": time.Now().Unix(),
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetCrossTransferByNonce 根据来源链、目标链与跨链序号获取跨链交易, 不存在时返回nil
+func (d *ChainDao) GetCrossTransferByNonce(ctx context.Context, fromChainId, toChainId, nonce uint64) (*model.CrossTransfer, error) {
+	var transfer *model.CrossTransfer
+	err := g.DB().Model("cross_transfer").Ctx(ctx).
+		Where("from_chain_id", fromChainId).
+		Where("to_chain_id", toChainId).
+		Where("nonce", nonce).
+		Scan(&transfer)
+	return transfer, err
+}
+
 // GetCrossTransferList 获取跨链交易列表
 func (d *ChainDao) GetCrossTransferList(ctx context.Context, fromChainId, toChainId uint64, address string, status int, page, pageSize int) ([]*model.CrossTransfer, int, error) {
 	m := g.DB().Model("cross_transfer")
@@ -33,21 +33,37 @@ func (d *ContractDao) GetById(ctx context.Context, id uint64) (*model.Contract,
 	return contract, err
 }
 
-// GetByAddress 根据地址获取合约
-func (d *ContractDao) GetByAddress(ctx context.Context, address, network string) (*model.Contract, error) {
+// GetByAddress 根据链与地址获取合约
+func (d *ContractDao) GetByAddress(ctx context.Context, chainId uint64, address string) (*model.Contract, error) {
 	var contract *model.Contract
 	err := g.DB().Model("contract").
 		Ctx(ctx).
+		Where("chain_id", chainId).
 		Where("address", address).
-		Where("network", network).
 		Where("status", 1).
 		Scan(&contract)
 	return contract, err
 }
 
-// InsertEvent 插入合约事件
+// GetActiveList 获取所有启用的合约
+func (d *ContractDao) GetActiveList(ctx context.Context) ([]*model.Contract, error) {
+	var contracts []*model.Contract
+	err := g.DB().Model("contract").Ctx(ctx).Where("status", 1).Order("id ASC").Scan(&contracts)
+	return contracts, err
+}
+
+// InsertEvent 插入合约事件, 同一链上的日志(tx_hash, log_index)已存在时忽略
 func (d *ContractDao) InsertEvent(ctx context.Context, event *model.ContractEvent) error {
-	_, err := g.DB().Model("contract_event").Ctx(ctx).Data(event).Insert()
+	_, err := g.DB().Model("contract_event").Ctx(ctx).Data(event).InsertIgnore()
+	return err
+}
+
+// DeleteEventsAfter 删除合约在指定高度之后的事件
+func (d *ContractDao) DeleteEventsAfter(ctx context.Context, contractId uint64, blockNumber int64) error {
+	_, err := g.DB().Model("contract_event").Ctx(ctx).
+		Where("contract_id", contractId).
+		Where("block_number > ?", blockNumber).
+		Delete()
 	return err
 }
 
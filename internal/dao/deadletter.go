@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type DeadLetterDao struct{}
+
+var DeadLetter = &DeadLetterDao{}
+
+// Insert 添加失败的事件日志
+func (d *DeadLetterDao) Insert(ctx context.Context, letter *model.EventDeadLetter) error {
+	id, err := g.DB().Model("event_dead_letter").Ctx(ctx).Data(letter).InsertAndGetId()
+	if err != nil {
+		return err
+	}
+	letter.Id = uint64(id)
+	return nil
+}
+
+// Get 根据ID获取失败的事件日志, 不存在时返回nil
+func (d *DeadLetterDao) Get(ctx context.Context, id uint64) (*model.EventDeadLetter, error) {
+	var letter *model.EventDeadLetter
+	err := g.DB().Model("event_dead_letter").Ctx(ctx).Where("id", id).Scan(&letter)
+	return letter, err
+}
+
+// GetByLog 获取来源中同一日志的记录, 不存在时返回nil
+func (d *DeadLetterDao) GetByLog(ctx context.Context, chainId uint64, source, txHash string, logIndex uint) (*model.EventDeadLetter, error) {
+	var letter *model.EventDeadLetter
+	err := g.DB().Model("event_dead_letter").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("source", source).
+		Where("tx_hash", txHash).
+		Where("log_index", logIndex).
+		Scan(&letter)
+	return letter, err
+}
+
+// GetList 获取失败的事件日志列表, status小于0表示全部状态, chainId为0表示全部链
+func (d *DeadLetterDao) GetList(ctx context.Context, chainId uint64, status int, page, pageSize int) (list []*model.EventDeadLetter, total int, err error) {
+	m := g.DB().Model("event_dead_letter")
+
+	if chainId > 0 {
+		m = m.Where("chain_id", chainId)
+	}
+	if status >= 0 {
+		m = m.Where("status", status)
+	}
+
+	// 获取总数
+	total, err = m.Ctx(ctx).Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list = make([]*model.EventDeadLetter, 0)
+	err = m.Ctx(ctx).
+		Page(page, pageSize).
+		Order("id DESC").
+		Scan(&list)
+
+	return list, total, err
+}
+
+// GetDue 获取到期待重试的记录, 以及重试中但在staleBefore之后未更新(执行实例中断)的记录
+func (d *DeadLetterDao) GetDue(ctx context.Context, now, staleBefore int64, limit int) ([]*model.EventDeadLetter, error) {
+	var list []*model.EventDeadLetter
+	err := g.DB().Model("event_dead_letter").Ctx(ctx).
+		Where("((status = ? AND next_retry_at <= ?) OR (status = ? AND updated_at < ?))",
+			model.EventDeadLetterStatusPending, now, model.EventDeadLetterStatusRunning, staleBefore).
+		Order("next_retry_at ASC").
+		Limit(limit).
+		Scan(&list)
+	return list, err
+}
+
+// Claim 乐观更新为重试中并累计执行次数, 仅记录待重试、达到最大重试次数或执行实例已中断,
+// 且执行次数仍为attempts时生效, 防止同一记录被多个实例同时重试
+func (d *DeadLetterDao) Claim(ctx context.Context, id uint64, attempts int, staleBefore int64) (bool, error) {
+	result, err := g.DB().Model("event_dead_letter").Ctx(ctx).
+		Where("id", id).
+		Where("attempts", attempts).
+		Where("(status IN (?) OR (status = ? AND updated_at < ?))",
+			g.Slice{model.EventDeadLetterStatusPending, model.EventDeadLetterStatusExhausted},
+			model.EventDeadLetterStatusRunning, staleBefore).
+		Data(g.Map{
+			"status":     model.EventDeadLetterStatusRunning,
+			"attempts":   &gdb.Counter{Field: "attempts", Value: 1},
+			"updated_at": time.Now().Unix(),
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UpdateClaimed 保存重试结果, 仅记录仍处于本次认领(重试中且执行次数为attempts)时生效
+func (d *DeadLetterDao) UpdateClaimed(ctx context.Context, id uint64, attempts int, data g.Map) (bool, error) {
+	data["updated_at"] = time.Now().Unix()
+	result, err := g.DB().Model("event_dead_letter").Ctx(ctx).
+		Where("id", id).
+		Where("attempts", attempts).
+		Where("status", model.EventDeadLetterStatusRunning).
+		Data(data).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UpdateIdle 更新未在重试中且执行次数仍为attempts的记录
+func (d *DeadLetterDao) UpdateIdle(ctx context.Context, id uint64, attempts int, data g.Map) (bool, error) {
+	data["updated_at"] = time.Now().Unix()
+	result, err := g.DB().Model("event_dead_letter").Ctx(ctx).
+		Where("id", id).
+		Where("attempts", attempts).
+		WhereNot("status", model.EventDeadLetterStatusRunning).
+		Data(data).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Discard 丢弃待重试或已达到最大重试次数的记录
+func (d *DeadLetterDao) Discard(ctx context.Context, id uint64) (bool, error) {
+	result, err := g.DB().Model("event_dead_letter").Ctx(ctx).
+		Where("id", id).
+		WhereIn("status", g.Slice{model.EventDeadLetterStatusPending, model.EventDeadLetterStatusExhausted}).
+		Data(g.Map{
+			"status":     model.EventDeadLetterStatusDiscarded,
+			"updated_at": time.Now().Unix(),
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// DeleteAfter 删除来源在指定高度之后的记录, 用于重组回滚
+func (d *DeadLetterDao) DeleteAfter(ctx context.Context, chainId uint64, source string, blockNumber uint64) error {
+	_, err := g.DB().Model("event_dead_letter").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("source", source).
+		Where("block_number > ?", blockNumber).
+		Delete()
+	return err
+}
@@ -92,7 +92,7 @@ func (d *DecentralizedDao) GetP2PMessages(ctx context.Context, peer string) ([]*
 	var messages []*model.P2PMessage
 	err := g.DB().Model("p2p_message").
 		Where("to_peer", peer).
-		Or("from_peer", peer).
+		WhereOr("from_peer", peer).
 		Order("created_at DESC").
 		Scan(&messages)
 	return messages, err
@@ -0,0 +1,148 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type IndexerDao struct{}
+
+var Indexer = &IndexerDao{}
+
+// GetCursor 获取索引游标, 不存在时返回nil
+func (d *IndexerDao) GetCursor(ctx context.Context, chainId uint64, source string) (*model.IndexerCursor, error) {
+	var cursor *model.IndexerCursor
+	err := g.DB().Model("indexer_cursor").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("source", source).
+		Scan(&cursor)
+	return cursor, err
+}
+
+// SaveCursor 保存索引游标, 按(chain_id, source)唯一键插入或更新
+func (d *IndexerDao) SaveCursor(ctx context.Context, chainId uint64, source string, blockNumber uint64) error {
+	_, err := g.DB().Model("indexer_cursor").Ctx(ctx).Data(g.Map{
+		"chain_id":     chainId,
+		"source":       source,
+		"block_number": blockNumber,
+		"updated_at":   time.Now().Unix(),
+	}).OnConflict("chain_id", "source").Save()
+	return err
+}
+
+// GetCursors 批量获取同一条链上的索引游标
+func (d *IndexerDao) GetCursors(ctx context.Context, chainId uint64, sources []string) ([]*model.IndexerCursor, error) {
+	var cursors []*model.IndexerCursor
+	if len(sources) == 0 {
+		return cursors, nil
+	}
+	err := g.DB().Model("indexer_cursor").Ctx(ctx).
+		Where("chain_id", chainId).
+		WhereIn("source", sources).
+		Scan(&cursors)
+	return cursors, err
+}
+
+// SaveCursors 批量将同一条链上的索引游标推进到同一高度
+func (d *IndexerDao) SaveCursors(ctx context.Context, chainId uint64, sources []string, blockNumber uint64) error {
+	if len(sources) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	data := make(g.List, 0, len(sources))
+	for _, source := range sources {
+		data = append(data, g.Map{
+			"chain_id":     chainId,
+			"source":       source,
+			"block_number": blockNumber,
+			"updated_at":   now,
+		})
+	}
+	_, err := g.DB().Model("indexer_cursor").Ctx(ctx).Data(data).OnConflict("chain_id", "source").Save()
+	return err
+}
+
+// SaveBlock 记录事件索引分组已处理区块的哈希
+func (d *IndexerDao) SaveBlock(ctx context.Context, chainId uint64, group string, blockNumber uint64, blockHash string) error {
+	_, err := g.DB().Model("indexer_block").Ctx(ctx).Data(g.Map{
+		"chain_id":     chainId,
+		"event_group":  group,
+		"block_number": blockNumber,
+		"block_hash":   blockHash,
+		"created_at":   time.Now().Unix(),
+	}).OnConflict("chain_id", "event_group", "block_number").Save()
+	return err
+}
+
+// GetRecentBlocks 获取事件索引分组最近处理的区块, 按高度倒序
+func (d *IndexerDao) GetRecentBlocks(ctx context.Context, chainId uint64, group string, limit int) ([]*model.IndexerBlock, error) {
+	var blocks []*model.IndexerBlock
+	err := g.DB().Model("indexer_block").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("event_group", group).
+		Order("block_number DESC").
+		Limit(limit).
+		Scan(&blocks)
+	return blocks, err
+}
+
+// DeleteBlocksAfter 删除高于指定高度的区块记录
+func (d *IndexerDao) DeleteBlocksAfter(ctx context.Context, chainId uint64, group string, blockNumber uint64) error {
+	_, err := g.DB().Model("indexer_block").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("event_group", group).
+		Where("block_number > ?", blockNumber).
+		Delete()
+	return err
+}
+
+// InsertLogs 记录已处理的事件日志, 重复日志忽略
+func (d *IndexerDao) InsertLogs(ctx context.Context, logs []*model.IndexerLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	_, err := g.DB().Model("indexer_log").Ctx(ctx).Data(logs).InsertIgnore()
+	return err
+}
+
+// GetLogsAfter 获取来源在指定高度之后处理的日志, 按处理顺序倒序
+func (d *IndexerDao) GetLogsAfter(ctx context.Context, chainId uint64, source string, blockNumber uint64) ([]*model.IndexerLog, error) {
+	var logs []*model.IndexerLog
+	err := g.DB().Model("indexer_log").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("source", source).
+		Where("block_number > ?", blockNumber).
+		Order("block_number DESC, log_index DESC").
+		Scan(&logs)
+	return logs, err
+}
+
+// DeleteLogsAfter 删除来源在指定高度之后处理的日志
+func (d *IndexerDao) DeleteLogsAfter(ctx context.Context, chainId uint64, source string, blockNumber uint64) error {
+	_, err := g.DB().Model("indexer_log").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("source", source).
+		Where("block_number > ?", blockNumber).
+		Delete()
+	return err
+}
+
+// Prune 清理重组窗口之外的区块与日志记录
+func (d *IndexerDao) Prune(ctx context.Context, chainId uint64, group string, before uint64) error {
+	_, err := g.DB().Model("indexer_block").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("event_group", group).
+		Where("block_number < ?", before).
+		Delete()
+	if err != nil {
+		return err
+	}
+	_, err = g.DB().Model("indexer_log").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("block_number < ?", before).
+		Delete()
+	return err
+}
@@ -36,6 +36,16 @@ func (d *NFTDao) GetById(ctx context.Context, id uint64) (*model.NFT, error) {
 	return nft, err
 }
 
+// GetByTokenId 根据合约与代币ID获取NFT, 不存在时返回nil
+func (d *NFTDao) GetByTokenId(ctx context.Context, contractId uint64, tokenId string) (*model.NFT, error) {
+	var nft *model.NFT
+	err := g.DB().Model("nft").Ctx(ctx).
+		Where("contract_id", contractId).
+		Where("token_id", tokenId).
+		Scan(&nft)
+	return nft, err
+}
+
 // GetList 获取NFT列表
 func (d *NFTDao) GetList(ctx context.Context, owner, creator string, contractId uint64, page, pageSize int) ([]*model.NFT, int, error) {
 	m := g.DB().Model("nft").Where("status", 1)
@@ -61,12 +71,45 @@ func (d *NFTDao) GetList(ctx context.Context, owner, creator string, contractId
 	return list, total, err
 }
 
-// InsertTransfer 插入转移记录
+// InsertTransfer 插入转移记录, 同一链上的日志(hash, log_index)已存在时忽略
 func (d *NFTDao) InsertTransfer(ctx context.Context, transfer *model.NFTTransfer) error {
-	_, err := g.DB().Model("nft_transfer").Ctx(ctx).Data(transfer).Insert()
+	_, err := g.DB().Model("nft_transfer").Ctx(ctx).Data(transfer).InsertIgnore()
 	return err
 }
 
+// DeleteTransfersAfter 删除合约下NFT在指定高度之后的链上转移记录, 返回受影响的NFT ID
+func (d *NFTDao) DeleteTransfersAfter(ctx context.Context, contractId uint64, blockNumber uint64) ([]uint64, error) {
+	values, err := g.DB().Model("nft_transfer t").Ctx(ctx).
+		InnerJoin("nft n", "n.id = t.nft_id").
+		Where("n.contract_id", contractId).
+		Where("t.block_number > ?", blockNumber).
+		Distinct().
+		Array("t.nft_id")
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	nftIds := make([]uint64, 0, len(values))
+	for _, value := range values {
+		nftIds = append(nftIds, value.Uint64())
+	}
+	_, err = g.DB().Model("nft_transfer").Ctx(ctx).
+		WhereIn("nft_id", nftIds).
+		Where("block_number > ?", blockNumber).
+		Delete()
+	return nftIds, err
+}
+
+// GetLastTransfer 获取NFT最近一次已上链的转移记录, 不存在时返回nil
+func (d *NFTDao) GetLastTransfer(ctx context.Context, nftId uint64) (*model.NFTTransfer, error) {
+	var transfer *model.NFTTransfer
+	err := g.DB().Model("nft_transfer").Ctx(ctx).
+		Where("nft_id", nftId).
+		Where("block_number > ?", 0).
+		Order("block_number DESC, log_index DESC, id DESC").
+		Scan(&transfer)
+	return transfer, err
+}
+
 // InsertMarket 插入市场记录
 func (d *NFTDao) InsertMarket(ctx context.Context, market *model.NFTMarket) error {
 	_, err := g.DB().Model("nft_market").Ctx(ctx).Data(market).Insert()
@@ -0,0 +1,130 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type NonceDao struct{}
+
+var Nonce = &NonceDao{}
+
+// Reserve 锁定nonce账户并分配nonce
+// pending为链上pending nonce; [pending, nextNonce)区间内没有有效预留的nonce视为空缺优先复用,
+// 区间内不存在任何有效预留时说明本地与链上发生漂移, 重新以链上nonce为准;
+// 在staleBefore之前更新且仍未上链的预留视为失效(进程中断或交易被丢弃)
+func (d *NonceDao) Reserve(ctx context.Context, chainId uint64, address string, pending uint64, staleBefore int64) (*model.NonceReservation, error) {
+	var reservation *model.NonceReservation
+	err := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		now := time.Now().Unix()
+
+		//1.初始化并锁定账户
+		_, err := tx.Model("nonce_account").Ctx(ctx).Data(g.Map{
+			"chain_id":   chainId,
+			"address":    address,
+			"next_nonce": pending,
+			"updated_at": now,
+		}).InsertIgnore()
+		if err != nil {
+			return err
+		}
+		var account *model.NonceAccount
+		err = tx.Model("nonce_account").Ctx(ctx).
+			Where("chain_id", chainId).
+			Where("address", address).
+			LockUpdate().
+			Scan(&account)
+		if err != nil {
+			return err
+		}
+
+		//2.链上nonce超前(外部发送交易)时同步
+		next := account.NextNonce
+		if pending > next {
+			next = pending
+		}
+
+		//3.查询区间内有效的预留
+		values, err := tx.Model("nonce_reservation").Ctx(ctx).
+			Fields("nonce").
+			Where("chain_id", chainId).
+			Where("address", address).
+			WhereGTE("nonce", pending).
+			WhereLT("nonce", next).
+			WhereIn("status", g.Slice{model.NonceStatusReserved, model.NonceStatusSent}).
+			WhereGTE("updated_at", staleBefore).
+			Array()
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 && next > pending {
+			g.Log().Warningf(ctx, "nonce drift for %s on chain %d: local %d, chain %d, resync", address, chainId, next, pending)
+			next = pending
+		}
+		used := make(map[uint64]bool, len(values))
+		for _, v := range values {
+			used[v.Uint64()] = true
+		}
+
+		//4.优先填补空缺, 否则分配下一个nonce
+		nonce := next
+		for n := pending; n < next; n++ {
+			if !used[n] {
+				nonce = n
+				break
+			}
+		}
+		if nonce == next {
+			next++
+		}
+		_, err = tx.Model("nonce_account").Ctx(ctx).Where("id", account.Id).Data(g.Map{
+			"next_nonce": next,
+			"updated_at": now,
+		}).Update()
+		if err != nil {
+			return err
+		}
+
+		//5.记录预留
+		reservation = &model.NonceReservation{
+			ChainId:   chainId,
+			Address:   address,
+			Nonce:     nonce,
+			Status:    model.NonceStatusReserved,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		id, err := tx.Model("nonce_reservation").Ctx(ctx).Data(reservation).InsertAndGetId()
+		if err != nil {
+			return err
+		}
+		reservation.Id = uint64(id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// UpdateReservation 更新nonce预留状态
+func (d *NonceDao) UpdateReservation(ctx context.Context, id uint64, data g.Map) error {
+	_, err := g.DB().Model("nonce_reservation").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
+// ReleaseByHash 释放交易对应的nonce预留, 用于交易被丢弃后填补空缺
+func (d *NonceDao) ReleaseByHash(ctx context.Context, chainId uint64, txHash string) error {
+	_, err := g.DB().Model("nonce_reservation").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("tx_hash", txHash).
+		Data(g.Map{
+			"status":     model.NonceStatusReleased,
+			"updated_at": time.Now().Unix(),
+		}).Update()
+	return err
+}
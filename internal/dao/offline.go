@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type OfflineDao struct{}
+
+var Offline = &OfflineDao{}
+
+// Insert 添加离线签名交易
+func (d *OfflineDao) Insert(ctx context.Context, offline *model.OfflineTransaction) error {
+	id, err := g.DB().Model("offline_transaction").Ctx(ctx).Data(offline).InsertAndGetId()
+	if err != nil {
+		return err
+	}
+	offline.Id = uint64(id)
+	return nil
+}
+
+// GetById 根据ID获取离线签名交易
+func (d *OfflineDao) GetById(ctx context.Context, id uint64) (*model.OfflineTransaction, error) {
+	var offline *model.OfflineTransaction
+	err := g.DB().Model("offline_transaction").Ctx(ctx).Where("id", id).Scan(&offline)
+	return offline, err
+}
+
+// Update 更新离线签名交易
+func (d *OfflineDao) Update(ctx context.Context, id uint64, data g.Map) error {
+	_, err := g.DB().Model("offline_transaction").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
+// MarkSubmitted 乐观更新为已广播, 仅待签名状态时生效, 防止同一信封重复提交
+func (d *OfflineDao) MarkSubmitted(ctx context.Context, id uint64, hash string, updatedAt int64) (bool, error) {
+	result, err := g.DB().Model("offline_transaction").Ctx(ctx).
+		Where("id", id).
+		Where("status", model.OfflineTxStatusPending).
+		Data(g.Map{
+			"status":     model.OfflineTxStatusSubmitted,
+			"hash":       hash,
+			"updated_at": updatedAt,
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,188 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type PayoutDao struct{}
+
+var Payout = &PayoutDao{}
+
+// CreateJob 在同一事务中添加批量付款任务及明细行
+func (d *PayoutDao) CreateJob(ctx context.Context, job *model.PayoutJob, items []*model.PayoutItem) error {
+	return g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		id, err := tx.Model("payout_job").Ctx(ctx).Data(job).InsertAndGetId()
+		if err != nil {
+			return err
+		}
+		job.Id = uint64(id)
+
+		for _, item := range items {
+			item.JobId = job.Id
+			itemId, err := tx.Model("payout_item").Ctx(ctx).Data(item).InsertAndGetId()
+			if err != nil {
+				return err
+			}
+			item.Id = uint64(itemId)
+		}
+		return nil
+	})
+}
+
+// GetJob 根据ID获取批量付款任务
+func (d *PayoutDao) GetJob(ctx context.Context, id uint64) (*model.PayoutJob, error) {
+	var job *model.PayoutJob
+	err := g.DB().Model("payout_job").Ctx(ctx).Where("id", id).Scan(&job)
+	return job, err
+}
+
+// GetJobList 获取用户的批量付款任务列表
+func (d *PayoutDao) GetJobList(ctx context.Context, userId uint64, page, pageSize int) (list []*model.PayoutJob, total int, err error) {
+	m := g.DB().Model("payout_job").Where("user_id", userId)
+
+	// 获取总数
+	total, err = m.Ctx(ctx).Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list = make([]*model.PayoutJob, 0)
+	err = m.Ctx(ctx).
+		Page(page, pageSize).
+		Order("id DESC").
+		Scan(&list)
+
+	return list, total, err
+}
+
+// GetActiveJobs 获取待执行与执行中的任务
+func (d *PayoutDao) GetActiveJobs(ctx context.Context, limit int) ([]*model.PayoutJob, error) {
+	var list []*model.PayoutJob
+	err := g.DB().Model("payout_job").Ctx(ctx).
+		WhereIn("status", g.Slice{model.PayoutJobStatusPending, model.PayoutJobStatusRunning}).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&list)
+	return list, err
+}
+
+// UpdateJob 更新批量付款任务
+func (d *PayoutDao) UpdateJob(ctx context.Context, id uint64, data g.Map) error {
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model("payout_job").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
+// GetItems 获取任务明细行
+func (d *PayoutDao) GetItems(ctx context.Context, jobId uint64) ([]*model.PayoutItem, error) {
+	var list []*model.PayoutItem
+	err := g.DB().Model("payout_item").Ctx(ctx).
+		Where("job_id", jobId).
+		Order("row_no ASC").
+		Scan(&list)
+	return list, err
+}
+
+// CountItems 统计任务中指定状态的明细行数
+func (d *PayoutDao) CountItems(ctx context.Context, jobId uint64, status int) (int, error) {
+	return g.DB().Model("payout_item").Ctx(ctx).
+		Where("job_id", jobId).
+		Where("status", status).
+		Count()
+}
+
+// UpdateItemsByBatch 将交易批次状态回写到所属明细行, 已是目标状态的行不重复更新
+func (d *PayoutDao) UpdateItemsByBatch(ctx context.Context, batchId uint64, status int, data g.Map) error {
+	data["status"] = status
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model("payout_item").Ctx(ctx).
+		Where("batch_id", batchId).
+		WhereNot("status", status).
+		Data(data).
+		Update()
+	return err
+}
+
+// PlanBatches 保存任务的交易批次并关联明细行, 任务置为执行中;
+// 锁定任务行且仅待执行状态时生效, 已规划的任务返回false
+func (d *PayoutDao) PlanBatches(ctx context.Context, jobId uint64, batches []*model.PayoutBatch, itemIds [][]uint64) (bool, error) {
+	planned := false
+	err := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		//1.锁定任务
+		var job *model.PayoutJob
+		err := tx.Model("payout_job").Ctx(ctx).Where("id", jobId).LockUpdate().Scan(&job)
+		if err != nil {
+			return err
+		}
+		if job == nil || job.Status != model.PayoutJobStatusPending {
+			return nil
+		}
+
+		//2.保存批次并关联明细行
+		now := time.Now().Unix()
+		for i, batch := range batches {
+			id, err := tx.Model("payout_batch").Ctx(ctx).Data(batch).InsertAndGetId()
+			if err != nil {
+				return err
+			}
+			batch.Id = uint64(id)
+			_, err = tx.Model("payout_item").Ctx(ctx).
+				WhereIn("id", itemIds[i]).
+				Data(g.Map{"batch_id": batch.Id, "updated_at": now}).
+				Update()
+			if err != nil {
+				return err
+			}
+		}
+
+		//3.任务置为执行中
+		_, err = tx.Model("payout_job").Ctx(ctx).Where("id", jobId).Data(g.Map{
+			"status":     model.PayoutJobStatusRunning,
+			"updated_at": now,
+		}).Update()
+		if err != nil {
+			return err
+		}
+		planned = true
+		return nil
+	})
+	return planned, err
+}
+
+// GetBatches 获取任务的交易批次
+func (d *PayoutDao) GetBatches(ctx context.Context, jobId uint64) ([]*model.PayoutBatch, error) {
+	var list []*model.PayoutBatch
+	err := g.DB().Model("payout_batch").Ctx(ctx).
+		Where("job_id", jobId).
+		Order("id ASC").
+		Scan(&list)
+	return list, err
+}
+
+// UpdateBatch 更新交易批次
+func (d *PayoutDao) UpdateBatch(ctx context.Context, id uint64, data g.Map) error {
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model("payout_batch").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
+// MarkBatchSigned 乐观更新为已签名并保存原始交易, 仅待签名状态时生效, 防止并发执行重复发送
+func (d *PayoutDao) MarkBatchSigned(ctx context.Context, id uint64, data g.Map) (bool, error) {
+	data["status"] = model.PayoutBatchStatusSigned
+	data["updated_at"] = time.Now().Unix()
+	result, err := g.DB().Model("payout_batch").Ctx(ctx).
+		Where("id", id).
+		Where("status", model.PayoutBatchStatusPending).
+		Data(data).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type ReindexDao struct{}
+
+var Reindex = &ReindexDao{}
+
+// Insert 添加重新索引任务
+func (d *ReindexDao) Insert(ctx context.Context, job *model.ReindexJob) error {
+	id, err := g.DB().Model("reindex_job").Ctx(ctx).Data(job).InsertAndGetId()
+	if err != nil {
+		return err
+	}
+	job.Id = uint64(id)
+	return nil
+}
+
+// Get 根据ID获取重新索引任务, 不存在时返回nil
+func (d *ReindexDao) Get(ctx context.Context, id uint64) (*model.ReindexJob, error) {
+	var job *model.ReindexJob
+	err := g.DB().Model("reindex_job").Ctx(ctx).Where("id", id).Scan(&job)
+	return job, err
+}
+
+// GetList 获取重新索引任务列表
+func (d *ReindexDao) GetList(ctx context.Context, page, pageSize int) (list []*model.ReindexJob, total int, err error) {
+	m := g.DB().Model("reindex_job")
+
+	// 获取总数
+	total, err = m.Ctx(ctx).Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list = make([]*model.ReindexJob, 0)
+	err = m.Ctx(ctx).
+		Page(page, pageSize).
+		Order("id DESC").
+		Scan(&list)
+
+	return list, total, err
+}
+
+// GetRunnable 获取待执行的任务, 以及执行中但在staleBefore之后未更新(执行实例中断)的任务
+func (d *ReindexDao) GetRunnable(ctx context.Context, staleBefore int64, limit int) ([]*model.ReindexJob, error) {
+	var list []*model.ReindexJob
+	err := g.DB().Model("reindex_job").Ctx(ctx).
+		Where("(status = ? OR (status = ? AND updated_at < ?))",
+			model.ReindexJobStatusPending, model.ReindexJobStatusRunning, staleBefore).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&list)
+	return list, err
+}
+
+// Claim 乐观更新为由runner执行, 仅任务待执行或执行实例已中断时生效, 防止多个实例同时执行
+func (d *ReindexDao) Claim(ctx context.Context, id uint64, runner string, staleBefore int64) (bool, error) {
+	result, err := g.DB().Model("reindex_job").Ctx(ctx).
+		Where("id", id).
+		Where("(status = ? OR (status = ? AND updated_at < ?))",
+			model.ReindexJobStatusPending, model.ReindexJobStatusRunning, staleBefore).
+		Data(g.Map{
+			"status":     model.ReindexJobStatusRunning,
+			"runner":     runner,
+			"updated_at": time.Now().Unix(),
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UpdateRunning 更新执行中的任务, 仅任务仍由runner执行时生效; 返回false表示任务已被取消或由其他实例接管
+func (d *ReindexDao) UpdateRunning(ctx context.Context, id uint64, runner string, data g.Map) (bool, error) {
+	data["updated_at"] = time.Now().Unix()
+	result, err := g.DB().Model("reindex_job").Ctx(ctx).
+		Where("id", id).
+		Where("runner", runner).
+		Where("status", model.ReindexJobStatusRunning).
+		Data(data).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Cancel 取消待执行或执行中的任务, 执行中的任务在处理完当前区块段后停止
+func (d *ReindexDao) Cancel(ctx context.Context, id uint64) (bool, error) {
+	now := time.Now().Unix()
+	result, err := g.DB().Model("reindex_job").Ctx(ctx).
+		Where("id", id).
+		WhereIn("status", g.Slice{model.ReindexJobStatusPending, model.ReindexJobStatusRunning}).
+		Data(g.Map{
+			"status":      model.ReindexJobStatusCancelled,
+			"finished_at": now,
+			"updated_at":  now,
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
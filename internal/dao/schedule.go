@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type ScheduleDao struct{}
+
+var Schedule = &ScheduleDao{}
+
+// Insert 添加定时转账
+func (d *ScheduleDao) Insert(ctx context.Context, schedule *model.ScheduledTransfer) error {
+	id, err := g.DB().Model("scheduled_transfer").Ctx(ctx).Data(schedule).InsertAndGetId()
+	if err != nil {
+		return err
+	}
+	schedule.Id = uint64(id)
+	return nil
+}
+
+// GetById 根据ID获取定时转账
+func (d *ScheduleDao) GetById(ctx context.Context, id uint64) (*model.ScheduledTransfer, error) {
+	var schedule *model.ScheduledTransfer
+	err := g.DB().Model("scheduled_transfer").Ctx(ctx).Where("id", id).Scan(&schedule)
+	return schedule, err
+}
+
+// GetList 获取用户的定时转账列表
+func (d *ScheduleDao) GetList(ctx context.Context, userId uint64, page, pageSize int) (list []*model.ScheduledTransfer, total int, err error) {
+	m := g.DB().Model("scheduled_transfer").Where("user_id", userId)
+
+	// 获取总数
+	total, err = m.Ctx(ctx).Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list = make([]*model.ScheduledTransfer, 0)
+	err = m.Ctx(ctx).
+		Page(page, pageSize).
+		Order("id DESC").
+		Scan(&list)
+
+	return list, total, err
+}
+
+// Update 更新定时转账
+func (d *ScheduleDao) Update(ctx context.Context, id uint64, data g.Map) error {
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model("scheduled_transfer").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
+// GetDue 获取已到执行时间的生效中定时转账
+func (d *ScheduleDao) GetDue(ctx context.Context, now int64, limit int) ([]*model.ScheduledTransfer, error) {
+	var list []*model.ScheduledTransfer
+	err := g.DB().Model("scheduled_transfer").Ctx(ctx).
+		Where("status", model.ScheduleStatusActive).
+		WhereLTE("next_run_at", now).
+		Order("next_run_at ASC").
+		Limit(limit).
+		Scan(&list)
+	return list, err
+}
+
+// Claim 锁定定时转账并登记本次执行, 推进下次执行时间;
+// 计划时间已被其他执行者推进时返回false, 保证同一计划时间只执行一次
+func (d *ScheduleDao) Claim(ctx context.Context, id uint64, scheduledAt int64, run *model.ScheduledRun, data g.Map) (bool, error) {
+	claimed := false
+	err := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		//1.锁定定时转账
+		var schedule *model.ScheduledTransfer
+		err := tx.Model("scheduled_transfer").Ctx(ctx).Where("id", id).LockUpdate().Scan(&schedule)
+		if err != nil {
+			return err
+		}
+		if schedule == nil || schedule.Status != model.ScheduleStatusActive || schedule.NextRunAt != scheduledAt {
+			return nil
+		}
+
+		//2.登记执行记录
+		runId, err := tx.Model("scheduled_run").Ctx(ctx).Data(run).InsertAndGetId()
+		if err != nil {
+			return err
+		}
+		run.Id = uint64(runId)
+
+		//3.推进下次执行时间
+		data["run_count"] = schedule.RunCount + 1
+		data["updated_at"] = time.Now().Unix()
+		_, err = tx.Model("scheduled_transfer").Ctx(ctx).Where("id", id).Data(data).Update()
+		if err != nil {
+			return err
+		}
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+// GetRuns 获取定时转账的执行记录
+func (d *ScheduleDao) GetRuns(ctx context.Context, scheduleId uint64, page, pageSize int) (list []*model.ScheduledRun, total int, err error) {
+	m := g.DB().Model("scheduled_run").Where("schedule_id", scheduleId)
+
+	// 获取总数
+	total, err = m.Ctx(ctx).Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list = make([]*model.ScheduledRun, 0)
+	err = m.Ctx(ctx).
+		Page(page, pageSize).
+		Order("id DESC").
+		Scan(&list)
+
+	return list, total, err
+}
+
+// GetRetryRuns 获取已到重试时间的执行记录
+func (d *ScheduleDao) GetRetryRuns(ctx context.Context, now int64, limit int) ([]*model.ScheduledRun, error) {
+	var list []*model.ScheduledRun
+	err := g.DB().Model("scheduled_run").Ctx(ctx).
+		Where("status", model.ScheduledRunStatusRetrying).
+		WhereLTE("next_retry_at", now).
+		Order("next_retry_at ASC").
+		Limit(limit).
+		Scan(&list)
+	return list, err
+}
+
+// ClaimRun 乐观更新为执行中, 仅指定状态时生效, 防止并发重复执行
+func (d *ScheduleDao) ClaimRun(ctx context.Context, id uint64, status int) (bool, error) {
+	result, err := g.DB().Model("scheduled_run").Ctx(ctx).
+		Where("id", id).
+		Where("status", status).
+		Data(g.Map{
+			"status":     model.ScheduledRunStatusRunning,
+			"updated_at": time.Now().Unix(),
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UpdateRun 更新执行记录
+func (d *ScheduleDao) UpdateRun(ctx context.Context, id uint64, data g.Map) error {
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model("scheduled_run").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
+// FailStaleRuns 将长时间停留在执行中的记录(进程中断)标记为失败, 不自动重试以免重复转账
+func (d *ScheduleDao) FailStaleRuns(ctx context.Context, before int64, reason string) error {
+	_, err := g.DB().Model("scheduled_run").Ctx(ctx).
+		Where("status", model.ScheduledRunStatusRunning).
+		WhereLT("updated_at", before).
+		Data(g.Map{
+			"status":     model.ScheduledRunStatusFailed,
+			"error":      reason,
+			"updated_at": time.Now().Unix(),
+		}).
+		Update()
+	return err
+}
@@ -0,0 +1,158 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"go-wallet-defi/internal/model"
+)
+
+type SchemaDao struct{}
+
+var Schema = &SchemaDao{}
+
+// schemaTable 表与模型的对应关系
+type schemaTable struct {
+	name   string
+	model  interface{}
+	unique [][]string // 唯一索引列, 生产环境(MySQL)的表结构与唯一索引见manifest/sql
+}
+
+// schemaTables 按模型生成的表结构
+var schemaTables = []schemaTable{
+	{name: "aggregator_transaction", model: model.AggregatorTransaction{}},
+	{name: "asset_analysis", model: model.AssetAnalysis{}},
+	{name: "asset_distribution", model: model.AssetDistribution{}},
+	{name: "batch_transaction", model: model.BatchTransaction{}},
+	{name: "bridge_transaction", model: model.BridgeTransaction{}},
+	{name: "chain", model: model.Chain{}, unique: [][]string{{"chain_id"}}},
+	{name: "contract", model: model.Contract{}},
+	{name: "contract_call", model: model.ContractCall{}},
+	{name: "contract_event", model: model.ContractEvent{}, unique: [][]string{{"chain_id", "tx_hash", "log_index"}}},
+	{name: "contract_mapping", model: model.ContractMapping{}},
+	{name: "cross_transfer", model: model.CrossTransfer{}},
+	{name: "dex_trade", model: model.DexTrade{}},
+	{name: "did_document", model: model.DIDDocument{}},
+	{name: "event_dead_letter", model: model.EventDeadLetter{}, unique: [][]string{{"chain_id", "source", "tx_hash", "log_index"}}},
+	{name: "gas_price", model: model.GasPrice{}},
+	{name: "gas_strategy", model: model.GasStrategy{}},
+	{name: "indexer_block", model: model.IndexerBlock{}, unique: [][]string{{"chain_id", "event_group", "block_number"}}},
+	{name: "indexer_cursor", model: model.IndexerCursor{}, unique: [][]string{{"chain_id", "source"}}},
+	{name: "indexer_log", model: model.IndexerLog{}, unique: [][]string{{"chain_id", "source", "tx_hash", "log_index"}}},
+	{name: "ipfs_file", model: model.IPFSFile{}},
+	{name: "lending", model: model.Lending{}},
+	{name: "lending_position", model: model.LendingPosition{}},
+	{name: "liquidity", model: model.Liquidity{}},
+	{name: "market_indicator", model: model.MarketIndicator{}},
+	{name: "mev_protection", model: model.MEVProtection{}},
+	{name: "multi_sig_transaction", model: model.MultiSigTransaction{}},
+	{name: "multi_sig_wallet", model: model.MultiSigWallet{}},
+	{name: "nft", model: model.NFT{}},
+	{name: "nft_market", model: model.NFTMarket{}},
+	{name: "nft_transaction", model: model.NFTTransaction{}},
+	{name: "nft_transfer", model: model.NFTTransfer{}, unique: [][]string{{"chain_id", "hash", "log_index"}}},
+	{name: "nonce_account", model: model.NonceAccount{}, unique: [][]string{{"chain_id", "address"}}},
+	{name: "nonce_reservation", model: model.NonceReservation{}},
+	{name: "offline_transaction", model: model.OfflineTransaction{}},
+	{name: "payout_batch", model: model.PayoutBatch{}},
+	{name: "payout_item", model: model.PayoutItem{}},
+	{name: "payout_job", model: model.PayoutJob{}},
+	{name: "profit_analysis", model: model.ProfitAnalysis{}},
+	{name: "proposal", model: model.Proposal{}},
+	{name: "reindex_job", model: model.ReindexJob{}},
+	{name: "risk_analysis", model: model.RiskAnalysis{}},
+	{name: "risk_log", model: model.RiskLog{}},
+	{name: "risk_rule", model: model.RiskRule{}},
+	{name: "scheduled_run", model: model.ScheduledRun{}},
+	{name: "scheduled_transfer", model: model.ScheduledTransfer{}},
+	{name: "swap_transaction", model: model.SwapTransaction{}},
+	{name: "transaction", model: model.Transaction{}},
+	{name: "transaction_analysis", model: model.TransactionAnalysis{}},
+	{name: "transaction_limit", model: model.TransactionLimit{}},
+	{name: "tx_acceleration", model: model.TxAcceleration{}},
+	{name: "tx_pattern", model: model.TxPattern{}},
+	{name: "tx_tracking", model: model.TxTracking{}, unique: [][]string{{"source", "source_id", "tx_hash"}}},
+	{name: "vault", model: model.Vault{}},
+	{name: "vote", model: model.Vote{}},
+	{name: "wallet", model: model.Wallet{}},
+	{name: "wallet_group", model: model.WalletGroup{}},
+	{name: "whitelist", model: model.Whitelist{}},
+	{name: "worker_job", model: model.WorkerJob{}, unique: [][]string{{"name"}}},
+	{name: "yield_farm", model: model.YieldFarm{}},
+}
+
+// IsSQLite 当前数据库是否为SQLite
+func (d *SchemaDao) IsSQLite() bool {
+	return g.DB().GetConfig().Type == "sqlite"
+}
+
+// Migrate 按模型创建缺失的表与唯一索引, 仅支持SQLite, 用于dev模式
+func (d *SchemaDao) Migrate(ctx context.Context) error {
+	if !d.IsSQLite() {
+		return fmt.Errorf("schema migration only supports sqlite, got %s", g.DB().GetConfig().Type)
+	}
+	for _, table := range schemaTables {
+		for _, sql := range table.ddl() {
+			if _, err := g.DB().Exec(ctx, sql); err != nil {
+				return fmt.Errorf("migrate %s: %w", table.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Drop 删除全部模型表, 仅支持SQLite
+func (d *SchemaDao) Drop(ctx context.Context) error {
+	if !d.IsSQLite() {
+		return fmt.Errorf("schema drop only supports sqlite, got %s", g.DB().GetConfig().Type)
+	}
+	for _, table := range schemaTables {
+		if _, err := g.DB().Exec(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, table.name)); err != nil {
+			return fmt.Errorf("drop %s: %w", table.name, err)
+		}
+	}
+	return nil
+}
+
+// ddl 生成建表与唯一索引语句, 列名为字段名的蛇形形式
+func (t schemaTable) ddl() []string {
+	typ := reflect.TypeOf(t.model)
+	columns := []string{`"id" INTEGER PRIMARY KEY AUTOINCREMENT`}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || field.Name == "Id" {
+			continue
+		}
+		columns = append(columns, fmt.Sprintf(`"%s" %s`, gstr.CaseSnake(field.Name), columnType(field.Type)))
+	}
+
+	sqls := []string{fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s" (%s)`, t.name, strings.Join(columns, ", "))}
+	for _, unique := range t.unique {
+		sqls = append(sqls, fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS "uk_%s_%s" ON "%s" ("%s")`,
+			t.name, strings.Join(unique, "_"), t.name, strings.Join(unique, `", "`)))
+	}
+	return sqls
+}
+
+// columnType 字段类型对应的SQLite列类型, 数值列默认0, 字符串列默认空串
+func columnType(typ reflect.Type) string {
+	if typ == reflect.TypeOf(&gtime.Time{}) {
+		return "DATETIME"
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER NOT NULL DEFAULT 0"
+	case reflect.Float32, reflect.Float64:
+		return "REAL NOT NULL DEFAULT 0"
+	case reflect.String:
+		return "TEXT NOT NULL DEFAULT ''"
+	default:
+		return "TEXT"
+	}
+}
@@ -80,6 +80,18 @@ func (d *SecurityDao) GetTransactionLimit(ctx context.Context, id uint64) (*mode
 	return limit, err
 }
 
+// GetUserTransactionLimit 获取用户指定代币的有效交易限额
+func (d *SecurityDao) GetUserTransactionLimit(ctx context.Context, userId uint64, tokenAddress string) (*model.TransactionLimit, error) {
+	var limit *model.TransactionLimit
+	err := g.DB().Model("transaction_limit").Ctx(ctx).
+		Where("user_id", userId).
+		Where("token_address", tokenAddress).
+		Where("status", 1).
+		Order("id DESC").
+		Scan(&limit)
+	return limit, err
+}
+
 // UpdateTransactionLimit 更新交易限额
 func (d *SecurityDao) UpdateTransactionLimit(ctx context.Context, id uint64, data g.Map) error {
 	_, err := g.DB().Model("transaction_limit").Ctx(ctx).Where("id", id).Data(data).Update()
@@ -105,6 +117,13 @@ func (d *SecurityDao) UpdateRiskRule(ctx context.Context, id uint64, data g.Map)
 	return err
 }
 
+// GetActiveRiskRules 获取启用的风控规则
+func (d *SecurityDao) GetActiveRiskRules(ctx context.Context) ([]*model.RiskRule, error) {
+	var list []*model.RiskRule
+	err := g.DB().Model("risk_rule").Ctx(ctx).Where("status", 1).Order("id ASC").Scan(&list)
+	return list, err
+}
+
 // CreateRiskLog 创建风控日志
 func (d *SecurityDao) CreateRiskLog(ctx context.Context, log *model.RiskLog) error {
 	_, err := g.DB().Model("risk_log").Ctx(ctx).Data(log).Insert()
@@ -118,12 +137,23 @@ func (d *SecurityDao) GetUserTxAmount(ctx context.Context, userId uint64, tokenA
 		Ctx(ctx).
 		Where("user_id", userId).
 		Where("token_address", tokenAddress).
+		WhereNot("direction", model.TransactionDirectionIn).
 		Where("created_at between ? and ?", startTime, endTime).
 		Fields("sum(amount) as total").
 		Scan(&amount)
 	return amount.Total, err
 }
 
+// GetUserTxCount 获取用户时间范围内的转出交易次数
+func (d *SecurityDao) GetUserTxCount(ctx context.Context, userId uint64, startTime, endTime int64) (int, error) {
+	return g.DB().Model("transaction").
+		Ctx(ctx).
+		Where("user_id", userId).
+		WhereNot("direction", model.TransactionDirectionIn).
+		Where("created_at between ? and ?", startTime, endTime).
+		Count()
+}
+
 // CheckWhitelist 检查白名单
 func (d *SecurityDao) CheckWhitelist(ctx context.Context, userId uint64, address string, txType int) (bool, error) {
 	count, err := g.DB().Model("whitelist").
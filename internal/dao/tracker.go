@@ -0,0 +1,90 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type TrackerDao struct{}
+
+var Tracker = &TrackerDao{}
+
+// GetPendingRows 获取业务表中尚未登记跟踪的待确认记录
+func (d *TrackerDao) GetPendingRows(ctx context.Context, source *model.TrackedSource, limit int) ([]*model.TrackedRow, error) {
+	fields := []interface{}{"id", source.HashField + " AS hash"}
+	if source.ChainField != "" {
+		fields = append(fields, source.ChainField+" AS chain_id")
+	}
+	var rows []*model.TrackedRow
+	err := g.DB().Model(source.Table).Ctx(ctx).
+		Fields(fields...).
+		Where(source.StatusField, source.PendingStatus).
+		WhereNot(source.HashField, "").
+		Where(fmt.Sprintf(
+			"NOT EXISTS (SELECT 1 FROM tx_tracking t WHERE t.source = ? AND t.source_id = %s.id AND t.tx_hash = %s.%s)",
+			source.Table, source.Table, source.HashField,
+		), source.Name).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&rows)
+	return rows, err
+}
+
+// Enroll 登记跟踪记录, 已存在时忽略
+func (d *TrackerDao) Enroll(ctx context.Context, tracking *model.TxTracking) error {
+	_, err := g.DB().Model("tx_tracking").Ctx(ctx).Data(tracking).InsertIgnore()
+	return err
+}
+
+// GetActive 获取未终结的跟踪记录
+func (d *TrackerDao) GetActive(ctx context.Context, limit int) ([]*model.TxTracking, error) {
+	var list []*model.TxTracking
+	err := g.DB().Model("tx_tracking").Ctx(ctx).
+		WhereIn("state", g.Slice{model.TxStatePending, model.TxStateIncluded, model.TxStateConfirmed}).
+		Order("updated_at ASC").
+		Limit(limit).
+		Scan(&list)
+	return list, err
+}
+
+// GetByHash 根据交易哈希获取跟踪记录
+func (d *TrackerDao) GetByHash(ctx context.Context, chainId uint64, txHash string) ([]*model.TxTracking, error) {
+	var list []*model.TxTracking
+	err := g.DB().Model("tx_tracking").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("tx_hash", txHash).
+		Scan(&list)
+	return list, err
+}
+
+// GetReplacement 查找同一发送地址与nonce的其他交易
+func (d *TrackerDao) GetReplacement(ctx context.Context, chainId uint64, fromAddress string, nonce uint64, txHash string) (*model.TxTracking, error) {
+	var tracking *model.TxTracking
+	err := g.DB().Model("tx_tracking").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("from_address", fromAddress).
+		Where("nonce", nonce).
+		WhereNot("tx_hash", txHash).
+		WhereNotIn("state", g.Slice{model.TxStateDropped, model.TxStateReplaced}).
+		Order("id DESC").
+		Scan(&tracking)
+	return tracking, err
+}
+
+// Update 更新跟踪记录
+func (d *TrackerDao) Update(ctx context.Context, id uint64, data g.Map) error {
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model("tx_tracking").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
+// UpdateSource 回写业务表记录
+func (d *TrackerDao) UpdateSource(ctx context.Context, table string, id uint64, data g.Map) error {
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model(table).Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
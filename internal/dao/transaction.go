@@ -22,6 +22,12 @@ func (d *TransactionDao) Update(ctx context.Context, hash string, data g.Map) er
 	return err
 }
 
+// UpdateById 按ID更新交易记录
+func (d *TransactionDao) UpdateById(ctx context.Context, id uint64, data g.Map) error {
+	_, err := g.DB().Model("transaction").Ctx(ctx).Where("id", id).Data(data).Update()
+	return err
+}
+
 // GetList 获取交易列表
 func (d *TransactionDao) GetList(ctx context.Context, address string, page, pageSize int) (list []*model.Transaction, total int, err error) {
 	m := g.DB().Model("transaction").
@@ -49,3 +55,13 @@ func (d *TransactionDao) GetByHash(ctx context.Context, hash string) (*model.Tra
 	err := g.DB().Model("transaction").Ctx(ctx).Where("hash", hash).Scan(&tx)
 	return tx, err
 }
+
+// GetByChainHash 获取链上同一交易哈希的全部记录
+func (d *TransactionDao) GetByChainHash(ctx context.Context, chainId uint64, hash string) ([]*model.Transaction, error) {
+	var list []*model.Transaction
+	err := g.DB().Model("transaction").Ctx(ctx).
+		Where("chain_id", chainId).
+		Where("hash", hash).
+		Scan(&list)
+	return list, err
+}
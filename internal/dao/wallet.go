@@ -2,10 +2,16 @@ package dao
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/gogf/gf/v2/frame/g"
 	"go-wallet-defi/internal/model"
+	"strings"
+	"time"
 )
 
+// likeEscaper 转义LIKE通配符, 使用'!'作为转义符以兼容MySQL与SQLite
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
 type WalletDao struct{}
 
 var Wallet = WalletDao{}
@@ -22,24 +28,51 @@ func (d *WalletDao) GetByAddress(ctx context.Context, address string) (*model.Wa
 }
 
 // GetList 获取钱包列表
-func (d *WalletDao) GetList(ctx context.Context, page, pageSize int) (list []*model.Wallet, err error) {
+func (d *WalletDao) GetList(ctx context.Context, filter *model.WalletFilter, page, pageSize int) (list []*model.Wallet, total int, err error) {
 	m := g.DB().Model("wallet")
 
+	// 筛选条件
+	if filter != nil {
+		if filter.UserId > 0 {
+			m = m.Where("user_id", filter.UserId)
+		}
+		if filter.Chain != "" {
+			m = m.Where("chain", filter.Chain)
+		}
+		if filter.WalletType > 0 {
+			m = m.Where("wallet_type", filter.WalletType)
+		}
+		if filter.GroupId > 0 {
+			m = m.Where("group_id", filter.GroupId)
+		}
+		if filter.Tag != "" {
+			// tags为json.Marshal编码的数组, 按相同编码匹配完整元素, 并转义LIKE通配符
+			tag, err := json.Marshal(filter.Tag)
+			if err != nil {
+				return nil, 0, err
+			}
+			m = m.Where("tags LIKE ? ESCAPE '!'", "%"+likeEscaper.Replace(string(tag))+"%")
+		}
+		if filter.Label != "" {
+			m = m.WhereLike("label", "%"+filter.Label+"%")
+		}
+	}
+
 	// 获取总数
-	_, err = m.Ctx(ctx).Count()
+	total, err = m.Ctx(ctx).Count()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// 不需要返回敏感信息
 	list = make([]*model.Wallet, 0)
 	err = m.Ctx(ctx).
-		Fields("id, user_id, address, chain, wallet_type, created_at, updated_at").
+		Fields("id, user_id, address, chain, wallet_type, label, tags, group_id, created_at, updated_at").
 		Page(page, pageSize).
 		Order("created_at DESC").
 		Scan(&list)
 
-	return list, err
+	return list, total, err
 }
 
 // GetListByUserId 根据用户ID获取钱包列表
@@ -56,7 +89,7 @@ func (d *WalletDao) GetListByUserId(ctx context.Context, userId uint64, page, pa
 	// 不需要返回敏感信息
 	list = make([]*model.Wallet, 0)
 	err = m.Ctx(ctx).
-		Fields("id, user_id, address, chain, wallet_type, created_at, updated_at").
+		Fields("id, user_id, address, chain, wallet_type, label, tags, group_id, created_at, updated_at").
 		Page(page, pageSize).
 		Order("created_at DESC").
 		Scan(&list)
@@ -74,3 +107,78 @@ func (d *WalletDao) List(ctx context.Context, page, pageSize int) ([]*model.Wall
 	err := g.DB().Model("wallet").Ctx(ctx).Page(page, pageSize).Order("id DESC").Scan(&wallets)
 	return wallets, err
 }
+
+// GetAddresses 获取全部托管钱包地址, 不含敏感字段
+func (d *WalletDao) GetAddresses(ctx context.Context) ([]*model.Wallet, error) {
+	var wallets []*model.Wallet
+	err := g.DB().Model("wallet").Ctx(ctx).
+		Fields("id, user_id, address").
+		Order("id ASC").
+		Scan(&wallets)
+	return wallets, err
+}
+
+// GetSecretsAfterId 按ID分批获取钱包加密字段, 用于密钥轮换
+func (d *WalletDao) GetSecretsAfterId(ctx context.Context, lastId uint64, limit int) ([]*model.Wallet, error) {
+	var wallets []*model.Wallet
+	err := g.DB().Model("wallet").Ctx(ctx).
+		Fields("id, address, mnemonic, private_key").
+		WhereGT("id", lastId).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&wallets)
+	return wallets, err
+}
+
+// UpdateSecrets 乐观更新钱包加密字段, 仅当密文未被并发修改时生效
+func (d *WalletDao) UpdateSecrets(ctx context.Context, wallet *model.Wallet, mnemonic, privateKey string) (bool, error) {
+	result, err := g.DB().Model("wallet").Ctx(ctx).
+		Data(g.Map{
+			"mnemonic":    mnemonic,
+			"private_key": privateKey,
+			"updated_at":  time.Now().Unix(),
+		}).
+		Where("id", wallet.Id).
+		Where("mnemonic", wallet.Mnemonic).
+		Where("private_key", wallet.PrivateKey).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UpdateMeta 更新钱包标签、标签列表与分组
+func (d *WalletDao) UpdateMeta(ctx context.Context, address string, data g.Map) error {
+	_, err := g.DB().Model("wallet").Ctx(ctx).Data(data).Where("address", address).Update()
+	return err
+}
+
+// InsertGroup 创建钱包分组
+func (d *WalletDao) InsertGroup(ctx context.Context, group *model.WalletGroup) error {
+	id, err := g.DB().Model("wallet_group").Ctx(ctx).Data(group).InsertAndGetId()
+	if err != nil {
+		return err
+	}
+	group.Id = uint64(id)
+	return nil
+}
+
+// GetGroupById 获取钱包分组
+func (d *WalletDao) GetGroupById(ctx context.Context, id uint64) (*model.WalletGroup, error) {
+	var group *model.WalletGroup
+	err := g.DB().Model("wallet_group").Ctx(ctx).Where("id", id).Scan(&group)
+	return group, err
+}
+
+// GetGroups 获取钱包分组列表
+func (d *WalletDao) GetGroups(ctx context.Context, userId uint64) ([]*model.WalletGroup, error) {
+	m := g.DB().Model("wallet_group").Ctx(ctx)
+	if userId > 0 {
+		m = m.Where("user_id", userId)
+	}
+	var groups []*model.WalletGroup
+	err := m.Order("id ASC").Scan(&groups)
+	return groups, err
+}
@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+type WorkerDao struct{}
+
+var Worker = &WorkerDao{}
+
+// AcquireLease 获取或续约任务租约, 仅租约已由holder持有或已过期时生效, 返回是否持有租约
+// 每次生效都递增lease_version, 保证更新必然改变行数据, 受影响行数可用于判断是否成功
+func (d *WorkerDao) AcquireLease(ctx context.Context, name, holder string, expiresAt int64) (bool, error) {
+	now := time.Now().Unix()
+
+	//1.初始化任务行
+	_, err := g.DB().Model("worker_job").Ctx(ctx).Data(g.Map{
+		"name":       name,
+		"state":      model.WorkerJobStateStopped,
+		"updated_at": now,
+	}).InsertIgnore()
+	if err != nil {
+		return false, err
+	}
+
+	//2.乐观更新租约
+	result, err := g.DB().Model("worker_job").Ctx(ctx).
+		Where("name", name).
+		Where("(holder = ? OR lease_expires_at < ?)", holder, now).
+		Data(g.Map{
+			"holder":           holder,
+			"lease_expires_at": expiresAt,
+			"lease_version":    &gdb.Counter{Field: "lease_version", Value: 1},
+			"updated_at":       now,
+		}).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ReleaseLease 释放holder持有的任务租约
+func (d *WorkerDao) ReleaseLease(ctx context.Context, name, holder string) error {
+	_, err := g.DB().Model("worker_job").Ctx(ctx).
+		Where("name", name).
+		Where("holder", holder).
+		Data(g.Map{
+			"holder":           "",
+			"lease_expires_at": 0,
+			"state":            model.WorkerJobStateStopped,
+			"updated_at":       time.Now().Unix(),
+		}).
+		Update()
+	return err
+}
+
+// UpdateJob 更新任务运行状态, 仅holder仍持有租约时生效
+func (d *WorkerDao) UpdateJob(ctx context.Context, name, holder string, data g.Map) error {
+	data["updated_at"] = time.Now().Unix()
+	_, err := g.DB().Model("worker_job").Ctx(ctx).
+		Where("name", name).
+		Where("holder", holder).
+		Data(data).
+		Update()
+	return err
+}
+
+// GetJobList 获取全部后台任务, 按名称排序
+func (d *WorkerDao) GetJobList(ctx context.Context) ([]*model.WorkerJob, error) {
+	var list []*model.WorkerJob
+	err := g.DB().Model("worker_job").Ctx(ctx).
+		Order("name ASC").
+		Scan(&list)
+	return list, err
+}
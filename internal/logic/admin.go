@@ -0,0 +1,32 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/ethclientx"
+)
+
+type AdminLogic struct{}
+
+// RpcHealth 获取已创建节点池的节点健康状态
+func (s *AdminLogic) RpcHealth(ctx context.Context) []*model.RpcEndpointHealth {
+	return ethclientx.Health()
+}
+
+// WorkerJobs 获取后台任务的租约与运行状态, 租约已过期但未释放的任务标记为lost
+func (s *AdminLogic) WorkerJobs(ctx context.Context) ([]*model.WorkerJob, error) {
+	jobs, err := dao.Worker.GetJobList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	for _, job := range jobs {
+		if job.Holder != "" && job.LeaseExpiresAt < now {
+			job.State = model.WorkerJobStateLost
+		}
+	}
+	return jobs, nil
+}
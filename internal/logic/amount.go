@@ -0,0 +1,160 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/amountx"
+	"go-wallet-defi/internal/pkg/contracts/token"
+)
+
+const (
+	defaultNativeSymbol   = "ETH"
+	defaultNativeDecimals = 18
+)
+
+// tokenMetaCache 代币元数据缓存, 键为 <chainId>:<address>
+var tokenMetaCache sync.Map
+
+type AmountLogic struct{}
+
+// Parse 按单位解析金额, 支持 "1.5" 或 "1.5 USDC", 代币地址为空或零地址时使用链原生代币精度;
+// unit为decimal时按代币精度换算, 为raw时为最小单位整数, 为空时使用配置amount.defaultUnit(默认raw, 兼容未传unit的旧客户端)
+func (s *AmountLogic) Parse(ctx context.Context, chainId uint64, tokenAddress, amount, unit string) (*model.TokenAmount, error) {
+	client, chainId, err := chainClient(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	return parseAmount(ctx, client, chainId, tokenAddress, amount, unit)
+}
+
+// Format 按代币精度格式化最小单位金额, 元数据获取失败时仅返回原始金额
+func (s *AmountLogic) Format(ctx context.Context, chainId uint64, tokenAddress, raw string) *model.TokenAmount {
+	result := &model.TokenAmount{TokenAddress: tokenAddress, Raw: raw}
+	value, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return result
+	}
+	meta, err := s.TokenMeta(ctx, chainId, tokenAddress)
+	if err != nil {
+		g.Log().Warningf(ctx, "resolve token %s on chain %d failed: %v", tokenAddress, chainId, err)
+		return result
+	}
+	return newTokenAmount(tokenAddress, meta, value)
+}
+
+// TokenMeta 获取代币符号与精度
+func (s *AmountLogic) TokenMeta(ctx context.Context, chainId uint64, tokenAddress string) (*model.TokenMeta, error) {
+	client, chainId, err := chainClient(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	return tokenMeta(ctx, client, chainId, tokenAddress)
+}
+
+// parseAmount 按单位解析金额并校验符号
+func parseAmount(ctx context.Context, client *ethclient.Client, chainId uint64, tokenAddress, amount, unit string) (*model.TokenAmount, error) {
+	//1.拆分金额与符号, 确定单位
+	value, symbol := amountx.SplitSymbol(amount)
+	if value == "" {
+		return nil, amountx.ErrEmptyAmount
+	}
+	if unit == "" {
+		unit = g.Cfg().MustGet(ctx, "amount.defaultUnit", amountx.UnitRaw).String()
+	}
+	if unit != amountx.UnitDecimal && unit != amountx.UnitRaw {
+		return nil, fmt.Errorf("unknown amount unit %q, expected %s or %s", unit, amountx.UnitDecimal, amountx.UnitRaw)
+	}
+
+	//2.获取代币精度
+	meta, err := tokenMeta(ctx, client, chainId, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if symbol != "" && !strings.EqualFold(symbol, meta.Symbol) {
+		return nil, fmt.Errorf("amount symbol %s does not match token symbol %s", symbol, meta.Symbol)
+	}
+
+	//3.按精度换算
+	var raw *big.Int
+	if unit == amountx.UnitRaw {
+		raw, err = amountx.ParseRaw(value)
+	} else {
+		raw, err = amountx.ParseUnits(value, meta.Decimals)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newTokenAmount(tokenAddress, meta, raw), nil
+}
+
+// newTokenAmount 按代币元数据构建金额
+func newTokenAmount(tokenAddress string, meta *model.TokenMeta, raw *big.Int) *model.TokenAmount {
+	return &model.TokenAmount{
+		TokenAddress: tokenAddress,
+		Symbol:       meta.Symbol,
+		Decimals:     meta.Decimals,
+		Raw:          raw.String(),
+		Formatted:    amountx.FormatUnits(raw, meta.Decimals),
+	}
+}
+
+// tokenMeta 获取代币元数据, 原生代币读取链配置, ERC20代币读取合约并缓存
+func tokenMeta(ctx context.Context, client *ethclient.Client, chainId uint64, tokenAddress string) (*model.TokenMeta, error) {
+	if isNativeToken(tokenAddress) {
+		chain, err := dao.Chain.GetByChainId(ctx, chainId)
+		if err != nil {
+			return nil, err
+		}
+		meta := &model.TokenMeta{Symbol: defaultNativeSymbol, Decimals: defaultNativeDecimals}
+		if chain != nil {
+			if chain.Symbol != "" {
+				meta.Symbol = chain.Symbol
+			}
+			if chain.Decimals > 0 {
+				meta.Decimals = chain.Decimals
+			}
+		}
+		return meta, nil
+	}
+
+	if !common.IsHexAddress(tokenAddress) {
+		return nil, fmt.Errorf("invalid token address %q", tokenAddress)
+	}
+	address := common.HexToAddress(tokenAddress)
+	key := fmt.Sprintf("%d:%s", chainId, address.Hex())
+	if cached, ok := tokenMetaCache.Load(key); ok {
+		return cached.(*model.TokenMeta), nil
+	}
+
+	erc20, err := token.NewERC20(address, client)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := erc20.Decimals()
+	if err != nil {
+		return nil, fmt.Errorf("get decimals of token %s failed: %w", address.Hex(), err)
+	}
+	symbol, err := erc20.Symbol()
+	if err != nil {
+		// 部分代币symbol返回bytes32, 不影响精度换算
+		g.Log().Warningf(ctx, "get symbol of token %s failed: %v", address.Hex(), err)
+	}
+
+	meta := &model.TokenMeta{Symbol: symbol, Decimals: int(decimals)}
+	tokenMetaCache.Store(key, meta)
+	return meta, nil
+}
+
+// isNativeToken 判断是否为链原生代币
+func isNativeToken(tokenAddress string) bool {
+	return tokenAddress == "" || common.HexToAddress(tokenAddress) == (common.Address{})
+}
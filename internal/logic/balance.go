@@ -0,0 +1,276 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/amountx"
+	"go-wallet-defi/internal/pkg/contracts/multicall"
+	"go-wallet-defi/internal/pkg/contracts/token"
+	"go-wallet-defi/internal/pkg/ethclientx"
+)
+
+// GetBalance 查询钱包在所有启用链上的原生代币与配置代币余额
+func (s *WalletLogic) GetBalance(ctx context.Context, address, fiat string) (*model.WalletBalance, error) {
+	balances, err := s.GetBalances(ctx, []string{address}, fiat)
+	if err != nil {
+		return nil, err
+	}
+	return balances[address], nil
+}
+
+// GetBalances 批量查询多个钱包余额, 每条链通过Multicall3 aggregate3合并查询
+func (s *WalletLogic) GetBalances(ctx context.Context, addresses []string, fiat string) (map[string]*model.WalletBalance, error) {
+	//1.获取启用的链
+	chains, err := dao.Chain.GetActiveList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.获取法币价格
+	var prices map[string]*big.Rat
+	if fiat != "" {
+		prices, err = loadFiatPrices(ctx, fiat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	//3.并发查询各链余额
+	chainResults := make([]map[string]*model.ChainBalance, len(chains))
+	var wg sync.WaitGroup
+	for i, chain := range chains {
+		wg.Add(1)
+		go func(i int, chain *model.Chain) {
+			defer wg.Done()
+			chainResults[i] = queryChainBalances(ctx, chain, addresses, prices)
+		}(i, chain)
+	}
+	wg.Wait()
+
+	//4.按钱包汇总
+	result := make(map[string]*model.WalletBalance, len(addresses))
+	for _, address := range addresses {
+		balance := &model.WalletBalance{
+			Address:      address,
+			FiatCurrency: strings.ToUpper(fiat),
+			Chains:       make([]*model.ChainBalance, 0, len(chains)),
+		}
+		total := new(big.Rat)
+		for _, chainResult := range chainResults {
+			chainBalance := chainResult[address]
+			balance.Chains = append(balance.Chains, chainBalance)
+			if value, ok := new(big.Rat).SetString(chainBalance.FiatValue); ok {
+				total.Add(total, value)
+			}
+		}
+		if prices != nil {
+			balance.FiatValue = total.FloatString(2)
+		}
+		result[address] = balance
+	}
+
+	return result, nil
+}
+
+// queryChainBalances 查询单条链上多个钱包的余额, 失败时记录在结果的Error字段
+func queryChainBalances(ctx context.Context, chain *model.Chain, addresses []string, prices map[string]*big.Rat) map[string]*model.ChainBalance {
+	tokens := balanceTokens(ctx, chain.ChainId)
+	result := make(map[string]*model.ChainBalance, len(addresses))
+	for _, address := range addresses {
+		chainBalance := &model.ChainBalance{
+			ChainId: chain.ChainId,
+			Name:    chain.Name,
+			Native: &model.TokenBalance{
+				Symbol:   chain.Symbol,
+				Decimals: chain.Decimals,
+				Raw:      "0",
+				Balance:  "0",
+			},
+			Tokens: make([]*model.TokenBalance, 0, len(tokens)),
+		}
+		for _, t := range tokens {
+			chainBalance.Tokens = append(chainBalance.Tokens, &model.TokenBalance{
+				TokenAddress: common.HexToAddress(t.Address).Hex(),
+				Symbol:       t.Symbol,
+				Decimals:     t.Decimals,
+				Raw:          "0",
+				Balance:      "0",
+			})
+		}
+		result[address] = chainBalance
+	}
+
+	if err := fillChainBalances(ctx, chain, addresses, tokens, result); err != nil {
+		g.Log().Warningf(ctx, "query balances on chain %d failed: %v", chain.ChainId, err)
+		for _, chainBalance := range result {
+			chainBalance.Error = err.Error()
+		}
+		return result
+	}
+
+	// 计算法币估值
+	if prices != nil {
+		for _, chainBalance := range result {
+			total := new(big.Rat)
+			for _, b := range append([]*model.TokenBalance{chainBalance.Native}, chainBalance.Tokens...) {
+				price, ok := prices[strings.ToUpper(b.Symbol)]
+				if !ok {
+					continue
+				}
+				raw, _ := new(big.Int).SetString(b.Raw, 10)
+				value := new(big.Rat).Mul(amountx.ToRat(raw, b.Decimals), price)
+				b.FiatValue = value.FloatString(2)
+				total.Add(total, value)
+			}
+			chainBalance.FiatValue = total.FloatString(2)
+		}
+	}
+
+	return result
+}
+
+// fillChainBalances 通过aggregate3批量查询余额并写入结果
+func fillChainBalances(ctx context.Context, chain *model.Chain, addresses []string, tokens []*model.BalanceToken, result map[string]*model.ChainBalance) error {
+	client, err := ethclientx.GetClientByChainId(ctx, chain.ChainId)
+	if err != nil {
+		return err
+	}
+	mc, err := multicall.NewMulticall3(multicallAddress(ctx, chain.ChainId), client)
+	if err != nil {
+		return err
+	}
+	erc20, err := abi.JSON(strings.NewReader(token.ERC20ABI))
+	if err != nil {
+		return err
+	}
+
+	//1.未配置精度的代币先查询decimals
+	calls := make([]multicall.Call3, 0, len(addresses)*(len(tokens)+1)+len(tokens))
+	decimalsIndex := make(map[int]int)
+	for i, t := range tokens {
+		if t.Decimals > 0 {
+			continue
+		}
+		data, err := erc20.Pack("decimals")
+		if err != nil {
+			return err
+		}
+		decimalsIndex[i] = len(calls)
+		calls = append(calls, multicall.Call3{Target: common.HexToAddress(t.Address), AllowFailure: true, CallData: data})
+	}
+
+	//2.每个钱包查询原生余额与代币余额
+	for _, address := range addresses {
+		owner := common.HexToAddress(address)
+		data, err := mc.PackGetEthBalance(owner)
+		if err != nil {
+			return err
+		}
+		calls = append(calls, multicall.Call3{Target: mc.Address(), AllowFailure: true, CallData: data})
+		for _, t := range tokens {
+			data, err := erc20.Pack("balanceOf", owner)
+			if err != nil {
+				return err
+			}
+			calls = append(calls, multicall.Call3{Target: common.HexToAddress(t.Address), AllowFailure: true, CallData: data})
+		}
+	}
+
+	//3.执行批量调用
+	results, err := mc.Aggregate3(ctx, calls, nil)
+	if err != nil {
+		return err
+	}
+	if len(results) != len(calls) {
+		return fmt.Errorf("multicall returned %d results for %d calls", len(results), len(calls))
+	}
+
+	//4.解析精度
+	decimals := make([]int, len(tokens))
+	for i, t := range tokens {
+		decimals[i] = t.Decimals
+		if index, ok := decimalsIndex[i]; ok && results[index].Success {
+			if v := multicall.UnpackUint256(results[index].ReturnData); v != nil {
+				decimals[i] = int(v.Int64())
+			}
+		}
+	}
+
+	//5.解析余额
+	cursor := len(decimalsIndex)
+	for _, address := range addresses {
+		chainBalance := result[address]
+		setTokenBalance(chainBalance.Native, results[cursor], chain.Decimals)
+		cursor++
+		for i := range tokens {
+			setTokenBalance(chainBalance.Tokens[i], results[cursor], decimals[i])
+			cursor++
+		}
+	}
+
+	return nil
+}
+
+// setTokenBalance 按精度换算余额
+func setTokenBalance(b *model.TokenBalance, r multicall.Result, decimals int) {
+	b.Decimals = decimals
+	if !r.Success {
+		return
+	}
+	raw := multicall.UnpackUint256(r.ReturnData)
+	if raw == nil {
+		return
+	}
+	b.Raw = raw.String()
+	b.Balance = amountx.FormatUnits(raw, decimals)
+}
+
+// balanceTokens 读取链的余额查询代币列表, 配置项 balance.tokens.<chainId>
+func balanceTokens(ctx context.Context, chainId uint64) []*model.BalanceToken {
+	var tokens []*model.BalanceToken
+	if err := g.Cfg().MustGet(ctx, fmt.Sprintf("balance.tokens.%d", chainId)).Scan(&tokens); err != nil {
+		g.Log().Warningf(ctx, "invalid balance.tokens for chain %d: %v", chainId, err)
+		return nil
+	}
+	return tokens
+}
+
+// multicallAddress 获取链上Multicall3地址, 可通过 multicall.addresses.<chainId> 覆盖
+func multicallAddress(ctx context.Context, chainId uint64) common.Address {
+	address := g.Cfg().MustGet(ctx, fmt.Sprintf("multicall.addresses.%d", chainId)).String()
+	if address == "" {
+		address = multicall.DefaultAddress
+	}
+	return common.HexToAddress(address)
+}
+
+// loadFiatPrices 从市场指标读取代币法币价格, 交易对格式为 <SYMBOL>/<FIAT>
+func loadFiatPrices(ctx context.Context, fiat string) (map[string]*big.Rat, error) {
+	indicators, err := dao.Analysis.GetMarketIndicators(ctx)
+	if err != nil {
+		return nil, err
+	}
+	suffix := "/" + strings.ToUpper(fiat)
+	prices := make(map[string]*big.Rat)
+	for _, indicator := range indicators {
+		symbol := strings.ToUpper(indicator.Symbol)
+		if !strings.HasSuffix(symbol, suffix) {
+			continue
+		}
+		price, ok := new(big.Rat).SetString(indicator.Price)
+		if !ok {
+			continue
+		}
+		prices[strings.TrimSuffix(symbol, suffix)] = price
+	}
+	return prices, nil
+}
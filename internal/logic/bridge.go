@@ -2,11 +2,13 @@ package logic
 
 import (
 	"context"
-	_ "crypto/ecdsa"
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -21,9 +23,12 @@ import (
 	"time"
 )
 
+// bridgeABI 跨链桥合约ABI
+var bridgeABI, _ = abi.JSON(strings.NewReader(bridge.BridgeABI))
+
 type BridgeLogic struct{}
 
-func (s BridgeLogic) CrossTransfer(ctx context.Context, fromChainId, toChainId uint64, fromAddress, toAddress, tokenAddress, amount string) (hash string, nonce uint64, err error) {
+func (s BridgeLogic) CrossTransfer(ctx context.Context, fromChainId, toChainId uint64, fromAddress, toAddress, tokenAddress, amount, unit string) (hash string, nonce uint64, err error) {
 	//1.获取来源链信息
 	fromChain, err := dao.Chain.GetById(ctx, fromChainId)
 	if err != nil {
@@ -43,33 +48,33 @@ func (s BridgeLogic) CrossTransfer(ctx context.Context, fromChainId, toChainId u
 	if err != nil {
 		return "", 0, err
 	}
-	//5.解析ABI
+	//5.按代币精度解析金额
+	amountValue, err := parseAmount(ctx, client, fromChain.ChainId, tokenAddress, amount, unit)
+	if err != nil {
+		return "", 0, err
+	}
+	amountBig, _ := new(big.Int).SetString(amountValue.Raw, 10)
+	//6.解析ABI
 	parsed, err := abi.JSON(strings.NewReader(bridge.BridgeABI))
 	if err != nil {
 		return "", 0, err
 	}
-	//6.如果是代币，需要先approve
+	//7.如果是代币，需要先approve
 	if tokenAddress != "" {
-		//6.1获取代币合约
+		//7.1获取代币合约
 		token, err := token.NewERC20(common.HexToAddress(tokenAddress), client)
 		if err != nil {
 			return "", 0, err
 		}
 
 		// 调用approve方法
-		approveData, err := token.PackApprove(common.HexToAddress(fromChain.BridgeAddress), new(big.Int).SetString(amount, 10))
+		approveData, err := token.PackApprove(common.HexToAddress(fromChain.BridgeAddress), amountBig)
 		if err != nil {
 			return "", 0, err
 		}
 
-		//6.2发送approve交易
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, tokenAddress, big.NewInt(0), approveData)
-		if err != nil {
-			return "", 0, err
-		}
-
-		//6.3等待approve交易确认
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		//7.2发送approve交易并等待确认
+		err = sendApproval(ctx, client, fromAddress, tokenAddress, approveData)
 		if err != nil {
 			return "", 0, err
 		}
@@ -78,13 +83,39 @@ func (s BridgeLogic) CrossTransfer(ctx context.Context, fromChainId, toChainId u
 	// 构造lock方法调用数据
 	value := big.NewInt(0)
 	if tokenAddress == "" {
-		value, _ = new(big.Int).SetString(amount, 10)
+		value = amountBig
 		tokenAddress = "0x0000000000000000000000000000000000000000"
 	}
 
+	// 先保存跨链交易记录, 以记录ID作为跨链序号, 锁定事件按来源链、目标链与序号匹配该记录
+	transfer := &model.CrossTransfer{
+		FromChainId:  fromChainId,
+		ToChainId:    toChainId,
+		FromAddress:  fromAddress,
+		ToAddress:    toAddress,
+		TokenAddress: tokenAddress,
+		Amount:       amountValue.Raw,
+		Fee:          "0",
+		Status:       model.CrossTransferStatusPending,
+		CreatedAt:    time.Now().Unix(),
+		UpdatedAt:    time.Now().Unix(),
+	}
+	err = dao.Chain.InsertCrossTransfer(ctx, transfer)
+	if err != nil {
+		return "", 0, err
+	}
+	nonce = transfer.Id
+	err = dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, g.Map{
+		"nonce":      nonce,
+		"updated_at": time.Now().Unix(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
 	data, err := parsed.Pack("lock",
 		common.HexToAddress(tokenAddress),
-		interface{}(new(big.Int).SetString(amount, 10)),
+		amountBig,
 		new(big.Int).SetUint64(toChainId),
 		common.HexToAddress(toAddress),
 		new(big.Int).SetUint64(nonce),
@@ -93,29 +124,24 @@ func (s BridgeLogic) CrossTransfer(ctx context.Context, fromChainId, toChainId u
 		return "", 0, err
 	}
 
-	// 发送交易
-	hash, err = s.sendTransaction(ctx, client, fromAddress, fromChain.BridgeAddress, value, data)
+	// 发送交易, 失败时标记记录为失败
+	hash, err = sendTransaction(ctx, client, fromAddress, fromChain.BridgeAddress, value, data)
 	if err != nil {
+		if updateErr := dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, g.Map{
+			"status":     model.CrossTransferStatusFailed,
+			"error":      err.Error(),
+			"updated_at": time.Now().Unix(),
+		}); updateErr != nil {
+			g.Log().Warningf(ctx, "mark cross transfer %d failed: %v", transfer.Id, updateErr)
+		}
 		return "", 0, err
 	}
 
-	// 保存跨链交易记录
-	transfer := &model.CrossTransfer{
-		FromChainId:  fromChainId,
-		ToChainId:    toChainId,
-		FromAddress:  fromAddress,
-		ToAddress:    toAddress,
-		TokenAddress: tokenAddress,
-		Amount:       amount,
-		Fee:          "0",
-		Nonce:        nonce,
-		FromHash:     hash,
-		Status:       0,
-		CreatedAt:    time.Now().Unix(),
-		UpdatedAt:    time.Now().Unix(),
-	}
-
-	err = dao.Chain.InsertCrossTransfer(ctx, transfer)
+	// 保存锁定交易哈希
+	err = dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, g.Map{
+		"from_hash":  hash,
+		"updated_at": time.Now().Unix(),
+	})
 	if err != nil {
 		return "", 0, err
 	}
@@ -123,6 +149,18 @@ func (s BridgeLogic) CrossTransfer(ctx context.Context, fromChainId, toChainId u
 	return hash, nonce, nil
 }
 
+// CheckValidator 检查验证者签名配置; 解锁签名需对裸哈希签名, 远程签名器(Web3Signer/Clef)只支持加前缀的消息签名,
+// 配置了验证者时使用远程签名器会导致每个锁定事件处理失败, 启动时拒绝该配置
+func (s *BridgeLogic) CheckValidator(ctx context.Context) error {
+	if g.Cfg().MustGet(ctx, "bridge.validator").String() == "" {
+		return nil
+	}
+	if signerType := g.Cfg().MustGet(ctx, "signer.type", "local").String(); signerType != "local" {
+		return fmt.Errorf("bridge.validator requires the local signer, signer type %s cannot sign raw unlock hashes", signerType)
+	}
+	return nil
+}
+
 // GetCrossTransfers 获取跨链交易列表
 func (s *BridgeLogic) GetCrossTransfers(ctx context.Context, fromChainId, toChainId uint64, address string, status, page, pageSize int) ([]*model.CrossTransfer, int, error) {
 	return dao.Chain.GetCrossTransferList(ctx, fromChainId, toChainId, address, status, page, pageSize)
@@ -131,22 +169,36 @@ func (s *BridgeLogic) GetCrossTransfers(ctx context.Context, fromChainId, toChai
 // ProcessLockEvent 处理锁定事件
 func (s *BridgeLogic) ProcessLockEvent(ctx context.Context, chainId uint64, token, from string, amount string, toChainId uint64, toAddress string, nonce uint64, hash string) error {
 	// 查找跨链交易记录
-	transfers, _, err := dao.Chain.GetCrossTransferList(ctx, chainId, toChainId, "", 0, 1, 10)
+	transfer, err := dao.Chain.GetCrossTransferByNonce(ctx, chainId, toChainId, nonce)
 	if err != nil {
 		return err
 	}
-
-	var transfer *model.CrossTransfer
-	for _, t := range transfers {
-		if t.Nonce == nonce {
-			transfer = t
-			break
-		}
-	}
 	if transfer == nil {
 		return errors.New("transfer not found")
 	}
 
+	// 已保存解锁交易(重复处理)或处于回放模式(重新索引历史区块)时只恢复锁定信息, 不签名新的解锁交易;
+	// 非回放模式下重新广播已保存但未上链的解锁交易
+	if transfer.ToHash != "" || replaying(ctx) {
+		if transfer.ToHash == "" {
+			g.Log().Warningf(ctx, "cross transfer %d lock %s replayed without unlock, unlock is not sent in replay mode", transfer.Id, hash)
+		}
+		data := g.Map{
+			"from_hash":  hash,
+			"updated_at": time.Now().Unix(),
+		}
+		if transfer.Status == model.CrossTransferStatusPending {
+			data["status"] = model.CrossTransferStatusLocked
+		}
+		if err := dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, data); err != nil {
+			return err
+		}
+		if replaying(ctx) {
+			return nil
+		}
+		return s.rebroadcastUnlock(ctx, transfer)
+	}
+
 	// 更新状态为已锁定
 	err = dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, g.Map{
 		"status":     1,
@@ -163,22 +215,30 @@ func (s *BridgeLogic) ProcessLockEvent(ctx context.Context, chainId uint64, toke
 		return err
 	}
 
-	// 获取验证者私钥
-	privateKey, err := crypto.HexToECDSA("validator private key")
+	// 获取验证者地址
+	validator := common.HexToAddress(g.Cfg().MustGet(ctx, "bridge.validator").String())
+	if validator == (common.Address{}) {
+		return errors.New("bridge validator not configured")
+	}
+	txSigner, err := getSigner(ctx)
 	if err != nil {
 		return err
 	}
 
 	// 计算解锁签名
+	amountBig, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid lock amount %q", amount)
+	}
 	message := crypto.Keccak256(
 		common.HexToAddress(token).Bytes(),
-		new(big.Int).SetString(amount, 10).Bytes(),
+		amountBig.Bytes(),
 		common.HexToAddress(toAddress).Bytes(),
 		new(big.Int).SetUint64(chainId).Bytes(),
 		new(big.Int).SetUint64(nonce).Bytes(),
 	)
 
-	signature, err := crypto.Sign(message, privateKey)
+	signature, err := txSigner.SignHash(ctx, validator, message)
 	if err != nil {
 		return err
 	}
@@ -198,7 +258,7 @@ func (s *BridgeLogic) ProcessLockEvent(ctx context.Context, chainId uint64, toke
 	// 构造unlock方法调用数据
 	data, err := parsed.Pack("unlock",
 		common.HexToAddress(token),
-		new(big.Int).SetString(amount, 10),
+		amountBig,
 		common.HexToAddress(toAddress),
 		new(big.Int).SetUint64(chainId),
 		new(big.Int).SetUint64(nonce),
@@ -208,35 +268,98 @@ func (s *BridgeLogic) ProcessLockEvent(ctx context.Context, chainId uint64, toke
 		return err
 	}
 
-	// 发送解锁交易
-	validator := crypto.PubkeyToAddress(privateKey.PublicKey)
-	toHash, err := s.sendTransaction(ctx, client, validator.Hex(), toChain.BridgeAddress, big.NewInt(0), data)
+	// 签名、保存并发送解锁交易
+	return s.sendUnlock(ctx, client, transfer, validator.Hex(), toChain.BridgeAddress, data)
+}
+
+// sendUnlock 签名解锁交易, 广播前以尚未保存解锁交易为条件保存交易哈希与原始交易;
+// 同一锁定事件被重复处理(重组重放、死信重试)时只有一次能保存并广播, 广播失败时由再次处理重新广播同一笔交易
+func (s *BridgeLogic) sendUnlock(ctx context.Context, client *ethclient.Client, transfer *model.CrossTransfer, validator, bridgeAddress string, data []byte) error {
+	//1.估算费用与gas, 模拟执行
+	to := common.HexToAddress(bridgeAddress)
+	fees, err := estimateFees(ctx, client, nil)
+	if err != nil {
+		return err
+	}
+	gasLimit, err := estimateGas(ctx, client, validator, &to, big.NewInt(0), data)
 	if err != nil {
 		return err
 	}
+	if _, err := simulateTransaction(ctx, client, validator, &to, big.NewInt(0), gasLimit, data); err != nil {
+		return err
+	}
 
-	// 更新解锁交易哈希
-	return dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, g.Map{
-		"to_hash":    toHash,
-		"updated_at": time.Now().Unix(),
-	})
+	//2.分配nonce并签名
+	reservation, err := reserveNonce(ctx, client, validator)
+	if err != nil {
+		return err
+	}
+	signedTx, raw, err := func() (*types.Transaction, []byte, error) {
+		tx, err := newTransaction(ctx, client, reservation.Nonce, &to, big.NewInt(0), gasLimit, data, fees)
+		if err != nil {
+			return nil, nil, err
+		}
+		signedTx, err := signTransaction(ctx, client, validator, tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		raw, err := signedTx.MarshalBinary()
+		return signedTx, raw, err
+	}()
+	if err != nil {
+		releaseNonce(ctx, reservation)
+		return err
+	}
+
+	//3.广播前保存, 已由其他处理保存时放弃本次签名的交易
+	ok, err := dao.Chain.ClaimUnlock(ctx, transfer.Id, signedTx.Hash().Hex(), hexutil.Encode(raw))
+	if err != nil {
+		releaseNonce(ctx, reservation)
+		return err
+	}
+	if !ok {
+		releaseNonce(ctx, reservation)
+		g.Log().Warningf(ctx, "cross transfer %d unlock already sent by another process", transfer.Id)
+		return nil
+	}
+	markNonceSent(ctx, reservation, signedTx.Hash().Hex())
+
+	//4.广播
+	return client.SendTransaction(ctx, signedTx)
+}
+
+// rebroadcastUnlock 重新广播已保存的解锁交易, 节点已知该交易(交易池中或已上链)时忽略
+func (s *BridgeLogic) rebroadcastUnlock(ctx context.Context, transfer *model.CrossTransfer) error {
+	if transfer.UnlockTx == "" || transfer.Status != model.CrossTransferStatusLocked {
+		return nil
+	}
+	client, err := ethclientx.GetClientByChainId(ctx, transfer.ToChainId)
+	if err != nil {
+		return err
+	}
+	_, _, err = client.TransactionByHash(ctx, common.HexToHash(transfer.ToHash))
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(common.FromHex(transfer.UnlockTx)); err != nil {
+		return fmt.Errorf("decode unlock tx of cross transfer %d: %w", transfer.Id, err)
+	}
+	g.Log().Infof(ctx, "cross transfer %d rebroadcast unlock %s", transfer.Id, transfer.ToHash)
+	return client.SendTransaction(ctx, tx)
 }
 
 // ProcessUnlockEvent 处理解锁事件
 func (s *BridgeLogic) ProcessUnlockEvent(ctx context.Context, chainId uint64, token, to string, amount string, fromChainId uint64, nonce uint64, hash string) error {
 	// 查找跨链交易记录
-	transfers, _, err := dao.Chain.GetCrossTransferList(ctx, fromChainId, chainId, "", 1, 1, 10)
+	transfer, err := dao.Chain.GetCrossTransferByNonce(ctx, fromChainId, chainId, nonce)
 	if err != nil {
 		return err
 	}
-
-	var transfer *model.CrossTransfer
-	for _, t := range transfers {
-		if t.Nonce == nonce {
-			transfer = t
-			break
-		}
-	}
 	if transfer == nil {
 		return errors.New("transfer not found")
 	}
@@ -249,69 +372,141 @@ func (s *BridgeLogic) ProcessUnlockEvent(ctx context.Context, chainId uint64, to
 	})
 }
 
-// 发送交易
-func (s *BridgeLogic) sendTransaction(ctx context.Context, client *ethclient.Client, from, to string, value *big.Int, data []byte) (string, error) {
-	nonce, err := client.PendingNonceAt(ctx, common.HexToAddress(from))
+// eventSources 每条配置了跨链桥合约的链一个事件来源, 处理Lock与Unlock事件
+func (s *BridgeLogic) eventSources(ctx context.Context) ([]*EventSource, error) {
+	chains, err := dao.Chain.GetActiveList(ctx)
 	if err != nil {
-		return "", err
-	}
-
-	gasPrice, err := client.SuggestGasPrice(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
-		From:  common.HexToAddress(from),
-		To:    &common.HexToAddress(to),
-		Value: value,
-		Data:  data,
-	})
-	if err != nil {
-		return "", err
-	}
-
-	tx := types.NewTransaction(nonce, common.HexToAddress(to), value, gasLimit, gasPrice, data)
-
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		return "", err
+		return nil, err
 	}
+	var sources []*EventSource
+	for _, chain := range chains {
+		if !common.IsHexAddress(chain.BridgeAddress) {
+			continue
+		}
+		chainId := chain.ChainId
+		address := common.HexToAddress(chain.BridgeAddress)
+		contract := bind.NewBoundContract(address, bridgeABI, nil, nil, nil)
+		source := NewEventSource(model.EventGroupBridge, chainId, address, uint64(chain.BridgeHeight)).
+			Handle(bridgeABI.Events["Lock"].ID, func(ctx context.Context, log types.Log) error {
+				return s.handleLock(ctx, chainId, contract, log)
+			}).
+			Handle(bridgeABI.Events["Unlock"].ID, func(ctx context.Context, log types.Log) error {
+				return s.handleUnlock(ctx, chainId, contract, log)
+			}).
+			OnRollback(func(ctx context.Context, fork uint64, logs []types.Log) error {
+				return s.rollbackEvents(ctx, chainId, contract, logs)
+			})
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
 
-	wallet, err := dao.Wallet.GetByAddress(ctx, from)
-	if err != nil {
-		return "", err
-	}
+// bridgeLockEvent 跨链桥Lock事件
+type bridgeLockEvent struct {
+	Token     common.Address
+	From      common.Address
+	Amount    *big.Int
+	ToChainId *big.Int
+	ToAddress common.Address
+	Nonce     *big.Int
+}
 
-	privateKey, err := crypto.HexToECDSA(wallet.PrivateKey)
-	if err != nil {
-		return "", err
-	}
+// bridgeUnlockEvent 跨链桥Unlock事件
+type bridgeUnlockEvent struct {
+	Token       common.Address
+	To          common.Address
+	Amount      *big.Int
+	FromChainId *big.Int
+	Nonce       *big.Int
+}
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		return "", err
+// handleLock 解析Lock事件并发起目标链解锁
+func (s *BridgeLogic) handleLock(ctx context.Context, chainId uint64, contract *bind.BoundContract, log types.Log) error {
+	var event bridgeLockEvent
+	if err := contract.UnpackLog(&event, "Lock", log); err != nil {
+		return err
 	}
+	return s.ProcessLockEvent(ctx,
+		chainId,
+		event.Token.Hex(),
+		event.From.Hex(),
+		event.Amount.String(),
+		event.ToChainId.Uint64(),
+		event.ToAddress.Hex(),
+		event.Nonce.Uint64(),
+		log.TxHash.Hex(),
+	)
+}
 
-	err = client.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return "", err
+// handleUnlock 解析Unlock事件并完成跨链交易
+func (s *BridgeLogic) handleUnlock(ctx context.Context, chainId uint64, contract *bind.BoundContract, log types.Log) error {
+	var event bridgeUnlockEvent
+	if err := contract.UnpackLog(&event, "Unlock", log); err != nil {
+		return err
 	}
-
-	return signedTx.Hash().Hex(), nil
+	return s.ProcessUnlockEvent(ctx,
+		chainId,
+		event.Token.Hex(),
+		event.To.Hex(),
+		event.Amount.String(),
+		event.FromChainId.Uint64(),
+		event.Nonce.Uint64(),
+		log.TxHash.Hex(),
+	)
 }
 
-// 等待交易确认
-func (s *BridgeLogic) waitTransaction(ctx context.Context, client *ethclient.Client, hash string) (*types.Receipt, error) {
-	for {
-		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(hash))
-		if err != nil {
-			if err == ethereum.NotFound {
-				time.Sleep(time.Second)
+// rollbackEvents 撤销被重组移除的Lock/Unlock事件对跨链交易状态的更新, 已发出的解锁交易无法撤回, 保留其哈希避免重放时重复解锁
+func (s *BridgeLogic) rollbackEvents(ctx context.Context, chainId uint64, contract *bind.BoundContract, logs []types.Log) error {
+	for _, log := range logs {
+		switch log.Topics[0] {
+		case bridgeABI.Events["Lock"].ID:
+			var event bridgeLockEvent
+			if err := contract.UnpackLog(&event, "Lock", log); err != nil {
+				return err
+			}
+			transfer, err := dao.Chain.GetCrossTransferByNonce(ctx, chainId, event.ToChainId.Uint64(), event.Nonce.Uint64())
+			if err != nil {
+				return err
+			}
+			if transfer == nil || !strings.EqualFold(transfer.FromHash, log.TxHash.Hex()) {
+				continue
+			}
+			if transfer.Status != model.CrossTransferStatusLocked {
+				g.Log().Warningf(ctx, "cross transfer %d lock %s reorged out after status %d", transfer.Id, log.TxHash.Hex(), transfer.Status)
 				continue
 			}
-			return nil, err
+			if transfer.ToHash != "" {
+				g.Log().Warningf(ctx, "cross transfer %d lock %s reorged out after unlock %s was sent", transfer.Id, log.TxHash.Hex(), transfer.ToHash)
+			}
+			err = dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, g.Map{
+				"status":     model.CrossTransferStatusPending,
+				"from_hash":  "",
+				"updated_at": time.Now().Unix(),
+			})
+			if err != nil {
+				return err
+			}
+
+		case bridgeABI.Events["Unlock"].ID:
+			var event bridgeUnlockEvent
+			if err := contract.UnpackLog(&event, "Unlock", log); err != nil {
+				return err
+			}
+			transfer, err := dao.Chain.GetCrossTransferByNonce(ctx, event.FromChainId.Uint64(), chainId, event.Nonce.Uint64())
+			if err != nil {
+				return err
+			}
+			if transfer == nil || transfer.Status != model.CrossTransferStatusCompleted || !strings.EqualFold(transfer.ToHash, log.TxHash.Hex()) {
+				continue
+			}
+			err = dao.Chain.UpdateCrossTransfer(ctx, transfer.Id, g.Map{
+				"status":     model.CrossTransferStatusLocked,
+				"updated_at": time.Now().Unix(),
+			})
+			if err != nil {
+				return err
+			}
 		}
-		return receipt, nil
 	}
+	return nil
 }
@@ -0,0 +1,103 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+)
+
+const defaultChainRegistry = "manifest/config/chains.yaml"
+
+type ChainLogic struct{}
+
+// SyncRegistry 将链注册表文件同步到chain表, 文件中未列出的链保持不变
+func (s *ChainLogic) SyncRegistry(ctx context.Context) error {
+	//1.读取注册表文件
+	path := g.Cfg().MustGet(ctx, "chains.registry", defaultChainRegistry).String()
+	if !gfile.Exists(path) {
+		g.Log().Warningf(ctx, "chain registry %s not found, skip sync", path)
+		return nil
+	}
+	configs, err := s.parseRegistry(gfile.GetContents(path))
+	if err != nil {
+		return fmt.Errorf("chain registry %s: %w", path, err)
+	}
+
+	//2.按链ID写入chain表
+	now := time.Now().Unix()
+	for _, config := range configs {
+		rpcUrls, err := json.Marshal(config.RpcUrls)
+		if err != nil {
+			return err
+		}
+		status := 1
+		if config.Enabled != nil && !*config.Enabled {
+			status = 0
+		}
+		chain := &model.Chain{
+			Name:          config.Name,
+			ChainId:       config.ChainId,
+			Symbol:        config.Symbol,
+			Decimals:      config.Decimals,
+			ExplorerUrl:   config.ExplorerUrl,
+			RpcUrls:       string(rpcUrls),
+			BridgeAddress: config.BridgeAddress,
+			BridgeHeight:  config.BridgeHeight,
+			LegacyTx:      config.LegacyTx,
+			Confirmations: config.Confirmations,
+			Status:        status,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := dao.Chain.Save(ctx, chain); err != nil {
+			return fmt.Errorf("sync chain %d: %w", config.ChainId, err)
+		}
+	}
+	g.Log().Infof(ctx, "synced %d chains from registry %s", len(configs), path)
+	return nil
+}
+
+// GetActiveList 获取所有启用的链
+func (s *ChainLogic) GetActiveList(ctx context.Context) ([]*model.Chain, error) {
+	return dao.Chain.GetActiveList(ctx)
+}
+
+// parseRegistry 解析并校验注册表内容
+func (s *ChainLogic) parseRegistry(content string) ([]*model.ChainConfig, error) {
+	j, err := gjson.LoadContentType(gjson.ContentTypeYaml, content)
+	if err != nil {
+		return nil, err
+	}
+	var configs []*model.ChainConfig
+	if err := j.Get("chains").Scan(&configs); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint64]bool)
+	for i, config := range configs {
+		if config == nil || config.ChainId == 0 {
+			return nil, fmt.Errorf("chain #%d: chainId is required", i+1)
+		}
+		if seen[config.ChainId] {
+			return nil, fmt.Errorf("chain %d is duplicated", config.ChainId)
+		}
+		seen[config.ChainId] = true
+		if config.Name == "" {
+			return nil, fmt.Errorf("chain %d: name is required", config.ChainId)
+		}
+		if len(config.RpcUrls) == 0 {
+			return nil, fmt.Errorf("chain %d: rpcUrls is required", config.ChainId)
+		}
+		if config.Decimals == 0 {
+			config.Decimals = 18
+		}
+	}
+	return configs, nil
+}
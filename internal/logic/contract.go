@@ -3,15 +3,16 @@ package logic
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gogf/gf/v2/frame/g"
 	"go-wallet-defi/internal/dao"
 	"go-wallet-defi/internal/model"
-	"go-wallet-defi/internal/pkg/ethclientx"
 	"math/big"
 	"strings"
 	"time"
@@ -20,7 +21,7 @@ import (
 type ContractLogic struct{}
 
 // Deploy 部署合约
-func (c ContractLogic) Deploy(ctx context.Context, name, abiStr, bytecode, from, argsJson, network string) (hash, address string, err error) {
+func (c ContractLogic) Deploy(ctx context.Context, chainId uint64, name, abiStr, bytecode, from, argsJson string) (hash, address string, err error) {
 	//1.解析ABI
 	parsed, err := abi.JSON(strings.NewReader(abiStr))
 	if err != nil {
@@ -42,66 +43,38 @@ func (c ContractLogic) Deploy(ctx context.Context, name, abiStr, bytecode, from,
 		}
 		data = append(data, input...)
 	}
-	//4.获取nonce
-	client := ethclientx.GetClient(ctx)
-	nonce, err := client.PendingNonceAt(ctx, common.HexToAddress(from))
+	//4.获取链客户端
+	client, chainId, err := chainClient(ctx, chainId)
 	if err != nil {
 		return "", "", err
 	}
-	//5.获取gasPrice
-	gasPrice, err := client.SuggestGasPrice(ctx)
+	//5.估算交易费用
+	fees, err := estimateFees(ctx, client, nil)
 	if err != nil {
 		return "", "", err
 	}
 	//6.估算gas
-	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
-		From: common.HexToAddress(from),
-		Data: data,
-	})
-	if err != nil {
-		return "", "", err
-	}
-	//7.构建交易
-	tx := types.NewContractCreation(nonce, big.NewInt(0), gasLimit, gasPrice, data)
-
-	//8.签名交易
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		return "", "", err
-	}
-
-	wallet, err := dao.Wallet.GetByAddress(ctx, from)
+	gasLimit, err := estimateGas(ctx, client, from, nil, nil, data)
 	if err != nil {
 		return "", "", err
 	}
-
-	privateKey, err := crypto.HexToECDSA(wallet.PrivateKey)
-	if err != nil {
-		return "", "", err
-	}
-
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		return "", "", err
-	}
-
-	//9.发送交易
-	err = client.SendTransaction(ctx, signedTx)
+	//7.分配nonce, 签名并发送交易
+	signedTx, err := broadcastTransaction(ctx, client, from, nil, big.NewInt(0), gasLimit, data, fees)
 	if err != nil {
 		return "", "", err
 	}
-	//10.等待交易确认
+	//8.等待交易确认
 	receipt, err := bind.WaitMined(ctx, client, signedTx)
 	if err != nil {
 		return "", "", err
 	}
-	//11.保存合约信息
+	//9.保存合约信息
 	contract := &model.Contract{
 		Name:         name,
 		Address:      receipt.ContractAddress.Hex(),
 		ABI:          abiStr,
 		Bytecode:     bytecode,
-		Network:      network,
+		ChainId:      chainId,
 		DeployHash:   signedTx.Hash().Hex(),
 		DeployHeight: receipt.BlockNumber.Int64(),
 		Creator:      from,
@@ -118,12 +91,21 @@ func (c ContractLogic) Deploy(ctx context.Context, name, abiStr, bytecode, from,
 }
 
 // Call 调用合约
-func (s *ContractLogic) Call(ctx context.Context, address, method, argsJson, from, value string) (interface{}, error) {
+func (s *ContractLogic) Call(ctx context.Context, chainId uint64, address, method, argsJson, from, value string) (interface{}, error) {
+	// 获取链客户端
+	client, chainId, err := chainClient(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+
 	// 获取合约信息
-	contract, err := dao.Contract.GetByAddress(ctx, address, "ethereum")
+	contract, err := dao.Contract.GetByAddress(ctx, chainId, address)
 	if err != nil {
 		return nil, err
 	}
+	if contract == nil {
+		return nil, errors.New("contract not found")
+	}
 
 	// 解析ABI
 	parsed, err := abi.JSON(strings.NewReader(contract.ABI))
@@ -145,8 +127,6 @@ func (s *ContractLogic) Call(ctx context.Context, address, method, argsJson, fro
 		return nil, err
 	}
 
-	client := ethclientx.GetClient(ctx)
-
 	// 判断是否是只读方法
 	if parsed.Methods[method].IsConstant() {
 		// 只读方法直接调用
@@ -168,49 +148,26 @@ func (s *ContractLogic) Call(ctx context.Context, address, method, argsJson, fro
 	}
 
 	// 写入方法需要发送交易
-	nonce, err := client.PendingNonceAt(ctx, common.HexToAddress(from))
-	if err != nil {
-		return nil, err
-	}
-
-	gasPrice, err := client.SuggestGasPrice(ctx)
+	fees, err := estimateFees(ctx, client, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	val := big.NewInt(0)
 	if value != "" {
-		val.SetString(value, 10)
-	}
-
-	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
-		From:  common.HexToAddress(from),
-		To:    &common.HexToAddress(address),
-		Value: val,
-		Data:  data,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	tx := types.NewTransaction(nonce, common.HexToAddress(address), val, gasLimit, gasPrice, data)
-
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	wallet, err := dao.Wallet.GetByAddress(ctx, from)
-	if err != nil {
-		return nil, err
+		var ok bool
+		if val, ok = new(big.Int).SetString(value, 10); !ok {
+			return nil, fmt.Errorf("invalid value %q", value)
+		}
 	}
 
-	privateKey, err := crypto.HexToECDSA(wallet.PrivateKey)
+	contractAddress := common.HexToAddress(address)
+	gasLimit, err := estimateGas(ctx, client, from, &contractAddress, val, data)
 	if err != nil {
 		return nil, err
 	}
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := broadcastTransaction(ctx, client, from, &contractAddress, val, gasLimit, data, fees)
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +175,7 @@ func (s *ContractLogic) Call(ctx context.Context, address, method, argsJson, fro
 	// 保存调用记录
 	call := &model.ContractCall{
 		ContractId: contract.Id,
+		ChainId:    chainId,
 		Method:     method,
 		Params:     argsJson,
 		From:       from,
@@ -232,22 +190,101 @@ func (s *ContractLogic) Call(ctx context.Context, address, method, argsJson, fro
 		return nil, err
 	}
 
-	// 发送交易
-	err = client.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return nil, err
-	}
-
 	return signedTx.Hash().Hex(), nil
 }
 
 // GetEvents 获取合约事件
-func (s *ContractLogic) GetEvents(ctx context.Context, address, eventName string, fromBlock, toBlock int64, page, pageSize int) ([]*model.ContractEvent, int, error) {
+func (s *ContractLogic) GetEvents(ctx context.Context, chainId uint64, address, eventName string, fromBlock, toBlock int64, page, pageSize int) ([]*model.ContractEvent, int, error) {
 	// 获取合约信息
-	contract, err := dao.Contract.GetByAddress(ctx, address, "ethereum")
+	contract, err := dao.Contract.GetByAddress(ctx, chainId, address)
 	if err != nil {
 		return nil, 0, err
 	}
+	if contract == nil {
+		return nil, 0, errors.New("contract not found")
+	}
 
 	return dao.Contract.GetEvents(ctx, contract.Id, eventName, fromBlock, toBlock, page, pageSize)
 }
+
+// eventSources 每个合约一个事件来源, 处理ABI中声明的全部非匿名事件
+func (s *ContractLogic) eventSources(ctx context.Context) ([]*EventSource, error) {
+	contracts, err := dao.Contract.GetActiveList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*EventSource
+	for _, contract := range contracts {
+		if contract.ChainId == 0 {
+			g.Log().Warningf(ctx, "contract %d has no chain id, events not indexed, see manifest/sql/002_contract_chain_id.sql", contract.Id)
+			continue
+		}
+		parsed, err := abi.JSON(strings.NewReader(contract.ABI))
+		if err != nil {
+			g.Log().Warningf(ctx, "contract %d abi invalid, events not indexed: %v", contract.Id, err)
+			continue
+		}
+		if len(parsed.Events) == 0 {
+			continue
+		}
+		contract := contract
+		source := NewEventSource(fmt.Sprintf("%s:%d", model.EventGroupContract, contract.Id), contract.ChainId,
+			common.HexToAddress(contract.Address), uint64(contract.DeployHeight))
+		for _, event := range parsed.Events {
+			if event.Anonymous {
+				continue
+			}
+			event := event
+			source.Handle(event.ID, func(ctx context.Context, log types.Log) error {
+				return s.saveEvent(ctx, contract, event, log)
+			})
+		}
+		source.OnRollback(func(ctx context.Context, fork uint64, logs []types.Log) error {
+			return dao.Contract.DeleteEventsAfter(ctx, contract.Id, int64(fork))
+		})
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// saveEvent 解析并保存合约事件, 重复处理同一日志时忽略
+func (s *ContractLogic) saveEvent(ctx context.Context, contract *model.Contract, event abi.Event, log types.Log) error {
+	//1.解析索引参数与数据参数, 按ABI参数顺序保存
+	values := make(map[string]interface{})
+	if err := event.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+		return err
+	}
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return err
+	}
+	data := make([]interface{}, 0, len(event.Inputs))
+	for _, input := range event.Inputs {
+		data = append(data, values[input.Name])
+	}
+	dataJson, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	//2.保存事件
+	return dao.Contract.InsertEvent(ctx, &model.ContractEvent{
+		ContractId:  contract.Id,
+		ChainId:     contract.ChainId,
+		Name:        event.Name,
+		Signature:   event.Sig,
+		Topics:      common.Bytes2Hex(log.Topics[0].Bytes()),
+		Data:        string(dataJson),
+		BlockNumber: int64(log.BlockNumber),
+		BlockHash:   log.BlockHash.Hex(),
+		TxHash:      log.TxHash.Hex(),
+		TxIndex:     int(log.TxIndex),
+		LogIndex:    int(log.Index),
+		CreatedAt:   time.Now().Unix(),
+	})
+}
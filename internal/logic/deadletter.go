@@ -0,0 +1,244 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/text/gstr"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+)
+
+const (
+	defaultDeadLetterRetryInterval    = 30   // 首次重试间隔(秒), 之后每次失败翻倍
+	defaultDeadLetterMaxRetryInterval = 3600 // 重试间隔上限(秒)
+	defaultDeadLetterMaxAttempts      = 10   // 最大执行次数, 达到后等待人工处理
+	defaultDeadLetterBatchSize        = 100  // 每轮重试的记录数
+	defaultDeadLetterStaleTimeout     = 300  // 重试中超过该秒数未更新视为执行实例中断
+	deadLetterErrorLength             = 1000 // 保存的错误信息最大长度
+)
+
+// errDeadLetterClaimed 记录已被其他重试认领或状态已变化
+var errDeadLetterClaimed = errors.New("dead letter is being retried or is not pending or exhausted")
+
+type DeadLetterLogic struct{}
+
+// Get 获取失败的事件日志
+func (s *DeadLetterLogic) Get(ctx context.Context, id uint64) (*model.EventDeadLetter, error) {
+	letter, err := dao.DeadLetter.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if letter == nil {
+		return nil, errors.New("dead letter not found")
+	}
+	return letter, nil
+}
+
+// GetList 获取失败的事件日志列表, status小于0表示全部状态
+func (s *DeadLetterLogic) GetList(ctx context.Context, chainId uint64, status int, page, pageSize int) ([]*model.EventDeadLetter, int, error) {
+	return dao.DeadLetter.GetList(ctx, chainId, status, page, pageSize)
+}
+
+// Retry 立即重试一条待重试或达到最大重试次数的记录, 返回重试后的记录
+func (s *DeadLetterLogic) Retry(ctx context.Context, id uint64) (*model.EventDeadLetter, error) {
+	letter, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if letter.Status == model.EventDeadLetterStatusResolved {
+		return nil, errors.New("dead letter already resolved")
+	}
+	if letter.Status == model.EventDeadLetterStatusDiscarded {
+		return nil, errors.New("dead letter already discarded")
+	}
+	if err := s.retry(ctx, letter); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// Discard 丢弃待重试或达到最大重试次数的记录, 不再重试
+func (s *DeadLetterLogic) Discard(ctx context.Context, id uint64) error {
+	ok, err := dao.DeadLetter.Discard(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("dead letter is not pending or exhausted")
+	}
+	return nil
+}
+
+// Poll 重试到期的记录, 单条记录失败不影响同批其他记录
+func (s *DeadLetterLogic) Poll(ctx context.Context) error {
+	limit := g.Cfg().MustGet(ctx, "deadletter.batchSize", defaultDeadLetterBatchSize).Int()
+	letters, err := dao.DeadLetter.GetDue(ctx, time.Now().Unix(), s.staleBefore(ctx), limit)
+	if err != nil {
+		return err
+	}
+	for _, letter := range letters {
+		err := s.retry(ctx, letter)
+		if errors.Is(err, errDeadLetterClaimed) {
+			continue
+		}
+		if err != nil {
+			g.Log().Warningf(ctx, "retry dead letter %d failed: %v", letter.Id, err)
+		}
+	}
+	return nil
+}
+
+// record 保存处理失败的日志; 同一日志已有记录时累计执行次数并推迟重试,
+// 已丢弃的记录保持丢弃, 重试中的记录由认领的实例保存结果
+func (s *DeadLetterLogic) record(ctx context.Context, source *EventSource, log types.Log, cause error) error {
+	//1.首次失败, 保存日志内容
+	letter, err := dao.DeadLetter.GetByLog(ctx, source.ChainId, source.Name, log.TxHash.Hex(), log.Index)
+	if err != nil {
+		return err
+	}
+	if letter == nil {
+		now := time.Now().Unix()
+		status, nextRetryAt := s.schedule(ctx, 1)
+		return dao.DeadLetter.Insert(ctx, &model.EventDeadLetter{
+			ChainId:     source.ChainId,
+			EventGroup:  source.group,
+			Source:      source.Name,
+			Address:     log.Address.Hex(),
+			Topics:      encodeTopics(log.Topics),
+			Data:        hexutil.Encode(log.Data),
+			BlockNumber: log.BlockNumber,
+			BlockHash:   log.BlockHash.Hex(),
+			TxHash:      log.TxHash.Hex(),
+			TxIndex:     log.TxIndex,
+			LogIndex:    log.Index,
+			Replay:      replaying(ctx),
+			Attempts:    1,
+			Error:       gstr.StrLimitRune(cause.Error(), deadLetterErrorLength),
+			Status:      status,
+			NextRetryAt: nextRetryAt,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+	if letter.Status == model.EventDeadLetterStatusDiscarded || letter.Status == model.EventDeadLetterStatusRunning {
+		return nil
+	}
+
+	//2.再次失败(重组回放或重新索引), 任一次在正常模式下失败时重试不再限于回放模式;
+	// 期间记录被重试认领或已更新时以其结果为准
+	attempts := letter.Attempts + 1
+	_, err = dao.DeadLetter.UpdateIdle(ctx, letter.Id, letter.Attempts, s.failure(ctx, letter, attempts, letter.Replay && replaying(ctx), cause))
+	return err
+}
+
+// retry 认领记录后还原日志并调用来源当前的处理函数, 成功时标记为已解决, 失败时按执行次数推迟重试;
+// 记录已被其他重试认领或状态已变化时返回errDeadLetterClaimed, 不调用处理函数
+func (s *DeadLetterLogic) retry(ctx context.Context, letter *model.EventDeadLetter) error {
+	//1.认领记录, 同时累计执行次数
+	claimed, err := dao.DeadLetter.Claim(ctx, letter.Id, letter.Attempts, s.staleBefore(ctx))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return errDeadLetterClaimed
+	}
+	attempts := letter.Attempts + 1
+
+	//2.调用处理函数并保存结果
+	data := g.Map{
+		"status":        model.EventDeadLetterStatusResolved,
+		"next_retry_at": 0,
+	}
+	if cause := s.invoke(ctx, letter); cause != nil {
+		g.Log().Warningf(ctx, "%s retry log %s:%d failed: %v", letter.Source, letter.TxHash, letter.LogIndex, cause)
+		data = s.failure(ctx, letter, attempts, letter.Replay, cause)
+	} else {
+		g.Log().Infof(ctx, "%s retry log %s:%d succeeded after %d attempts", letter.Source, letter.TxHash, letter.LogIndex, attempts)
+	}
+	ok, err := dao.DeadLetter.UpdateClaimed(ctx, letter.Id, attempts, data)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		g.Log().Warningf(ctx, "dead letter %d was reclaimed before retry result was saved", letter.Id)
+	}
+	return nil
+}
+
+// invoke 按记录还原日志, 调用来源当前注册的处理函数; 来源或处理函数已不存在时返回错误
+func (s *DeadLetterLogic) invoke(ctx context.Context, letter *model.EventDeadLetter) error {
+	sources, err := (&EventIndexerLogic{}).loadSources(ctx, letter.EventGroup)
+	if err != nil {
+		return err
+	}
+	log := types.Log{
+		Address:     common.HexToAddress(letter.Address),
+		Topics:      decodeTopics(letter.Topics),
+		Data:        common.FromHex(letter.Data),
+		BlockNumber: letter.BlockNumber,
+		BlockHash:   common.HexToHash(letter.BlockHash),
+		TxHash:      common.HexToHash(letter.TxHash),
+		TxIndex:     letter.TxIndex,
+		Index:       letter.LogIndex,
+	}
+	if len(log.Topics) == 0 {
+		return errors.New("log has no topics")
+	}
+	for _, source := range sources {
+		if source.ChainId != letter.ChainId || source.Name != letter.Source || source.Address != log.Address {
+			continue
+		}
+		handler, ok := source.handlers[log.Topics[0]]
+		if !ok {
+			break
+		}
+		if letter.Replay {
+			ctx = withReplay(ctx)
+		}
+		return handler(ctx, log)
+	}
+	return errors.New("event source or handler is no longer registered")
+}
+
+// failure 第attempts次执行失败后要保存的字段, 包括错误信息与下次重试时间
+func (s *DeadLetterLogic) failure(ctx context.Context, letter *model.EventDeadLetter, attempts int, replay bool, cause error) g.Map {
+	status, nextRetryAt := s.schedule(ctx, attempts)
+	if status == model.EventDeadLetterStatusExhausted {
+		g.Log().Errorf(ctx, "%s log %s:%d failed %d times, waiting for manual handling", letter.Source, letter.TxHash, letter.LogIndex, attempts)
+	}
+	return g.Map{
+		"attempts":      attempts,
+		"error":         gstr.StrLimitRune(cause.Error(), deadLetterErrorLength),
+		"replay":        replay,
+		"status":        status,
+		"next_retry_at": nextRetryAt,
+	}
+}
+
+// staleBefore 重试中的记录在该时间之后未更新视为执行实例中断
+func (s *DeadLetterLogic) staleBefore(ctx context.Context) int64 {
+	timeout := g.Cfg().MustGet(ctx, "deadletter.staleTimeout", defaultDeadLetterStaleTimeout).Int64()
+	return time.Now().Unix() - timeout
+}
+
+// schedule 按指数退避计算第attempts次执行失败后的状态与下次重试时间:
+// 间隔为 retryInterval * 2^(attempts-1), 不超过maxRetryInterval; 达到最大执行次数时不再自动重试
+func (s *DeadLetterLogic) schedule(ctx context.Context, attempts int) (int, int64) {
+	maxAttempts := g.Cfg().MustGet(ctx, "deadletter.maxAttempts", defaultDeadLetterMaxAttempts).Int()
+	if attempts >= maxAttempts {
+		return model.EventDeadLetterStatusExhausted, 0
+	}
+	interval := g.Cfg().MustGet(ctx, "deadletter.retryInterval", defaultDeadLetterRetryInterval).Int64()
+	maxInterval := g.Cfg().MustGet(ctx, "deadletter.maxRetryInterval", defaultDeadLetterMaxRetryInterval).Int64()
+	delay := maxInterval
+	if shift := attempts - 1; shift < 62 && interval<<shift > 0 && interval<<shift < maxInterval {
+		delay = interval << shift
+	}
+	return model.EventDeadLetterStatusPending, time.Now().Unix() + delay
+}
@@ -72,20 +72,14 @@ func (s *DefiLogic) Swap(ctx context.Context, chainId uint64, fromToken, toToken
 			return "", "", err
 		}
 
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, fromToken, big.NewInt(0), approveData)
-		if err != nil {
-			return "", "", err
-		}
-
-		// 等待approve交易确认
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		err = sendApproval(ctx, client, fromAddress, fromToken, approveData)
 		if err != nil {
 			return "", "", err
 		}
 	}
 
 	// 发送交易
-	hash, err = s.sendTransaction(ctx, client, fromAddress, "router address", big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, "router address", big.NewInt(0), data)
 	if err != nil {
 		return "", "", err
 	}
@@ -169,12 +163,7 @@ func (s *DefiLogic) AddLiquidity(ctx context.Context, chainId uint64, tokenA, to
 			return "", "", err
 		}
 
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, tokenA, big.NewInt(0), approveData)
-		if err != nil {
-			return "", "", err
-		}
-
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		err = sendApproval(ctx, client, fromAddress, tokenA, approveData)
 		if err != nil {
 			return "", "", err
 		}
@@ -192,19 +181,14 @@ func (s *DefiLogic) AddLiquidity(ctx context.Context, chainId uint64, tokenA, to
 			return "", "", err
 		}
 
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, tokenB, big.NewInt(0), approveData)
-		if err != nil {
-			return "", "", err
-		}
-
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		err = sendApproval(ctx, client, fromAddress, tokenB, approveData)
 		if err != nil {
 			return "", "", err
 		}
 	}
 
 	// 发送交易
-	hash, err = s.sendTransaction(ctx, client, fromAddress, "router address", big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, "router address", big.NewInt(0), data)
 	if err != nil {
 		return "", "", err
 	}
@@ -313,18 +297,13 @@ func (s *DefiLogic) RemoveLiquidity(ctx context.Context, chainId uint64, pair st
 		return "", "", "", err
 	}
 
-	approveHash, err := s.sendTransaction(ctx, client, fromAddress, pair, big.NewInt(0), approveData)
-	if err != nil {
-		return "", "", "", err
-	}
-
-	_, err = s.waitTransaction(ctx, client, approveHash)
+	err = sendApproval(ctx, client, fromAddress, pair, approveData)
 	if err != nil {
 		return "", "", "", err
 	}
 
 	// 发送交易
-	hash, err = s.sendTransaction(ctx, client, fromAddress, "router address", big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, "router address", big.NewInt(0), data)
 	if err != nil {
 		return "", "", "", err
 	}
@@ -393,19 +372,14 @@ func (s *DefiLogic) Supply(ctx context.Context, chainId uint64, pool, token stri
 			return "", err
 		}
 
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, token, big.NewInt(0), approveData)
-		if err != nil {
-			return "", err
-		}
-
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		err = sendApproval(ctx, client, fromAddress, token, approveData)
 		if err != nil {
 			return "", err
 		}
 	}
 
 	// 发送交易
-	hash, err = s.sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
 	if err != nil {
 		return "", err
 	}
@@ -460,7 +434,7 @@ func (s *DefiLogic) Withdraw(ctx context.Context, chainId uint64, pool, token st
 	}
 
 	// 发送交易
-	hash, err = s.sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
 	if err != nil {
 		return "", err
 	}
@@ -489,7 +463,7 @@ func (s *DefiLogic) Withdraw(ctx context.Context, chainId uint64, pool, token st
 
 // Borrow 从Aave借款
 func (s *DefiLogic) Borrow(ctx context.Context, chainId uint64, pool, token string, amount string, rateMode int, fromAddress string) (hash string, err error) {
-	client, err := ethclient.GetClient(chainId)
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
 	if err != nil {
 		return "", err
 	}
@@ -512,7 +486,7 @@ func (s *DefiLogic) Borrow(ctx context.Context, chainId uint64, pool, token stri
 		return "", err
 	}
 
-	hash, err = s.sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
 	if err != nil {
 		return "", err
 	}
@@ -541,7 +515,7 @@ func (s *DefiLogic) Borrow(ctx context.Context, chainId uint64, pool, token stri
 
 // Repay 向Aave还款
 func (s *DefiLogic) Repay(ctx context.Context, chainId uint64, pool, token string, amount string, rateMode int, fromAddress string) (hash string, err error) {
-	client, err := ethclient.GetClient(chainId)
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
 	if err != nil {
 		return "", err
 	}
@@ -575,18 +549,13 @@ func (s *DefiLogic) Repay(ctx context.Context, chainId uint64, pool, token strin
 			return "", err
 		}
 
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, token, big.NewInt(0), approveData)
-		if err != nil {
-			return "", err
-		}
-
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		err = sendApproval(ctx, client, fromAddress, token, approveData)
 		if err != nil {
 			return "", err
 		}
 	}
 
-	hash, err = s.sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
 	if err != nil {
 		return "", err
 	}
@@ -633,7 +602,10 @@ func (s *DefiLogic) Stake(ctx context.Context, chainId uint64, pool string, amou
 	}
 
 	// approve stake token
-	stakeToken := "" // 从合约获取质押代币地址
+	stakeToken, err := s.StakeToken(ctx, chainId, pool)
+	if err != nil {
+		return "", err
+	}
 	if stakeToken != "0x0000000000000000000000000000000000000000" {
 		erc20, err := token.NewERC20(common.HexToAddress(stakeToken), client)
 		if err != nil {
@@ -645,23 +617,18 @@ func (s *DefiLogic) Stake(ctx context.Context, chainId uint64, pool string, amou
 			return "", err
 		}
 
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, stakeToken, big.NewInt(0), approveData)
-		if err != nil {
-			return "", err
-		}
-
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		err = sendApproval(ctx, client, fromAddress, stakeToken, approveData)
 		if err != nil {
 			return "", err
 		}
 	}
 
-	hash, err = s.sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
 	if err != nil {
 		return "", err
 	}
 
-	farm := &model.YieldFarm{
+	farmRecord := &model.YieldFarm{
 		ChainId:     chainId,
 		Pool:        pool,
 		StakeToken:  stakeToken,
@@ -675,7 +642,7 @@ func (s *DefiLogic) Stake(ctx context.Context, chainId uint64, pool string, amou
 		UpdatedAt:   time.Now().Unix(),
 	}
 
-	err = dao.Defi.InsertYieldFarm(ctx, farm)
+	err = dao.Defi.InsertYieldFarm(ctx, farmRecord)
 	if err != nil {
 		return "", err
 	}
@@ -685,7 +652,7 @@ func (s *DefiLogic) Stake(ctx context.Context, chainId uint64, pool string, amou
 
 // Unstake 从收益农场解除质押
 func (s *DefiLogic) Unstake(ctx context.Context, chainId uint64, pool string, amount string, fromAddress string) (hash string, err error) {
-	client, err := ethclient.GetClient(chainId)
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
 	if err != nil {
 		return "", err
 	}
@@ -702,7 +669,7 @@ func (s *DefiLogic) Unstake(ctx context.Context, chainId uint64, pool string, am
 		return "", err
 	}
 
-	hash, err = s.sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
 	if err != nil {
 		return "", err
 	}
@@ -752,7 +719,7 @@ func (s *DefiLogic) ClaimReward(ctx context.Context, chainId uint64, pool string
 		return "", "", err
 	}
 
-	hash, err = s.sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, pool, big.NewInt(0), data)
 	if err != nil {
 		return "", "", err
 	}
@@ -809,9 +776,12 @@ func (s *DefiLogic) DepositVault(ctx context.Context, chainId uint64, vault stri
 	}
 
 	// approve token
-	token := "" // 从合约获取存款代币地址
-	if token != "0x0000000000000000000000000000000000000000" {
-		erc20, err := token.NewERC20(common.HexToAddress(token), client)
+	vaultToken, err := s.VaultToken(ctx, chainId, vault)
+	if err != nil {
+		return "", "", err
+	}
+	if vaultToken != "0x0000000000000000000000000000000000000000" {
+		erc20, err := token.NewERC20(common.HexToAddress(vaultToken), client)
 		if err != nil {
 			return "", "", err
 		}
@@ -821,18 +791,13 @@ func (s *DefiLogic) DepositVault(ctx context.Context, chainId uint64, vault stri
 			return "", "", err
 		}
 
-		approveHash, err := s.sendTransaction(ctx, client, fromAddress, token, big.NewInt(0), approveData)
-		if err != nil {
-			return "", "", err
-		}
-
-		_, err = s.waitTransaction(ctx, client, approveHash)
+		err = sendApproval(ctx, client, fromAddress, vaultToken, approveData)
 		if err != nil {
 			return "", "", err
 		}
 	}
 
-	hash, err = s.sendTransaction(ctx, client, fromAddress, vault, big.NewInt(0), data)
+	hash, err = sendTransaction(ctx, client, fromAddress, vault, big.NewInt(0), data)
 	if err != nil {
 		return "", "", err
 	}
@@ -840,7 +805,7 @@ func (s *DefiLogic) DepositVault(ctx context.Context, chainId uint64, vault stri
 	vaultRecord := &model.Vault{
 		ChainId:   chainId,
 		Vault:     vault,
-		Token:     token,
+		Token:     vaultToken,
 		Amount:    amount,
 		Shares:    sharesBig.String(),
 		User:      fromAddress,
@@ -858,3 +823,41 @@ func (s *DefiLogic) DepositVault(ctx context.Context, chainId uint64, vault stri
 
 	return hash, sharesBig.String(), nil
 }
+
+// StakeToken 获取收益农场的质押代币地址
+func (s *DefiLogic) StakeToken(ctx context.Context, chainId uint64, pool string) (string, error) {
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
+	if err != nil {
+		return "", err
+	}
+
+	farm, err := defi.NewFarm(common.HexToAddress(pool), client)
+	if err != nil {
+		return "", err
+	}
+
+	stakeToken, err := farm.StakingToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return stakeToken.Hex(), nil
+}
+
+// VaultToken 获取机枪池的存款代币地址
+func (s *DefiLogic) VaultToken(ctx context.Context, chainId uint64, vault string) (string, error) {
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
+	if err != nil {
+		return "", err
+	}
+
+	yearnVault, err := defi.NewYearnVault(common.HexToAddress(vault), client)
+	if err != nil {
+		return "", err
+	}
+
+	vaultToken, err := yearnVault.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return vaultToken.Hex(), nil
+}
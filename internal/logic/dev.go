@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/devchain"
+)
+
+// devWalletChain dev模式预置钱包的链名称
+const devWalletChain = "ETH"
+
+type DevLogic struct{}
+
+// Setup 初始化dev环境数据库: 重建表结构, 写入模拟链、模拟合约与预置账户钱包
+func (s *DevLogic) Setup(ctx context.Context, node *devchain.Node, deployments []*devchain.Deployment, mnemonic string) error {
+	//1.重建表结构, 模拟链每次启动都从创世区块开始, 保留旧数据会与链状态不一致
+	if err := dao.Schema.Drop(ctx); err != nil {
+		return err
+	}
+	if err := dao.Schema.Migrate(ctx); err != nil {
+		return err
+	}
+
+	//2.写入模拟链
+	now := time.Now().Unix()
+	chainId := node.ChainId.Uint64()
+	rpcUrls, err := json.Marshal([]string{node.HTTPURL, node.WSURL})
+	if err != nil {
+		return err
+	}
+	chain := &model.Chain{
+		Name:          "Dev",
+		ChainId:       chainId,
+		Symbol:        "ETH",
+		Decimals:      18,
+		RpcUrls:       string(rpcUrls),
+		Confirmations: g.Cfg().MustGet(ctx, "dev.confirmations", 1).Int(),
+		Status:        1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	for _, deployment := range deployments {
+		if deployment.Kind == devchain.MockBridge.Kind {
+			chain.BridgeAddress = deployment.Address.Hex()
+			chain.BridgeHeight = int64(deployment.Block)
+		}
+	}
+	if err := dao.Chain.Save(ctx, chain); err != nil {
+		return err
+	}
+
+	//3.写入模拟合约
+	creator := node.Accounts[0].Address.Hex()
+	for _, deployment := range deployments {
+		contract := &model.Contract{
+			Name:         deployment.Name,
+			Address:      deployment.Address.Hex(),
+			ABI:          deployment.ABI,
+			Bytecode:     hexutil.Encode(deployment.Bytecode),
+			ChainId:      chainId,
+			DeployHash:   deployment.TxHash.Hex(),
+			DeployHeight: int64(deployment.Block),
+			Creator:      creator,
+			Status:       1,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := dao.Contract.Insert(ctx, contract); err != nil {
+			return fmt.Errorf("seed contract %s: %w", deployment.Name, err)
+		}
+	}
+
+	//4.导入预置账户为托管钱包
+	wallets := &WalletLogic{}
+	for _, account := range node.Accounts {
+		if _, err := wallets.Import(ctx, devWalletChain, mnemonic, account.Index); err != nil {
+			return fmt.Errorf("seed wallet %s: %w", account.Address.Hex(), err)
+		}
+	}
+	return nil
+}
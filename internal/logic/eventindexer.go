@@ -0,0 +1,611 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/ethclientx"
+)
+
+const (
+	defaultEventBatchBlocks = 2000 // 单次FilterLogs查询的最大区块数
+	eventBatchGrowAfter     = 8    // 缩小查询范围后, 连续成功多少次再放大
+	defaultReorgDepth       = 128  // 保留区块哈希与已处理日志的区块数, 超出该深度的重组无法回滚
+)
+
+// EventHandler 事件处理函数
+type EventHandler func(ctx context.Context, log types.Log) error
+
+// EventRollback 重组回滚函数: 撤销fork之后已处理日志产生的数据, logs为重组窗口内记录的日志, 按处理顺序倒序
+type EventRollback func(ctx context.Context, fork uint64, logs []types.Log) error
+
+// EventSource 事件来源: 一个合约地址及按事件签名注册的处理函数, 处理进度按(ChainId, Name)保存在索引游标中
+type EventSource struct {
+	Name       string
+	ChainId    uint64
+	Address    common.Address
+	StartBlock uint64 // 游标不存在时的起始区块, 0表示从当前安全高度开始
+	group      string // 所属事件索引分组, 加载时设置
+	handlers   map[common.Hash]EventHandler
+	rollback   EventRollback
+}
+
+// NewEventSource 创建事件来源
+func NewEventSource(name string, chainId uint64, address common.Address, startBlock uint64) *EventSource {
+	return &EventSource{
+		Name:       name,
+		ChainId:    chainId,
+		Address:    address,
+		StartBlock: startBlock,
+		handlers:   make(map[common.Hash]EventHandler),
+	}
+}
+
+// Handle 注册事件签名(topic0)的处理函数
+func (s *EventSource) Handle(topic common.Hash, handler EventHandler) *EventSource {
+	s.handlers[topic] = handler
+	return s
+}
+
+// OnRollback 注册重组回滚函数, 回滚后游标退回分叉点, fork之后的日志按规范链重新处理
+func (s *EventSource) OnRollback(rollback EventRollback) *EventSource {
+	s.rollback = rollback
+	return s
+}
+
+// only 复制来源, 只保留指定事件签名的处理函数, 没有匹配的处理函数时返回nil
+func (s *EventSource) only(topics map[common.Hash]bool) *EventSource {
+	filtered := NewEventSource(s.Name, s.ChainId, s.Address, s.StartBlock)
+	filtered.group = s.group
+	filtered.rollback = s.rollback
+	for topic, handler := range s.handlers {
+		if topics[topic] {
+			filtered.handlers[topic] = handler
+		}
+	}
+	if len(filtered.handlers) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+// replayKey 回放模式的ctx键
+type replayKey struct{}
+
+// withReplay 标记ctx处于回放模式: 重新处理历史日志, 处理函数只写入可重复写入的数据, 不发起解锁等外部操作
+func withReplay(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replayKey{}, true)
+}
+
+// replaying 判断ctx是否处于回放模式
+func replaying(ctx context.Context) bool {
+	replay, _ := ctx.Value(replayKey{}).(bool)
+	return replay
+}
+
+// eventSourceLoader 加载一个分组下全部链的事件来源
+type eventSourceLoader func(ctx context.Context) ([]*EventSource, error)
+
+// eventGroups 事件索引分组及其来源
+var eventGroups = map[string]eventSourceLoader{
+	model.EventGroupBridge:   (&BridgeLogic{}).eventSources,
+	model.EventGroupNFT:      (&NFTLogic{}).eventSources,
+	model.EventGroupContract: (&ContractLogic{}).eventSources,
+}
+
+// eventBatch 单条链当前的查询区块数, 节点限制范围时缩小, 连续成功后逐步恢复
+type eventBatch struct {
+	mutex     sync.Mutex
+	size      uint64
+	successes int
+}
+
+// eventBatches 按链ID缓存的查询区块数
+var eventBatches sync.Map
+
+// eventCursor 索引过程中的来源进度
+type eventCursor struct {
+	source *EventSource
+	next   uint64 // 下一个待处理的区块
+}
+
+// eventRun 一轮索引的公共参数
+type eventRun struct {
+	client  *ethclient.Client
+	chainId uint64
+	group   string
+	safe    uint64 // 已达到确认数的最高区块
+	window  uint64 // 重组窗口起点, 不低于该高度的区块记录哈希与已处理日志
+}
+
+type EventIndexerLogic struct{}
+
+// Chains 获取分组中存在事件来源的链
+func (s *EventIndexerLogic) Chains(ctx context.Context, group string) ([]uint64, error) {
+	sources, err := s.loadSources(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[uint64]bool)
+	var chainIds []uint64
+	for _, source := range sources {
+		if !seen[source.ChainId] {
+			seen[source.ChainId] = true
+			chainIds = append(chainIds, source.ChainId)
+		}
+	}
+	sort.Slice(chainIds, func(i, j int) bool { return chainIds[i] < chainIds[j] })
+	return chainIds, nil
+}
+
+// Index 将分组在指定链上的全部来源从各自游标处索引到安全高度, 处于同一高度的来源合并查询
+func (s *EventIndexerLogic) Index(ctx context.Context, group string, chainId uint64) error {
+	//1.加载该链上的事件来源
+	all, err := s.loadSources(ctx, group)
+	if err != nil {
+		return err
+	}
+	var sources []*EventSource
+	for _, source := range all {
+		if source.ChainId == chainId {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	//2.计算安全高度
+	run, err := s.newRun(ctx, group, chainId)
+	if err != nil || run == nil {
+		return err
+	}
+	safe := run.safe
+
+	//3.检测重组, 回滚分叉点之后的数据
+	if err := s.checkReorg(ctx, run, sources); err != nil {
+		return err
+	}
+
+	//4.读取游标, 首次索引且未配置起始区块的来源从安全高度之后开始
+	cursors, err := s.loadCursors(ctx, chainId, sources, safe)
+	if err != nil {
+		return err
+	}
+
+	//5.每轮取进度最低的一组来源查询一段区块, 追上其他来源后合并
+	for ctx.Err() == nil {
+		sort.Slice(cursors, func(i, j int) bool { return cursors[i].next < cursors[j].next })
+		if len(cursors) == 0 || cursors[0].next > safe {
+			return dao.Indexer.Prune(ctx, chainId, group, run.window)
+		}
+		from := cursors[0].next
+		to := safe
+		var members []*eventCursor
+		for _, cursor := range cursors {
+			if cursor.next == from {
+				members = append(members, cursor)
+			} else if cursor.next <= to {
+				to = cursor.next - 1
+				break
+			}
+		}
+		to, err = s.indexRange(ctx, run, members, from, to)
+		if err != nil {
+			return err
+		}
+		for _, cursor := range members {
+			cursor.next = to + 1
+		}
+	}
+	return ctx.Err()
+}
+
+// newRun 计算链的安全高度与重组窗口, 链高度尚未超过确认数时返回nil
+func (s *EventIndexerLogic) newRun(ctx context.Context, group string, chainId uint64) (*eventRun, error) {
+	chain, err := dao.Chain.GetByChainId(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	if chain == nil {
+		return nil, fmt.Errorf("chain %d not found", chainId)
+	}
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	safe, ok, err := confirmedHead(ctx, client, chain)
+	if err != nil || !ok {
+		return nil, err
+	}
+	run := &eventRun{client: client, chainId: chainId, group: group, safe: safe}
+	if depth := g.Cfg().MustGet(ctx, "indexer.reorgDepth", defaultReorgDepth).Uint64(); safe > depth {
+		run.window = safe - depth
+	}
+	return run, nil
+}
+
+// loadSources 加载分组的事件来源
+func (s *EventIndexerLogic) loadSources(ctx context.Context, group string) ([]*EventSource, error) {
+	loader, ok := eventGroups[group]
+	if !ok {
+		return nil, fmt.Errorf("unknown event group %s", group)
+	}
+	sources, err := loader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		source.group = group
+	}
+	return sources, nil
+}
+
+// loadCursors 读取来源的游标, 同名来源只保留一个
+func (s *EventIndexerLogic) loadCursors(ctx context.Context, chainId uint64, sources []*EventSource, safe uint64) ([]*eventCursor, error) {
+	names := make([]string, 0, len(sources))
+	byName := make(map[string]*EventSource, len(sources))
+	for _, source := range sources {
+		if _, ok := byName[source.Name]; ok {
+			continue
+		}
+		byName[source.Name] = source
+		names = append(names, source.Name)
+	}
+	saved, err := dao.Indexer.GetCursors(ctx, chainId, names)
+	if err != nil {
+		return nil, err
+	}
+	processed := make(map[string]uint64, len(saved))
+	for _, cursor := range saved {
+		processed[cursor.Source] = cursor.BlockNumber
+	}
+
+	var (
+		cursors []*eventCursor
+		fresh   []string
+	)
+	for _, name := range names {
+		source := byName[name]
+		if number, ok := processed[name]; ok {
+			cursors = append(cursors, &eventCursor{source: source, next: number + 1})
+		} else if source.StartBlock > 0 {
+			cursors = append(cursors, &eventCursor{source: source, next: source.StartBlock})
+		} else {
+			fresh = append(fresh, name)
+		}
+	}
+	if err := dao.Indexer.SaveCursors(ctx, chainId, fresh, safe); err != nil {
+		return nil, err
+	}
+	return cursors, nil
+}
+
+// indexRange 分段查询[from, to]内的日志并分发给处理函数, 每段完成后推进游标; 返回本次处理到的区块
+func (s *EventIndexerLogic) indexRange(ctx context.Context, run *eventRun, members []*eventCursor, from, to uint64) (uint64, error) {
+	//1.查询一段日志
+	names := make([]string, 0, len(members))
+	sources := make([]*EventSource, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.source.Name)
+		sources = append(sources, member.source)
+	}
+	logs, end, header, err := s.filterLogs(ctx, run, sources, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	//2.按区块与日志顺序分发
+	journal, _, err := s.dispatch(ctx, run, sources, logs)
+	if err != nil {
+		return 0, err
+	}
+
+	//3.记录日志与段末区块哈希后推进游标
+	if err := dao.Indexer.InsertLogs(ctx, journal); err != nil {
+		return 0, err
+	}
+	if header != nil {
+		if err := dao.Indexer.SaveBlock(ctx, run.chainId, run.group, end, header.Hash().Hex()); err != nil {
+			return 0, err
+		}
+	}
+	if err := dao.Indexer.SaveCursors(ctx, run.chainId, names, end); err != nil {
+		return 0, err
+	}
+	return end, nil
+}
+
+// filterLogs 合并来源的地址与事件签名, 查询[from, to]起始的一段日志, 节点限制范围时缩小后重试; 返回日志、段末区块,
+// 段末区块处于重组窗口内时同时返回其区块头, 区块头先于日志获取, 其间发生的重组在下一轮被检测到
+func (s *EventIndexerLogic) filterLogs(ctx context.Context, run *eventRun, sources []*EventSource, from, to uint64) ([]types.Log, uint64, *types.Header, error) {
+	//1.合并查询条件
+	var (
+		addresses []common.Address
+		topics    []common.Hash
+		seenAddr  = make(map[common.Address]bool)
+		seenTopic = make(map[common.Hash]bool)
+	)
+	for _, source := range sources {
+		if !seenAddr[source.Address] {
+			seenAddr[source.Address] = true
+			addresses = append(addresses, source.Address)
+		}
+		for topic := range source.handlers {
+			if !seenTopic[topic] {
+				seenTopic[topic] = true
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	//2.查询
+	batch := s.batch(ctx, run.chainId)
+	for {
+		end := to
+		if size := batch.current(); end-from+1 > size {
+			end = from + size - 1
+		}
+		var (
+			header *types.Header
+			err    error
+		)
+		if end >= run.window {
+			if header, err = run.client.HeaderByNumber(ctx, new(big.Int).SetUint64(end)); err != nil {
+				return nil, 0, nil, err
+			}
+		}
+		logs, err := run.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: addresses,
+			Topics:    [][]common.Hash{topics},
+		})
+		if err == nil {
+			batch.succeed(ctx)
+			return logs, end, header, nil
+		}
+		if !ethclientx.IsRangeLimitError(err) || !batch.shrink() {
+			return nil, 0, nil, err
+		}
+		g.Log().Debugf(ctx, "chain %d filter logs %d-%d limited by provider, retry with %d blocks: %v", run.chainId, from, end, batch.current(), err)
+	}
+}
+
+// dispatch 按区块与日志顺序将日志分发给来源的处理函数, 返回重组窗口内处理过的日志记录与处理失败的次数;
+// 处理失败的日志写入死信表按退避重试, 写入失败时返回错误, 游标不推进, 该段日志在下一轮重新处理
+func (s *EventIndexerLogic) dispatch(ctx context.Context, run *eventRun, sources []*EventSource, logs []types.Log) ([]*model.IndexerLog, int, error) {
+	var (
+		journal     []*model.IndexerLog
+		failed      int
+		deadLetters = &DeadLetterLogic{}
+	)
+	for _, log := range logs {
+		if log.Removed || len(log.Topics) == 0 {
+			continue
+		}
+		for _, source := range sources {
+			if source.Address != log.Address {
+				continue
+			}
+			handler, ok := source.handlers[log.Topics[0]]
+			if !ok {
+				continue
+			}
+			if cause := handler(ctx, log); cause != nil {
+				failed++
+				g.Log().Errorf(ctx, "%s handle log %s:%d failed, saved for retry: %v", source.Name, log.TxHash.Hex(), log.Index, cause)
+				if err := deadLetters.record(ctx, source, log, cause); err != nil {
+					return nil, failed, err
+				}
+			}
+			if log.BlockNumber >= run.window {
+				journal = append(journal, journalLog(run.chainId, source.Name, log))
+			}
+		}
+	}
+	return journal, failed, nil
+}
+
+// checkReorg 将最近记录的区块哈希与规范链比对, 找到分叉点后回滚分组内各来源在分叉点之后的数据并退回游标
+func (s *EventIndexerLogic) checkReorg(ctx context.Context, run *eventRun, sources []*EventSource) error {
+	//1.自高向低比对, 最高记录仍在规范链上时无重组
+	depth := g.Cfg().MustGet(ctx, "indexer.reorgDepth", defaultReorgDepth).Int()
+	blocks, err := dao.Indexer.GetRecentBlocks(ctx, run.chainId, run.group, depth)
+	if err != nil || len(blocks) == 0 {
+		return err
+	}
+	var (
+		fork  uint64
+		found bool
+	)
+	for i, block := range blocks {
+		header, err := run.client.HeaderByNumber(ctx, new(big.Int).SetUint64(block.BlockNumber))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return err
+		}
+		if header != nil && header.Hash().Hex() == block.BlockHash {
+			if i == 0 {
+				return nil
+			}
+			fork, found = block.BlockNumber, true
+			break
+		}
+	}
+	if !found {
+		if lowest := blocks[len(blocks)-1].BlockNumber; lowest > 0 {
+			fork = lowest - 1
+		}
+		g.Log().Errorf(ctx, "chain %d %s reorg deeper than %d tracked blocks, rolling back to %d", run.chainId, run.group, len(blocks), fork)
+	} else {
+		g.Log().Warningf(ctx, "chain %d %s reorg detected, rolling back to block %d", run.chainId, run.group, fork)
+	}
+
+	//2.回滚游标高于分叉点的来源
+	names := make([]string, 0, len(sources))
+	byName := make(map[string]*EventSource, len(sources))
+	for _, source := range sources {
+		if _, ok := byName[source.Name]; !ok {
+			byName[source.Name] = source
+			names = append(names, source.Name)
+		}
+	}
+	cursors, err := dao.Indexer.GetCursors(ctx, run.chainId, names)
+	if err != nil {
+		return err
+	}
+	for _, cursor := range cursors {
+		if cursor.BlockNumber <= fork {
+			continue
+		}
+		source := byName[cursor.Source]
+		if err := s.rollback(ctx, run.chainId, source, fork); err != nil {
+			return fmt.Errorf("rollback %s: %w", source.Name, err)
+		}
+	}
+
+	//3.删除分叉点之后的区块记录, 回滚中断时下一轮会再次检测到重组
+	return dao.Indexer.DeleteBlocksAfter(ctx, run.chainId, run.group, fork)
+}
+
+// rollback 回滚单个来源在分叉点之后的数据, 删除日志记录与死信并退回游标
+func (s *EventIndexerLogic) rollback(ctx context.Context, chainId uint64, source *EventSource, fork uint64) error {
+	records, err := dao.Indexer.GetLogsAfter(ctx, chainId, source.Name, fork)
+	if err != nil {
+		return err
+	}
+	if source.rollback != nil {
+		logs := make([]types.Log, 0, len(records))
+		for _, record := range records {
+			logs = append(logs, journalEntry(record))
+		}
+		if err := source.rollback(ctx, fork, logs); err != nil {
+			return err
+		}
+	}
+	if err := dao.Indexer.DeleteLogsAfter(ctx, chainId, source.Name, fork); err != nil {
+		return err
+	}
+	if err := dao.DeadLetter.DeleteAfter(ctx, chainId, source.Name, fork); err != nil {
+		return err
+	}
+	return dao.Indexer.SaveCursors(ctx, chainId, []string{source.Name}, fork)
+}
+
+// journalLog 将日志转换为重组窗口内的处理记录
+func journalLog(chainId uint64, source string, log types.Log) *model.IndexerLog {
+	return &model.IndexerLog{
+		ChainId:     chainId,
+		Source:      source,
+		Address:     log.Address.Hex(),
+		Topics:      encodeTopics(log.Topics),
+		Data:        hexutil.Encode(log.Data),
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash.Hex(),
+		TxHash:      log.TxHash.Hex(),
+		TxIndex:     log.TxIndex,
+		LogIndex:    log.Index,
+		CreatedAt:   time.Now().Unix(),
+	}
+}
+
+// journalEntry 将处理记录还原为日志
+func journalEntry(record *model.IndexerLog) types.Log {
+	return types.Log{
+		Address:     common.HexToAddress(record.Address),
+		Topics:      decodeTopics(record.Topics),
+		Data:        common.FromHex(record.Data),
+		BlockNumber: record.BlockNumber,
+		BlockHash:   common.HexToHash(record.BlockHash),
+		TxHash:      common.HexToHash(record.TxHash),
+		TxIndex:     record.TxIndex,
+		Index:       record.LogIndex,
+		Removed:     true,
+	}
+}
+
+// encodeTopics 将日志主题编码为逗号分隔的hex
+func encodeTopics(topics []common.Hash) string {
+	values := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		values = append(values, topic.Hex())
+	}
+	return strings.Join(values, ",")
+}
+
+// decodeTopics 解析逗号分隔的日志主题
+func decodeTopics(value string) []common.Hash {
+	if value == "" {
+		return nil
+	}
+	var topics []common.Hash
+	for _, topic := range strings.Split(value, ",") {
+		topics = append(topics, common.HexToHash(topic))
+	}
+	return topics
+}
+
+// batch 获取链的查询区块数
+func (s *EventIndexerLogic) batch(ctx context.Context, chainId uint64) *eventBatch {
+	if batch, ok := eventBatches.Load(chainId); ok {
+		return batch.(*eventBatch)
+	}
+	size := g.Cfg().MustGet(ctx, "watch.batchBlocks", defaultEventBatchBlocks).Uint64()
+	if size == 0 {
+		size = defaultEventBatchBlocks
+	}
+	batch, _ := eventBatches.LoadOrStore(chainId, &eventBatch{size: size})
+	return batch.(*eventBatch)
+}
+
+// current 当前查询区块数
+func (b *eventBatch) current() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.size
+}
+
+// shrink 查询区块数减半, 已为1时返回false
+func (b *eventBatch) shrink() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.size <= 1 {
+		return false
+	}
+	b.size /= 2
+	b.successes = 0
+	return true
+}
+
+// succeed 记录一次成功查询, 连续成功后查询区块数翻倍, 不超过配置值
+func (b *eventBatch) succeed(ctx context.Context) {
+	limit := g.Cfg().MustGet(ctx, "watch.batchBlocks", defaultEventBatchBlocks).Uint64()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.size >= limit {
+		return
+	}
+	b.successes++
+	if b.successes < eventBatchGrowAfter {
+		return
+	}
+	b.successes = 0
+	b.size *= 2
+	if b.size > limit {
+		b.size = limit
+	}
+}
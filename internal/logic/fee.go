@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+)
+
+// defaultGasStrategy 未指定时使用的Gas策略
+const defaultGasStrategy = "STANDARD"
+
+// estimateFees 按GasService维护的Gas策略估算交易费用, 默认返回EIP-1559的maxFeePerGas/maxPriorityFeePerGas,
+// 链标记为legacy、区块不含baseFee或显式指定gasPrice时回退为legacy gasPrice
+func estimateFees(ctx context.Context, client *ethclient.Client, params *model.TxFeeParams) (*model.TxFees, error) {
+	if params == nil {
+		params = &model.TxFeeParams{}
+	}
+
+	//1.显式指定gasPrice时使用legacy交易
+	if params.GasPrice != "" {
+		gasPrice, err := parseWei("gasPrice", params.GasPrice)
+		if err != nil {
+			return nil, err
+		}
+		return &model.TxFees{Legacy: true, GasPrice: gasPrice}, nil
+	}
+
+	//2.判断链是否支持EIP-1559
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := dao.Chain.GetByChainId(ctx, chainID.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if (chain != nil && chain.LegacyTx) || header.BaseFee == nil {
+		gasPrice, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &model.TxFees{Legacy: true, GasPrice: gasPrice}, nil
+	}
+
+	//3.获取Gas策略
+	strategyType := params.Strategy
+	if strategyType == "" {
+		strategyType = defaultGasStrategy
+	}
+	strategy, err := dao.Gas.GetGasStrategy(ctx, chainID.Uint64(), strategyType)
+	if err != nil {
+		return nil, err
+	}
+
+	//4.优先费用: 显式指定 > Gas策略 > 节点建议
+	var tip *big.Int
+	if params.MaxPriorityFeePerGas != "" {
+		if tip, err = parseWei("maxPriorityFeePerGas", params.MaxPriorityFeePerGas); err != nil {
+			return nil, err
+		}
+	} else if strategy != nil && strategy.Priority != "" {
+		if tip, err = parseWei("strategy priority", strategy.Priority); err != nil {
+			return nil, err
+		}
+	} else if tip, err = client.SuggestGasTipCap(ctx); err != nil {
+		return nil, err
+	}
+
+	//5.最大费用: 显式指定 > max(2*baseFee, 策略baseFee) + 优先费用
+	var maxFee *big.Int
+	if params.MaxFeePerGas != "" {
+		if maxFee, err = parseWei("maxFeePerGas", params.MaxFeePerGas); err != nil {
+			return nil, err
+		}
+	} else {
+		baseFee := new(big.Int).Mul(header.BaseFee, big.NewInt(2))
+		if strategy != nil && strategy.BaseFee != "" {
+			strategyBaseFee, err := parseWei("strategy baseFee", strategy.BaseFee)
+			if err != nil {
+				return nil, err
+			}
+			if strategyBaseFee.Cmp(baseFee) > 0 {
+				baseFee = strategyBaseFee
+			}
+		}
+		maxFee = baseFee.Add(baseFee, tip)
+	}
+	if maxFee.Cmp(tip) < 0 {
+		return nil, errors.New("maxFeePerGas less than maxPriorityFeePerGas")
+	}
+
+	return &model.TxFees{
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: tip,
+	}, nil
+}
+
+// parseWei 解析wei数值
+func parseWei(name, value string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return v, nil
+}
@@ -0,0 +1,302 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/ethclientx"
+)
+
+// erc20TransferTopic ERC20 Transfer(address,address,uint256) 事件签名
+var erc20TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+type IndexerLogic struct{}
+
+// Poll 扫描所有启用链的新区块, 记录托管钱包的原生转账与ERC20转账
+func (s *IndexerLogic) Poll(ctx context.Context) error {
+	//1.加载托管钱包地址
+	wallets, err := dao.Wallet.GetAddresses(ctx)
+	if err != nil {
+		return err
+	}
+	if len(wallets) == 0 {
+		return nil
+	}
+	managed := make(map[common.Address]*model.Wallet, len(wallets))
+	for _, wallet := range wallets {
+		if common.IsHexAddress(wallet.Address) {
+			managed[common.HexToAddress(wallet.Address)] = wallet
+		}
+	}
+
+	//2.逐链扫描
+	chains, err := dao.Chain.GetActiveList(ctx)
+	if err != nil {
+		return err
+	}
+	for _, chain := range chains {
+		if err := s.indexChain(ctx, chain, managed); err != nil {
+			g.Log().Warningf(ctx, "index chain %d failed: %v", chain.ChainId, err)
+		}
+	}
+	return nil
+}
+
+// indexChain 从游标处扫描一批已确认区块并推进游标
+func (s *IndexerLogic) indexChain(ctx context.Context, chain *model.Chain, managed map[common.Address]*model.Wallet) error {
+	client, err := ethclientx.GetClientByChainId(ctx, chain.ChainId)
+	if err != nil {
+		return err
+	}
+
+	//1.计算可扫描的最高区块, 保留确认数以规避重组
+	safe, ok, err := confirmedHead(ctx, client, chain)
+	if err != nil || !ok {
+		return err
+	}
+
+	//2.读取游标, 首次运行从配置的起始区块或当前安全高度开始
+	cursor, err := dao.Indexer.GetCursor(ctx, chain.ChainId, model.IndexerSourceInbound)
+	if err != nil {
+		return err
+	}
+	var from uint64
+	if cursor != nil {
+		from = cursor.BlockNumber + 1
+	} else {
+		from = g.Cfg().MustGet(ctx, fmt.Sprintf("indexer.startBlocks.%d", chain.ChainId)).Uint64()
+		if from == 0 {
+			return dao.Indexer.SaveCursor(ctx, chain.ChainId, model.IndexerSourceInbound, safe)
+		}
+	}
+	if from > safe {
+		return nil
+	}
+	to := from + g.Cfg().MustGet(ctx, "indexer.batchBlocks", 100).Uint64() - 1
+	if to > safe {
+		to = safe
+	}
+
+	//3.扫描原生转账与代币转账
+	blockTimes := make(map[uint64]uint64)
+	transfers, err := s.scanNative(ctx, client, chain.ChainId, from, to, managed, blockTimes)
+	if err != nil {
+		return err
+	}
+	tokenTransfers, err := s.scanTokens(ctx, client, chain.ChainId, from, to, managed, blockTimes)
+	if err != nil {
+		return err
+	}
+	transfers = append(transfers, tokenTransfers...)
+
+	//4.写入交易记录后推进游标
+	for _, transfer := range transfers {
+		if err := s.record(ctx, transfer); err != nil {
+			return err
+		}
+	}
+	return dao.Indexer.SaveCursor(ctx, chain.ChainId, model.IndexerSourceInbound, to)
+}
+
+// confirmedHead 获取链上已达到确认数的最高区块, 链高度不足确认数时返回false
+func confirmedHead(ctx context.Context, client *ethclient.Client, chain *model.Chain) (uint64, bool, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	lag := uint64(chain.Confirmations)
+	if lag == 0 {
+		lag = g.Cfg().MustGet(ctx, "indexer.confirmations", 12).Uint64()
+	}
+	if head < lag {
+		return 0, false, nil
+	}
+	return head - lag, true, nil
+}
+
+// scanNative 逐块扫描与托管钱包相关的原生转账
+func (s *IndexerLogic) scanNative(ctx context.Context, client *ethclient.Client, chainId uint64, from, to uint64, managed map[common.Address]*model.Wallet, blockTimes map[uint64]uint64) ([]*model.Transaction, error) {
+	if !g.Cfg().MustGet(ctx, "indexer.scanNative", true).Bool() {
+		return nil, nil
+	}
+	signer := types.LatestSignerForChainID(new(big.Int).SetUint64(chainId))
+	var transfers []*model.Transaction
+	for number := from; number <= to; number++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, err
+		}
+		blockTimes[number] = block.Time()
+
+		for _, tx := range block.Transactions() {
+			if tx.To() == nil || tx.Value().Sign() == 0 {
+				continue
+			}
+			sender, err := types.Sender(signer, tx)
+			if err != nil {
+				continue
+			}
+			if managed[sender] == nil && managed[*tx.To()] == nil {
+				continue
+			}
+
+			receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+			if err != nil {
+				return nil, err
+			}
+			status := model.TransactionStatusSuccess
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				status = model.TransactionStatusFailed
+			}
+			transfers = append(transfers, indexedTransfer(chainId, managed, sender, *tx.To(), "", tx.Value(),
+				tx.Hash().Hex(), model.NativeLogIndex, number, block.Time(), status))
+		}
+	}
+	return transfers, nil
+}
+
+// scanTokens 按托管地址过滤ERC20 Transfer日志, 分别匹配转出方与接收方
+func (s *IndexerLogic) scanTokens(ctx context.Context, client *ethclient.Client, chainId uint64, from, to uint64, managed map[common.Address]*model.Wallet, blockTimes map[uint64]uint64) ([]*model.Transaction, error) {
+	topics := make([]common.Hash, 0, len(managed))
+	for address := range managed {
+		topics = append(topics, common.BytesToHash(address.Bytes()))
+	}
+
+	batch := g.Cfg().MustGet(ctx, "indexer.topicBatch", 200).Int()
+	seen := make(map[string]bool)
+	var transfers []*model.Transaction
+	for start := 0; start < len(topics); start += batch {
+		end := start + batch
+		if end > len(topics) {
+			end = len(topics)
+		}
+		chunk := topics[start:end]
+
+		for _, filter := range [][][]common.Hash{
+			{{erc20TransferTopic}, chunk},
+			{{erc20TransferTopic}, nil, chunk},
+		} {
+			logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(from),
+				ToBlock:   new(big.Int).SetUint64(to),
+				Topics:    filter,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, log := range logs {
+				// ERC721的Transfer事件tokenId也被索引, 主题数为4
+				if len(log.Topics) != 3 || len(log.Data) != 32 || log.Removed {
+					continue
+				}
+				key := fmt.Sprintf("%s:%d", log.TxHash.Hex(), log.Index)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				blockTime, err := s.blockTime(ctx, client, log.BlockNumber, blockTimes)
+				if err != nil {
+					return nil, err
+				}
+				transfers = append(transfers, indexedTransfer(chainId, managed,
+					common.BytesToAddress(log.Topics[1].Bytes()),
+					common.BytesToAddress(log.Topics[2].Bytes()),
+					log.Address.Hex(), new(big.Int).SetBytes(log.Data),
+					log.TxHash.Hex(), int(log.Index), log.BlockNumber, blockTime, model.TransactionStatusSuccess))
+			}
+		}
+	}
+	return transfers, nil
+}
+
+// blockTime 获取区块时间, 同一批次内缓存
+func (s *IndexerLogic) blockTime(ctx context.Context, client *ethclient.Client, number uint64, blockTimes map[uint64]uint64) (uint64, error) {
+	if t, ok := blockTimes[number]; ok {
+		return t, nil
+	}
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return 0, err
+	}
+	blockTimes[number] = header.Time
+	return header.Time, nil
+}
+
+// record 写入索引到的转账, 已由本服务发出的交易仅补充区块信息与方向
+func (s *IndexerLogic) record(ctx context.Context, transfer *model.Transaction) error {
+	existing, err := dao.Transaction.GetByChainHash(ctx, transfer.ChainId, transfer.Hash)
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		if e.BlockTime != 0 && e.LogIndex == transfer.LogIndex && e.Direction == transfer.Direction {
+			return nil
+		}
+	}
+	for _, e := range existing {
+		if e.BlockTime == 0 &&
+			strings.EqualFold(e.ToAddress, transfer.ToAddress) &&
+			strings.EqualFold(e.TokenAddress, transfer.TokenAddress) &&
+			e.Amount == transfer.Amount {
+			return dao.Transaction.UpdateById(ctx, e.Id, g.Map{
+				"log_index":    transfer.LogIndex,
+				"direction":    transfer.Direction,
+				"block_number": transfer.BlockNumber,
+				"block_time":   transfer.BlockTime,
+				"updated_at":   time.Now().Unix(),
+			})
+		}
+	}
+	return dao.Transaction.Insert(ctx, transfer)
+}
+
+// indexedTransfer 构建索引交易记录, 按托管钱包判断方向与归属用户
+func indexedTransfer(chainId uint64, managed map[common.Address]*model.Wallet, from, to common.Address, token string, amount *big.Int, hash string, logIndex int, blockNumber, blockTime uint64, status int) *model.Transaction {
+	fromWallet, toWallet := managed[from], managed[to]
+	transfer := &model.Transaction{
+		ChainId:      chainId,
+		FromAddress:  from.Hex(),
+		ToAddress:    to.Hex(),
+		Amount:       amount.String(),
+		TokenAddress: token,
+		Hash:         hash,
+		LogIndex:     logIndex,
+		Status:       status,
+		BlockNumber:  int64(blockNumber),
+		BlockTime:    int64(blockTime),
+		CreatedAt:    time.Now().Unix(),
+		UpdatedAt:    time.Now().Unix(),
+	}
+	// 使用钱包表中的地址写法, 保证按地址查询历史时能匹配
+	if fromWallet != nil {
+		transfer.FromAddress = fromWallet.Address
+	}
+	if toWallet != nil {
+		transfer.ToAddress = toWallet.Address
+	}
+	switch {
+	case fromWallet != nil && toWallet != nil:
+		transfer.Direction = model.TransactionDirectionInternal
+		transfer.UserId = fromWallet.UserId
+	case fromWallet != nil:
+		transfer.Direction = model.TransactionDirectionOut
+		transfer.UserId = fromWallet.UserId
+	default:
+		transfer.Direction = model.TransactionDirectionIn
+		transfer.UserId = toWallet.UserId
+	}
+	return transfer
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"github.com/ethereum/go-ethereum"
+	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -12,13 +12,16 @@ import (
 	v1 "go-wallet-defi/api/v1"
 	"go-wallet-defi/internal/dao"
 	"go-wallet-defi/internal/model"
-	"go-wallet-defi/internal/pkg/ethclientx"
 	"go-wallet-defi/internal/pkg/ipfs"
 	"math/big"
 	"strings"
 	"time"
 )
 
+// erc1155TransferSingleTopic ERC1155 TransferSingle(address,address,address,uint256,uint256) 事件签名
+// ERC721的Transfer与ERC20签名相同, 使用erc20TransferTopic并以主题数区分
+var erc1155TransferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+
 type NFTLogic struct{}
 
 // Mint 铸造NFT
@@ -73,7 +76,10 @@ func (l *NFTLogic) Mint(ctx context.Context, req *v1.MintNFTReq) (hash string, t
 		return "", "", err
 	}
 	//7.调用合约mint方法
-	client := ethclientx.GetClient(ctx)
+	client, _, err := chainClient(ctx, contract.ChainId)
+	if err != nil {
+		return "", "", err
+	}
 	parsed, err := abi.JSON(strings.NewReader(contract.ABI))
 	if err != nil {
 		return "", "", err
@@ -95,50 +101,19 @@ func (l *NFTLogic) Mint(ctx context.Context, req *v1.MintNFTReq) (hash string, t
 	}
 
 	//8.构建交易
-	nonce, err := client.PendingNonceAt(ctx, common.HexToAddress(req.From))
-	if err != nil {
-		return "", "", err
-	}
-
-	gasPrice, err := client.SuggestGasPrice(ctx)
-	if err != nil {
-		return "", "", err
-	}
-
-	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
-		From: common.HexToAddress(req.From),
-		To:   &common.HexToAddress(contract.Address),
-		Data: data,
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	tx := types.NewTransaction(nonce, common.HexToAddress(contract.Address), big.NewInt(0), gasLimit, gasPrice, data)
-
-	//9.签名交易
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		return "", "", err
-	}
-
-	wallet, err := dao.Wallet.GetByAddress(ctx, req.From)
-	if err != nil {
-		return "", "", err
-	}
-
-	privateKey, err := crypto.HexToECDSA(wallet.PrivateKey)
+	fees, err := estimateFees(ctx, client, nil)
 	if err != nil {
 		return "", "", err
 	}
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	contractAddress := common.HexToAddress(contract.Address)
+	gasLimit, err := estimateGas(ctx, client, req.From, &contractAddress, nil, data)
 	if err != nil {
 		return "", "", err
 	}
 
-	// 发送交易
-	err = client.SendTransaction(ctx, signedTx)
+	//9.分配nonce, 签名并发送交易
+	signedTx, err := broadcastTransaction(ctx, client, req.From, &contractAddress, big.NewInt(0), gasLimit, data, fees)
 	if err != nil {
 		return "", "", err
 	}
@@ -200,7 +175,10 @@ func (s *NFTLogic) Transfer(ctx context.Context, nftId uint64, from, to string,
 	}
 
 	// 调用合约transfer方法
-	client := ethclientx.GetClient(ctx)
+	client, _, err := chainClient(ctx, contract.ChainId)
+	if err != nil {
+		return "", err
+	}
 	parsed, err := abi.JSON(strings.NewReader(contract.ABI))
 	if err != nil {
 		return "", err
@@ -227,88 +205,189 @@ func (s *NFTLogic) Transfer(ctx context.Context, nftId uint64, from, to string,
 	}
 
 	// 构建交易
-	nonce, err := client.PendingNonceAt(ctx, common.HexToAddress(from))
+	fees, err := estimateFees(ctx, client, nil)
 	if err != nil {
 		return "", err
 	}
 
-	gasPrice, err := client.SuggestGasPrice(ctx)
+	contractAddress := common.HexToAddress(contract.Address)
+	gasLimit, err := estimateGas(ctx, client, from, &contractAddress, nil, data)
 	if err != nil {
 		return "", err
 	}
 
-	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
-		From: common.HexToAddress(from),
-		To:   &common.HexToAddress(contract.Address),
-		Data: data,
-	})
+	// 分配nonce, 签名并发送交易
+	signedTx, err := broadcastTransaction(ctx, client, from, &contractAddress, big.NewInt(0), gasLimit, data, fees)
 	if err != nil {
 		return "", err
 	}
 
-	tx := types.NewTransaction(nonce, common.HexToAddress(contract.Address), big.NewInt(0), gasLimit, gasPrice, data)
-
-	// 签名交易
-	chainID, err := client.ChainID(ctx)
+	// 更新NFT所有者
+	err = dao.NFT.UpdateOwner(ctx, nftId, to)
 	if err != nil {
 		return "", err
 	}
 
-	wallet, err := dao.Wallet.GetByAddress(ctx, from)
-	if err != nil {
-		return "", err
+	// 保存转移记录
+	transfer := &model.NFTTransfer{
+		NftId:     nftId,
+		From:      from,
+		To:        to,
+		Amount:    amount,
+		Type:      "transfer",
+		Hash:      signedTx.Hash().Hex(),
+		CreatedAt: time.Now().Unix(),
 	}
 
-	privateKey, err := crypto.HexToECDSA(wallet.PrivateKey)
+	err = dao.NFT.InsertTransfer(ctx, transfer)
 	if err != nil {
 		return "", err
 	}
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	return signedTx.Hash().Hex(), nil
+}
+
+// List 获取NFT列表
+func (s *NFTLogic) List(ctx context.Context, owner, creator string, contractId uint64, page, pageSize int) ([]*model.NFT, int, error) {
+	return dao.NFT.GetList(ctx, owner, creator, contractId, page, pageSize)
+}
+
+// ListMarket 获取市场列表
+func (s *NFTLogic) ListMarket(ctx context.Context, seller string, status int, page, pageSize int) ([]*model.NFTMarket, int, error) {
+	return dao.NFT.GetMarketList(ctx, seller, status, page, pageSize)
+}
+
+// eventSources 每个ERC721/ERC1155合约一个事件来源, 处理转移事件
+func (s *NFTLogic) eventSources(ctx context.Context) ([]*EventSource, error) {
+	contracts, err := dao.Contract.GetActiveList(ctx)
 	if err != nil {
-		signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-		if err != nil {
-			return "", err
+		return nil, err
+	}
+	var sources []*EventSource
+	for _, contract := range contracts {
+		standard := nftStandard(contract.ABI)
+		if standard == "" {
+			continue
 		}
-
-		// 发送交易
-		err = client.SendTransaction(ctx, signedTx)
-		if err != nil {
-			return "", err
+		contract := contract
+		source := NewEventSource(fmt.Sprintf("%s:%d", model.EventGroupNFT, contract.Id), contract.ChainId,
+			common.HexToAddress(contract.Address), uint64(contract.DeployHeight))
+		topic := erc20TransferTopic
+		if standard == "ERC1155" {
+			topic = erc1155TransferSingleTopic
 		}
+		source.Handle(topic, func(ctx context.Context, log types.Log) error {
+			return s.handleTransfer(ctx, contract, standard, log)
+		}).OnRollback(func(ctx context.Context, fork uint64, logs []types.Log) error {
+			return s.rollbackTransfers(ctx, contract, fork)
+		})
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
 
-		// 更新NFT所有者
-		err = dao.NFT.UpdateOwner(ctx, nftId, to)
-		if err != nil {
-			return "", err
+// handleTransfer 解析转移事件, 保存转移记录并更新NFT所有者, 未由本服务登记的NFT忽略; 重复处理同一日志不产生重复记录
+func (s *NFTLogic) handleTransfer(ctx context.Context, contract *model.Contract, standard string, log types.Log) error {
+	var (
+		from, to        common.Address
+		tokenId, amount *big.Int
+	)
+	if standard == "ERC721" {
+		if len(log.Topics) != 4 {
+			return nil
 		}
-
-		// 保存转移记录
-		transfer := &model.NFTTransfer{
-			NftId:     nftId,
-			From:      from,
-			To:        to,
-			Amount:    amount,
-			Type:      "transfer",
-			Hash:      signedTx.Hash().Hex(),
-			CreatedAt: time.Now().Unix(),
+		from = common.BytesToAddress(log.Topics[1].Bytes())
+		to = common.BytesToAddress(log.Topics[2].Bytes())
+		tokenId = new(big.Int).SetBytes(log.Topics[3].Bytes())
+		amount = big.NewInt(1)
+	} else {
+		// TransferSingle(operator, from, to, id, value): id与value不在索引中
+		if len(log.Topics) != 4 || len(log.Data) != 64 {
+			return nil
 		}
+		from = common.BytesToAddress(log.Topics[2].Bytes())
+		to = common.BytesToAddress(log.Topics[3].Bytes())
+		tokenId = new(big.Int).SetBytes(log.Data[:32])
+		amount = new(big.Int).SetBytes(log.Data[32:])
+	}
 
-		err = dao.NFT.InsertTransfer(ctx, transfer)
-		if err != nil {
-			return "", err
-		}
+	nft, err := dao.NFT.GetByTokenId(ctx, contract.Id, tokenId.String())
+	if err != nil {
+		return err
+	}
+	if nft == nil {
+		return nil
+	}
 
-		return signedTx.Hash().Hex(), nil
+	transfer := &model.NFTTransfer{
+		NftId:       nft.Id,
+		From:        from.Hex(),
+		To:          to.Hex(),
+		Amount:      amount.Uint64(),
+		Type:        "transfer",
+		Hash:        log.TxHash.Hex(),
+		ChainId:     contract.ChainId,
+		LogIndex:    log.Index,
+		BlockNumber: int64(log.BlockNumber),
+		BlockTime:   time.Now().Unix(),
+		CreatedAt:   time.Now().Unix(),
 	}
+	if err := dao.NFT.InsertTransfer(ctx, transfer); err != nil {
+		return err
+	}
+
+	// 所有者取最近一次链上转移的接收方, 回放历史区块时不会覆盖之后的转移
+	last, err := dao.NFT.GetLastTransfer(ctx, nft.Id)
+	if err != nil || last == nil {
+		return err
+	}
+	return dao.NFT.UpdateOwner(ctx, nft.Id, last.To)
 }
 
-// List 获取NFT列表
-func (s *NFTLogic) List(ctx context.Context, owner, creator string, contractId uint64, page, pageSize int) ([]*model.NFT, int, error) {
-	return dao.NFT.GetList(ctx, owner, creator, contractId, page, pageSize)
+// rollbackTransfers 删除分叉点之后的链上转移记录, 所有者恢复为仍在规范链上的最近一次转移的接收方, 之后的转移由重放补回
+func (s *NFTLogic) rollbackTransfers(ctx context.Context, contract *model.Contract, fork uint64) error {
+	nftIds, err := dao.NFT.DeleteTransfersAfter(ctx, contract.Id, fork)
+	if err != nil {
+		return err
+	}
+	for _, nftId := range nftIds {
+		last, err := dao.NFT.GetLastTransfer(ctx, nftId)
+		if err != nil {
+			return err
+		}
+		// 没有已上链的转移时保留铸造时登记的所有者
+		if last == nil {
+			continue
+		}
+		if err := dao.NFT.UpdateOwner(ctx, nftId, last.To); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// ListMarket 获取市场列表
-func (s *NFTLogic) ListMarket(ctx context.Context, seller string, status int, page, pageSize int) ([]*model.NFTMarket, int, error) {
-	return dao.NFT.GetMarketList(ctx, seller, status, page, pageSize)
+// nftStandard 按ABI中的转移事件或方法判断NFT合约标准, 非NFT合约返回空
+func nftStandard(abiJson string) string {
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		return ""
+	}
+	if _, ok := parsed.Events["TransferSingle"]; ok {
+		return "ERC1155"
+	}
+	if event, ok := parsed.Events["Transfer"]; ok && len(event.Inputs) == 3 && event.Inputs[2].Indexed {
+		return "ERC721"
+	}
+	if method, ok := parsed.Methods["safeTransferFrom"]; ok {
+		switch len(method.Inputs) {
+		case 5:
+			return "ERC1155"
+		case 3, 4:
+			return "ERC721"
+		}
+	}
+	if _, ok := parsed.Methods["ownerOf"]; ok {
+		return "ERC721"
+	}
+	return ""
 }
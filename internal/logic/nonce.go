@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+)
+
+// defaultNonceTTL 预留nonce的默认有效期(秒), 超时仍未上链的预留可被复用
+const defaultNonceTTL = 300
+
+// reserveNonce 按(chainId, address)分配nonce, 并发请求通过数据库行锁串行
+func reserveNonce(ctx context.Context, client *ethclient.Client, from string) (*model.NonceReservation, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	address := common.HexToAddress(from)
+	pending, err := client.PendingNonceAt(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	ttl := g.Cfg().MustGet(ctx, "nonce.reservationTTL", defaultNonceTTL).Int64()
+	return dao.Nonce.Reserve(ctx, chainID.Uint64(), address.Hex(), pending, time.Now().Unix()-ttl)
+}
+
+// markNonceSent 交易广播成功后标记nonce已使用
+func markNonceSent(ctx context.Context, reservation *model.NonceReservation, hash string) {
+	err := dao.Nonce.UpdateReservation(ctx, reservation.Id, g.Map{
+		"status":     model.NonceStatusSent,
+		"tx_hash":    hash,
+		"updated_at": time.Now().Unix(),
+	})
+	if err != nil {
+		g.Log().Warningf(ctx, "mark nonce %d of %s sent failed: %v", reservation.Nonce, reservation.Address, err)
+	}
+}
+
+// releaseNonce 交易未能广播时释放nonce, 供后续交易填补空缺
+func releaseNonce(ctx context.Context, reservation *model.NonceReservation) {
+	err := dao.Nonce.UpdateReservation(ctx, reservation.Id, g.Map{
+		"status":     model.NonceStatusReleased,
+		"updated_at": time.Now().Unix(),
+	})
+	if err != nil {
+		g.Log().Warningf(ctx, "release nonce %d of %s failed: %v", reservation.Nonce, reservation.Address, err)
+	}
+}
@@ -0,0 +1,409 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/contracts/token"
+)
+
+// defaultEnvelopeTTL 待签名信封的默认有效期(秒)
+const defaultEnvelopeTTL = 3600
+
+type OfflineLogic struct{}
+
+// Build 生成待签名交易信封, 分配nonce并估算费用, 私钥不参与
+func (s *OfflineLogic) Build(ctx context.Context, params *model.OfflineTxParams) (*model.TxEnvelope, error) {
+	//1.获取客户端与钱包
+	client, chainId, err := chainClient(ctx, params.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(params.From) {
+		return nil, fmt.Errorf("invalid from address %q", params.From)
+	}
+	wallet, err := dao.Wallet.GetByAddress(ctx, params.From)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, errors.New("wallet not found")
+	}
+
+	//2.构建交易目标、金额与数据
+	offline := &model.OfflineTransaction{
+		UserId:       wallet.UserId,
+		ChainId:      chainId,
+		FromAddress:  params.From,
+		ToAddress:    params.To,
+		TokenAddress: params.Token,
+	}
+	var (
+		to    *common.Address
+		value = big.NewInt(0)
+		data  []byte
+	)
+	if params.To != "" {
+		if !common.IsHexAddress(params.To) {
+			return nil, fmt.Errorf("invalid to address %q", params.To)
+		}
+		address := common.HexToAddress(params.To)
+		to = &address
+	}
+	amount := params.Amount
+	if amount == "" {
+		amount = "0"
+	}
+	switch {
+	case params.Token != "":
+		if to == nil {
+			return nil, errors.New("recipient is required for token transfer")
+		}
+		if params.Data != "" {
+			return nil, errors.New("data is not allowed for token transfer")
+		}
+		tokenAmount, err := parseAmount(ctx, client, chainId, params.Token, amount, params.Unit)
+		if err != nil {
+			return nil, err
+		}
+		raw, _ := new(big.Int).SetString(tokenAmount.Raw, 10)
+		erc20, err := token.NewERC20(common.HexToAddress(params.Token), client)
+		if err != nil {
+			return nil, err
+		}
+		data, err = erc20.PackTransfer(*to, raw)
+		if err != nil {
+			return nil, err
+		}
+		tokenAddress := common.HexToAddress(params.Token)
+		to = &tokenAddress
+		offline.Amount = tokenAmount.Raw
+	default:
+		if params.Data != "" {
+			data, err = hexutil.Decode(params.Data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid data: %w", err)
+			}
+		}
+		if to == nil && len(data) == 0 {
+			return nil, errors.New("recipient is required")
+		}
+		nativeAmount, err := parseAmount(ctx, client, chainId, "", amount, params.Unit)
+		if err != nil {
+			return nil, err
+		}
+		value, _ = new(big.Int).SetString(nativeAmount.Raw, 10)
+		offline.Amount = nativeAmount.Raw
+	}
+
+	//3.估算费用与gas
+	fees, err := estimateFees(ctx, client, params.Fee)
+	if err != nil {
+		return nil, err
+	}
+	gasLimit := params.GasLimit
+	if gasLimit == 0 {
+		if len(data) == 0 {
+			gasLimit = 21000
+		} else {
+			gasLimit, err = estimateGas(ctx, client, params.From, to, value, data)
+			if err != nil {
+				return nil, err
+			}
+			gasLimit = gasLimit * 12 / 10
+		}
+	}
+
+	//4.分配nonce并构建未签名交易
+	reservation, err := reserveNonce(ctx, client, params.From)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := func() (*model.TxEnvelope, error) {
+		tx, err := newTransaction(ctx, client, reservation.Nonce, to, value, gasLimit, data, fees)
+		if err != nil {
+			return nil, err
+		}
+		unsigned, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		signingHash := types.LatestSignerForChainID(new(big.Int).SetUint64(chainId)).Hash(tx)
+
+		now := time.Now().Unix()
+		expireAt := now + g.Cfg().MustGet(ctx, "offline.envelopeTTL", defaultEnvelopeTTL).Int64()
+		envelope := &model.TxEnvelope{
+			ChainId:     chainId,
+			From:        common.HexToAddress(params.From).Hex(),
+			Nonce:       tx.Nonce(),
+			Value:       value.String(),
+			Data:        hexutil.Encode(data),
+			GasLimit:    gasLimit,
+			TxType:      int(tx.Type()),
+			UnsignedTx:  hexutil.Encode(unsigned),
+			SigningHash: signingHash.Hex(),
+			Summary:     summarizeTransaction(ctx, client, chainId, to, value, data, gasLimit, fees),
+			ExpireAt:    expireAt,
+		}
+		if to != nil {
+			envelope.To = to.Hex()
+		}
+		if fees.Legacy {
+			envelope.GasPrice = fees.GasPrice.String()
+		} else {
+			envelope.MaxFeePerGas = fees.MaxFeePerGas.String()
+			envelope.MaxPriorityFeePerGas = fees.MaxPriorityFeePerGas.String()
+		}
+
+		//5.保存信封
+		offline.Nonce = tx.Nonce()
+		offline.ReservationId = reservation.Id
+		offline.SigningHash = envelope.SigningHash
+		offline.Status = model.OfflineTxStatusPending
+		offline.ExpireAt = expireAt
+		offline.CreatedAt = now
+		offline.UpdatedAt = now
+		if err := dao.Offline.Insert(ctx, offline); err != nil {
+			return nil, err
+		}
+		envelope.Id = offline.Id
+		content, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, err
+		}
+		if err := dao.Offline.Update(ctx, offline.Id, g.Map{"envelope": string(content)}); err != nil {
+			return nil, err
+		}
+		return envelope, nil
+	}()
+	if err != nil {
+		releaseNonce(ctx, reservation)
+		return nil, err
+	}
+
+	// 离线签名耗时较长, 预留有效期延长至信封过期, 期间该nonce不会被其他交易复用
+	if err := dao.Nonce.UpdateReservation(ctx, reservation.Id, g.Map{"updated_at": envelope.ExpireAt}); err != nil {
+		g.Log().Warningf(ctx, "extend nonce %d of %s failed: %v", reservation.Nonce, reservation.Address, err)
+	}
+	return envelope, nil
+}
+
+// Submit 校验外部签名的原始交易与信封一致后广播, 并记录到交易表
+func (s *OfflineLogic) Submit(ctx context.Context, id uint64, rawTx string) (string, error) {
+	//1.获取信封
+	offline, err := dao.Offline.GetById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if offline == nil {
+		return "", errors.New("envelope not found")
+	}
+	if offline.Status != model.OfflineTxStatusPending {
+		return "", fmt.Errorf("envelope %d is not pending", id)
+	}
+	reservation := &model.NonceReservation{Id: offline.ReservationId, ChainId: offline.ChainId, Address: offline.FromAddress, Nonce: offline.Nonce}
+	if time.Now().Unix() > offline.ExpireAt {
+		if err := dao.Offline.Update(ctx, id, g.Map{"status": model.OfflineTxStatusExpired, "updated_at": time.Now().Unix()}); err != nil {
+			return "", err
+		}
+		// 曾广播但无法确认结果的信封保留nonce, 由预留有效期到期后按链上nonce回收
+		if offline.Hash == "" {
+			releaseNonce(ctx, reservation)
+		}
+		return "", fmt.Errorf("envelope %d expired", id)
+	}
+
+	//2.解码并校验签名交易
+	raw, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return "", fmt.Errorf("invalid raw transaction: %w", err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return "", fmt.Errorf("invalid raw transaction: %w", err)
+	}
+	if !tx.Protected() {
+		return "", errors.New("signed transaction is not replay protected")
+	}
+	signer := types.LatestSignerForChainID(new(big.Int).SetUint64(offline.ChainId))
+	if signer.Hash(tx).Hex() != offline.SigningHash {
+		return "", errors.New("signed transaction does not match envelope")
+	}
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return "", err
+	}
+	if sender != common.HexToAddress(offline.FromAddress) {
+		return "", fmt.Errorf("signed by %s, expected %s", sender.Hex(), common.HexToAddress(offline.FromAddress).Hex())
+	}
+
+	//3.标记已提交, 防止并发重复广播
+	hash := tx.Hash().Hex()
+	ok, err := dao.Offline.MarkSubmitted(ctx, id, hash, time.Now().Unix())
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("envelope %d is not pending", id)
+	}
+
+	//4.广播交易; 报错时按哈希确认交易是否已进入节点(超时但节点已接收、重复提交), 已进入时视为广播成功,
+	// 否则恢复为待签名; 无法确认时保留交易哈希, 信封过期时不释放可能已被使用的nonce
+	client, _, err := chainClient(ctx, offline.ChainId)
+	if err == nil {
+		if sendErr := client.SendTransaction(ctx, tx); sendErr != nil && !isAlreadyKnown(sendErr) {
+			_, _, lookupErr := client.TransactionByHash(ctx, tx.Hash())
+			if lookupErr == nil {
+				g.Log().Warningf(ctx, "submit envelope %d returned %v, but the node already has %s", id, sendErr, hash)
+			} else {
+				err = sendErr
+				if errors.Is(lookupErr, ethereum.NotFound) {
+					hash = ""
+				}
+			}
+		}
+	}
+	if err != nil {
+		if updateErr := dao.Offline.Update(ctx, id, g.Map{
+			"status":     model.OfflineTxStatusPending,
+			"hash":       hash,
+			"error":      err.Error(),
+			"updated_at": time.Now().Unix(),
+		}); updateErr != nil {
+			g.Log().Warningf(ctx, "reset envelope %d failed: %v", id, updateErr)
+		}
+		return "", err
+	}
+	markNonceSent(ctx, reservation, hash)
+
+	//5.保存交易记录
+	transaction := &model.Transaction{
+		UserId:       offline.UserId,
+		FromAddress:  offline.FromAddress,
+		ToAddress:    offline.ToAddress,
+		Amount:       offline.Amount,
+		TokenAddress: offline.TokenAddress,
+		Hash:         hash,
+		Direction:    model.TransactionDirectionOut,
+		Nonce:        tx.Nonce(),
+		GasLimit:     tx.Gas(),
+		Data:         common.Bytes2Hex(tx.Data()),
+		Status:       model.TransactionStatusPending,
+		CreatedAt:    time.Now().Unix(),
+		UpdatedAt:    time.Now().Unix(),
+	}
+	if offline.TokenAddress == "" {
+		transaction.LogIndex = model.NativeLogIndex
+	}
+	applySignedTx(transaction, tx)
+	if err := dao.Transaction.Insert(ctx, transaction); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// summarizeTransaction 生成交易可读摘要, 解码代币转账与已登记合约的调用参数
+func summarizeTransaction(ctx context.Context, client *ethclient.Client, chainId uint64, to *common.Address, value *big.Int, data []byte, gasLimit uint64, fees *model.TxFees) *model.TxSummary {
+	summary := &model.TxSummary{}
+
+	//1.最高网络费用
+	nativeMeta, err := tokenMeta(ctx, client, chainId, "")
+	if err != nil {
+		nativeMeta = &model.TokenMeta{Symbol: defaultNativeSymbol, Decimals: defaultNativeDecimals}
+	}
+	feePerGas := fees.MaxFeePerGas
+	if fees.Legacy {
+		feePerGas = fees.GasPrice
+	}
+	summary.MaxNetworkFee = newTokenAmount("", nativeMeta, new(big.Int).Mul(feePerGas, new(big.Int).SetUint64(gasLimit)))
+	nativeAmount := newTokenAmount("", nativeMeta, value)
+
+	//2.按交易类型解码
+	switch {
+	case to == nil:
+		summary.Action = model.TxActionContractCreation
+		summary.Description = "Deploy contract"
+	case len(data) == 0:
+		summary.Action = model.TxActionNativeTransfer
+		summary.Recipient = to.Hex()
+		summary.Amount = nativeAmount
+		summary.Description = fmt.Sprintf("Transfer %s %s to %s", nativeAmount.Formatted, nativeAmount.Symbol, to.Hex())
+	default:
+		summary.Action = model.TxActionContractCall
+		summary.Method = hexutil.Encode(data[:min(len(data), 4)])
+		summary.Description = fmt.Sprintf("Call %s on %s", summary.Method, to.Hex())
+
+		erc20, _ := abi.JSON(strings.NewReader(token.ERC20ABI))
+		if method, args := decodeCall(&erc20, data); method != nil && method.Name == "transfer" {
+			recipient, _ := args["recipient"].(common.Address)
+			raw, _ := args["amount"].(*big.Int)
+			if meta, err := tokenMeta(ctx, client, chainId, to.Hex()); err == nil && raw != nil {
+				amount := newTokenAmount(to.Hex(), meta, raw)
+				summary.Action = model.TxActionTokenTransfer
+				summary.Recipient = recipient.Hex()
+				summary.Amount = amount
+				summary.Method = method.Sig
+				summary.Args = formatArgs(args)
+				summary.Description = fmt.Sprintf("Transfer %s %s to %s", amount.Formatted, amount.Symbol, recipient.Hex())
+				return summary
+			}
+		}
+		if method, args := decodeCall(contractABI(ctx, chainId, to.Hex()), data); method != nil {
+			summary.Method = method.Sig
+			summary.Args = formatArgs(args)
+			summary.Description = fmt.Sprintf("Call %s on %s", method.Sig, to.Hex())
+		}
+		if value.Sign() > 0 {
+			summary.Amount = nativeAmount
+			summary.Description += fmt.Sprintf(" with %s %s", nativeAmount.Formatted, nativeAmount.Symbol)
+		}
+	}
+	return summary
+}
+
+// decodeCall 按ABI解码调用数据, 无法识别时返回nil
+func decodeCall(parsed *abi.ABI, data []byte) (*abi.Method, map[string]interface{}) {
+	if parsed == nil || len(data) < 4 {
+		return nil, nil
+	}
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		return nil, nil
+	}
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, data[4:]); err != nil {
+		return nil, nil
+	}
+	return method, args
+}
+
+// formatArgs 将调用参数转换为字符串, 便于在离线设备上展示
+func formatArgs(args map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(args))
+	for name, arg := range args {
+		switch v := arg.(type) {
+		case common.Address:
+			result[name] = v.Hex()
+		case []byte:
+			result[name] = hexutil.Encode(v)
+		default:
+			result[name] = fmt.Sprint(v)
+		}
+	}
+	return result
+}
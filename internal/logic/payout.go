@@ -0,0 +1,640 @@
+package logic
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/amountx"
+	"go-wallet-defi/internal/pkg/contracts/disperse"
+	"go-wallet-defi/internal/pkg/contracts/token"
+)
+
+const (
+	defaultPayoutMaxRows         = 5000 // 单个任务最大行数
+	defaultPayoutDisperseBatch   = 200  // Disperse模式单笔交易最大收款数
+	defaultPayoutBatchesPerRound = 50   // 每轮每个任务最多发送的交易数
+	defaultPayoutJobsPerRound    = 20   // 每轮处理的任务数
+	defaultPayoutApproveTimeout  = 300  // 等待授权交易确认的超时(秒)
+)
+
+type PayoutLogic struct{}
+
+// Create 解析并校验全部输入行, 任一行无效时返回全部行错误且不创建任务
+func (s *PayoutLogic) Create(ctx context.Context, params *model.PayoutParams) (*model.PayoutJob, []*model.PayoutRowError, error) {
+	//1.获取客户端与钱包
+	client, chainId, err := chainClient(ctx, params.ChainId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !common.IsHexAddress(params.From) {
+		return nil, nil, fmt.Errorf("invalid from address %q", params.From)
+	}
+	wallet, err := dao.Wallet.GetByAddress(ctx, params.From)
+	if err != nil {
+		return nil, nil, err
+	}
+	if wallet == nil {
+		return nil, nil, errors.New("wallet not found")
+	}
+	mode := strings.ToUpper(params.Mode)
+	if mode == "" {
+		mode = model.PayoutModeSequential
+	}
+	if mode != model.PayoutModeSequential && mode != model.PayoutModeDisperse {
+		return nil, nil, fmt.Errorf("unsupported payout mode %q", params.Mode)
+	}
+
+	//2.解析输入
+	rows := params.Rows
+	if params.Csv != "" {
+		if len(rows) > 0 {
+			return nil, nil, errors.New("csv and rows are mutually exclusive")
+		}
+		if rows, err = parsePayoutCsv(params.Csv); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil, errors.New("no payout rows")
+	}
+	maxRows := g.Cfg().MustGet(ctx, "payout.maxRows", defaultPayoutMaxRows).Int()
+	if len(rows) > maxRows {
+		return nil, nil, fmt.Errorf("too many payout rows: %d > %d", len(rows), maxRows)
+	}
+
+	//3.逐行校验地址与金额
+	var (
+		now       = time.Now().Unix()
+		items     = make([]*model.PayoutItem, 0, len(rows))
+		rowErrors = make([]*model.PayoutRowError, 0)
+		totals    = make(map[string]*big.Int)
+	)
+	for i, row := range rows {
+		item, err := s.validateRow(ctx, client, chainId, row)
+		if err != nil {
+			rowErrors = append(rowErrors, &model.PayoutRowError{RowNo: i + 1, Error: err.Error()})
+			continue
+		}
+		item.RowNo = i + 1
+		item.Status = model.PayoutItemStatusPending
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		items = append(items, item)
+
+		value, _ := new(big.Int).SetString(item.Amount, 10)
+		if totals[item.TokenAddress] == nil {
+			totals[item.TokenAddress] = new(big.Int)
+		}
+		totals[item.TokenAddress].Add(totals[item.TokenAddress], value)
+	}
+	if len(rowErrors) > 0 {
+		return nil, rowErrors, nil
+	}
+
+	//4.校验余额, 原生代币余额不含手续费
+	from := common.HexToAddress(params.From)
+	for tokenAddress, total := range totals {
+		balance, err := payoutBalance(ctx, client, tokenAddress, from)
+		if err != nil {
+			return nil, nil, err
+		}
+		if balance.Cmp(total) < 0 {
+			meta, err := tokenMeta(ctx, client, chainId, tokenAddress)
+			if err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("insufficient %s balance: need %s, have %s",
+				meta.Symbol, newTokenAmount(tokenAddress, meta, total).Formatted, newTokenAmount(tokenAddress, meta, balance).Formatted)
+		}
+	}
+
+	//5.保存任务与明细行
+	job := &model.PayoutJob{
+		UserId:      wallet.UserId,
+		ChainId:     chainId,
+		FromAddress: from.Hex(),
+		Mode:        mode,
+		Total:       len(items),
+		Status:      model.PayoutJobStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := dao.Payout.CreateJob(ctx, job, items); err != nil {
+		return nil, nil, err
+	}
+	return job, nil, nil
+}
+
+// Detail 获取任务及明细行
+func (s *PayoutLogic) Detail(ctx context.Context, id uint64) (*model.PayoutJob, []*model.PayoutItem, error) {
+	job, err := dao.Payout.GetJob(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job == nil {
+		return nil, nil, errors.New("payout job not found")
+	}
+	items, err := dao.Payout.GetItems(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, items, nil
+}
+
+// GetJobs 获取用户的批量付款任务列表
+func (s *PayoutLogic) GetJobs(ctx context.Context, userId uint64, page, pageSize int) ([]*model.PayoutJob, int, error) {
+	return dao.Payout.GetJobList(ctx, userId, page, pageSize)
+}
+
+// Poll 推进待执行与执行中的任务, 进程中断后从数据库中的批次状态继续执行
+func (s *PayoutLogic) Poll(ctx context.Context) error {
+	limit := g.Cfg().MustGet(ctx, "payout.jobsPerRound", defaultPayoutJobsPerRound).Int()
+	jobs, err := dao.Payout.GetActiveJobs(ctx, limit)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := s.runJob(ctx, job); err != nil {
+			g.Log().Warningf(ctx, "run payout job %d failed: %v", job.Id, err)
+		}
+	}
+	return nil
+}
+
+// runJob 规划交易批次, 发送待发送批次并汇总明细行状态
+func (s *PayoutLogic) runJob(ctx context.Context, job *model.PayoutJob) error {
+	client, _, err := chainClient(ctx, job.ChainId)
+	if err != nil {
+		return err
+	}
+
+	//1.首次执行时规划交易批次
+	if job.Status == model.PayoutJobStatusPending {
+		if err := s.plan(ctx, job); err != nil {
+			return err
+		}
+	}
+	batches, err := dao.Payout.GetBatches(ctx, job.Id)
+	if err != nil {
+		return err
+	}
+	items, err := dao.Payout.GetItems(ctx, job.Id)
+	if err != nil {
+		return err
+	}
+	byBatch := make(map[uint64][]*model.PayoutItem)
+	for _, item := range items {
+		byBatch[item.BatchId] = append(byBatch[item.BatchId], item)
+	}
+
+	//2.已签名未确认广播的批次(进程中断)按原始交易恢复
+	for _, batch := range batches {
+		if batch.Status == model.PayoutBatchStatusSigned {
+			if err := s.resumeBatch(ctx, client, job, batch); err != nil {
+				return err
+			}
+		}
+	}
+
+	//3.发送待发送批次
+	sent := 0
+	perRound := g.Cfg().MustGet(ctx, "payout.batchesPerRound", defaultPayoutBatchesPerRound).Int()
+	for _, batch := range batches {
+		if batch.Status != model.PayoutBatchStatusPending {
+			continue
+		}
+		if sent >= perRound {
+			break
+		}
+		if err := s.sendBatch(ctx, client, job, batch, byBatch[batch.Id], batches); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	//4.汇总状态
+	return s.settle(ctx, job)
+}
+
+// plan 按执行方式将明细行拆分为交易批次
+func (s *PayoutLogic) plan(ctx context.Context, job *model.PayoutJob) error {
+	items, err := dao.Payout.GetItems(ctx, job.Id)
+	if err != nil {
+		return err
+	}
+
+	//1.逐笔模式每行一笔交易, Disperse模式按代币分组并按批次大小拆分
+	var groups [][]*model.PayoutItem
+	if job.Mode == model.PayoutModeDisperse {
+		size := g.Cfg().MustGet(ctx, "payout.disperseBatch", defaultPayoutDisperseBatch).Int()
+		order := make([]string, 0)
+		byToken := make(map[string][]*model.PayoutItem)
+		for _, item := range items {
+			if _, ok := byToken[item.TokenAddress]; !ok {
+				order = append(order, item.TokenAddress)
+			}
+			byToken[item.TokenAddress] = append(byToken[item.TokenAddress], item)
+		}
+		for _, tokenAddress := range order {
+			list := byToken[tokenAddress]
+			for start := 0; start < len(list); start += size {
+				end := start + size
+				if end > len(list) {
+					end = len(list)
+				}
+				groups = append(groups, list[start:end])
+			}
+		}
+	} else {
+		for _, item := range items {
+			groups = append(groups, []*model.PayoutItem{item})
+		}
+	}
+
+	//2.保存批次
+	now := time.Now().Unix()
+	batches := make([]*model.PayoutBatch, 0, len(groups))
+	itemIds := make([][]uint64, 0, len(groups))
+	for _, group := range groups {
+		ids := make([]uint64, 0, len(group))
+		for _, item := range group {
+			ids = append(ids, item.Id)
+		}
+		batches = append(batches, &model.PayoutBatch{
+			JobId:        job.Id,
+			ChainId:      job.ChainId,
+			TokenAddress: group[0].TokenAddress,
+			Status:       model.PayoutBatchStatusPending,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		})
+		itemIds = append(itemIds, ids)
+	}
+	_, err = dao.Payout.PlanBatches(ctx, job.Id, batches, itemIds)
+	return err
+}
+
+// sendBatch 构建并签名批次交易, 先保存原始交易再广播, 保证中断后可按原交易恢复而不会重复付款
+func (s *PayoutLogic) sendBatch(ctx context.Context, client *ethclient.Client, job *model.PayoutJob, batch *model.PayoutBatch, items []*model.PayoutItem, batches []*model.PayoutBatch) error {
+	if len(items) == 0 {
+		return s.failBatch(ctx, batch, errors.New("batch has no items"))
+	}
+
+	//1.构建交易目标、金额与数据
+	to, value, data, err := s.buildCall(ctx, client, job, batch, items)
+	if err != nil {
+		return s.failBatch(ctx, batch, err)
+	}
+
+	//2.Disperse代币分发需先授权
+	if job.Mode == model.PayoutModeDisperse && !isNativeToken(batch.TokenAddress) {
+		if err := s.ensureAllowance(ctx, client, job, batch, batches, *to); err != nil {
+			return err
+		}
+	}
+
+	//3.估算费用与gas, 执行失败(如余额不足)的批次直接标记失败
+	fees, err := estimateFees(ctx, client, nil)
+	if err != nil {
+		return err
+	}
+	gasLimit := uint64(21000)
+	if len(data) > 0 {
+		if gasLimit, err = estimateGas(ctx, client, job.FromAddress, to, value, data); err != nil {
+			return s.failBatch(ctx, batch, err)
+		}
+		gasLimit = gasLimit * 12 / 10
+	}
+
+	//4.分配nonce并签名
+	reservation, err := reserveNonce(ctx, client, job.FromAddress)
+	if err != nil {
+		return err
+	}
+	signedTx, err := func() (*types.Transaction, error) {
+		tx, err := newTransaction(ctx, client, reservation.Nonce, to, value, gasLimit, data, fees)
+		if err != nil {
+			return nil, err
+		}
+		return signTransaction(ctx, client, job.FromAddress, tx)
+	}()
+	if err != nil {
+		releaseNonce(ctx, reservation)
+		return err
+	}
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		releaseNonce(ctx, reservation)
+		return err
+	}
+
+	//5.保存已签名交易, 其他执行者已处理该批次时放弃
+	ok, err := dao.Payout.MarkBatchSigned(ctx, batch.Id, g.Map{
+		"nonce":          reservation.Nonce,
+		"reservation_id": reservation.Id,
+		"hash":           signedTx.Hash().Hex(),
+		"raw_tx":         hexutil.Encode(raw),
+	})
+	if err != nil || !ok {
+		releaseNonce(ctx, reservation)
+		return err
+	}
+
+	//6.广播
+	return s.broadcastBatch(ctx, client, batch, signedTx, reservation)
+}
+
+// resumeBatch 恢复已签名未确认广播的批次, 交易已在节点中时直接标记已广播, 否则重新广播原始交易
+func (s *PayoutLogic) resumeBatch(ctx context.Context, client *ethclient.Client, job *model.PayoutJob, batch *model.PayoutBatch) error {
+	raw, err := hexutil.Decode(batch.RawTx)
+	if err != nil {
+		return s.failBatch(ctx, batch, fmt.Errorf("invalid raw transaction: %w", err))
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return s.failBatch(ctx, batch, fmt.Errorf("invalid raw transaction: %w", err))
+	}
+	reservation := &model.NonceReservation{Id: batch.ReservationId, ChainId: batch.ChainId, Address: job.FromAddress, Nonce: batch.Nonce}
+	return s.broadcastBatch(ctx, client, batch, tx, reservation)
+}
+
+// broadcastBatch 广播已保存的签名交易; 发送报错时按哈希确认交易是否已进入节点, 确认未发出才标记失败
+func (s *PayoutLogic) broadcastBatch(ctx context.Context, client *ethclient.Client, batch *model.PayoutBatch, tx *types.Transaction, reservation *model.NonceReservation) error {
+	hash := tx.Hash().Hex()
+	if sendErr := client.SendTransaction(ctx, tx); sendErr != nil {
+		_, _, err := client.TransactionByHash(ctx, tx.Hash())
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			// 节点不可用时保持已签名状态, 下一轮重新广播
+			return fmt.Errorf("send payout batch %d failed: %w", batch.Id, sendErr)
+		}
+		if errors.Is(err, ethereum.NotFound) {
+			// nonce已被其他交易使用时不可释放
+			if !strings.Contains(strings.ToLower(sendErr.Error()), "nonce too low") {
+				releaseNonce(ctx, reservation)
+			}
+			return s.failBatch(ctx, batch, sendErr)
+		}
+	}
+
+	markNonceSent(ctx, reservation, hash)
+	batch.Status = model.PayoutBatchStatusSent
+	return dao.Payout.UpdateBatch(ctx, batch.Id, g.Map{
+		"status": model.PayoutBatchStatusSent,
+		"hash":   hash,
+	})
+}
+
+// failBatch 标记批次失败
+func (s *PayoutLogic) failBatch(ctx context.Context, batch *model.PayoutBatch, cause error) error {
+	batch.Status = model.PayoutBatchStatusFailed
+	return dao.Payout.UpdateBatch(ctx, batch.Id, g.Map{
+		"status": model.PayoutBatchStatusFailed,
+		"error":  cause.Error(),
+	})
+}
+
+// buildCall 构建批次交易的目标地址、金额与调用数据
+func (s *PayoutLogic) buildCall(ctx context.Context, client *ethclient.Client, job *model.PayoutJob, batch *model.PayoutBatch, items []*model.PayoutItem) (*common.Address, *big.Int, []byte, error) {
+	recipients := make([]common.Address, 0, len(items))
+	values := make([]*big.Int, 0, len(items))
+	total := new(big.Int)
+	for _, item := range items {
+		value, ok := new(big.Int).SetString(item.Amount, 10)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("invalid amount %q of row %d", item.Amount, item.RowNo)
+		}
+		recipients = append(recipients, common.HexToAddress(item.ToAddress))
+		values = append(values, value)
+		total.Add(total, value)
+	}
+	native := isNativeToken(batch.TokenAddress)
+
+	//1.逐笔转账
+	if job.Mode != model.PayoutModeDisperse {
+		if native {
+			return &recipients[0], values[0], nil, nil
+		}
+		tokenAddress := common.HexToAddress(batch.TokenAddress)
+		erc20, err := token.NewERC20(tokenAddress, client)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		data, err := erc20.PackTransfer(recipients[0], values[0])
+		return &tokenAddress, big.NewInt(0), data, err
+	}
+
+	//2.Disperse批量分发
+	contract, err := disperse.NewDisperse(payoutDisperseAddress(ctx, job.ChainId))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	address := contract.Address()
+	if native {
+		data, err := contract.PackDisperseEther(recipients, values)
+		return &address, total, data, err
+	}
+	data, err := contract.PackDisperseToken(common.HexToAddress(batch.TokenAddress), recipients, values)
+	return &address, big.NewInt(0), data, err
+}
+
+// ensureAllowance 授权额度不足以覆盖该代币全部待发送批次时, 按剩余总额授权Disperse合约并等待确认
+func (s *PayoutLogic) ensureAllowance(ctx context.Context, client *ethclient.Client, job *model.PayoutJob, batch *model.PayoutBatch, batches []*model.PayoutBatch, spender common.Address) error {
+	//1.统计该代币待发送批次的总额
+	items, err := dao.Payout.GetItems(ctx, job.Id)
+	if err != nil {
+		return err
+	}
+	pending := make(map[uint64]bool)
+	for _, b := range batches {
+		if b.TokenAddress == batch.TokenAddress && b.Status == model.PayoutBatchStatusPending {
+			pending[b.Id] = true
+		}
+	}
+	required := new(big.Int)
+	for _, item := range items {
+		if pending[item.BatchId] {
+			value, _ := new(big.Int).SetString(item.Amount, 10)
+			if value != nil {
+				required.Add(required, value)
+			}
+		}
+	}
+
+	//2.检查授权额度
+	tokenAddress := common.HexToAddress(batch.TokenAddress)
+	erc20, err := token.NewERC20(tokenAddress, client)
+	if err != nil {
+		return err
+	}
+	allowance, err := erc20.Allowance(common.HexToAddress(job.FromAddress), spender)
+	if err != nil {
+		return err
+	}
+	if allowance.Cmp(required) >= 0 {
+		return nil
+	}
+
+	//3.授权并等待确认
+	data, err := erc20.PackApprove(spender, required)
+	if err != nil {
+		return err
+	}
+	hash, err := sendTransaction(ctx, client, job.FromAddress, tokenAddress.Hex(), big.NewInt(0), data)
+	if err != nil {
+		return fmt.Errorf("approve %s for disperse failed: %w", tokenAddress.Hex(), err)
+	}
+	timeout := g.Cfg().MustGet(ctx, "payout.approveTimeout", defaultPayoutApproveTimeout).Int64()
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	receipt, err := waitTransaction(waitCtx, client, hash)
+	if err != nil {
+		return fmt.Errorf("wait approve %s failed: %w", hash, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("approve transaction %s reverted", hash)
+	}
+	return nil
+}
+
+// settle 将批次状态回写到明细行, 全部批次终结后汇总任务结果
+func (s *PayoutLogic) settle(ctx context.Context, job *model.PayoutJob) error {
+	batches, err := dao.Payout.GetBatches(ctx, job.Id)
+	if err != nil {
+		return err
+	}
+
+	//1.回写明细行
+	done := true
+	for _, batch := range batches {
+		var status int
+		data := g.Map{"hash": batch.Hash}
+		switch batch.Status {
+		case model.PayoutBatchStatusSent:
+			status = model.PayoutItemStatusSubmitted
+			done = false
+		case model.PayoutBatchStatusConfirmed:
+			status = model.PayoutItemStatusConfirmed
+		case model.PayoutBatchStatusFailed:
+			status = model.PayoutItemStatusFailed
+			data["error"] = batch.Error
+		default:
+			done = false
+			continue
+		}
+		if err := dao.Payout.UpdateItemsByBatch(ctx, batch.Id, status, data); err != nil {
+			return err
+		}
+	}
+
+	//2.更新进度
+	succeeded, err := dao.Payout.CountItems(ctx, job.Id, model.PayoutItemStatusConfirmed)
+	if err != nil {
+		return err
+	}
+	failed, err := dao.Payout.CountItems(ctx, job.Id, model.PayoutItemStatusFailed)
+	if err != nil {
+		return err
+	}
+	data := g.Map{"succeeded": succeeded, "failed": failed}
+	if done {
+		switch {
+		case failed == 0:
+			data["status"] = model.PayoutJobStatusCompleted
+		case succeeded == 0:
+			data["status"] = model.PayoutJobStatusFailed
+		default:
+			data["status"] = model.PayoutJobStatusPartial
+		}
+	}
+	return dao.Payout.UpdateJob(ctx, job.Id, data)
+}
+
+// validateRow 校验收款地址、代币地址与金额, 金额按代币精度换算为最小单位
+func (s *PayoutLogic) validateRow(ctx context.Context, client *ethclient.Client, chainId uint64, row model.PayoutRow) (*model.PayoutItem, error) {
+	address := strings.TrimSpace(row.Address)
+	if !common.IsHexAddress(address) || common.HexToAddress(address) == (common.Address{}) {
+		return nil, fmt.Errorf("invalid address %q", row.Address)
+	}
+	tokenAddress := strings.TrimSpace(row.Token)
+	if isNativeToken(tokenAddress) {
+		tokenAddress = ""
+	} else if !common.IsHexAddress(tokenAddress) {
+		return nil, fmt.Errorf("invalid token address %q", row.Token)
+	} else {
+		tokenAddress = common.HexToAddress(tokenAddress).Hex()
+	}
+	amount, err := parseAmount(ctx, client, chainId, tokenAddress, row.Amount, amountx.UnitDecimal)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Raw == "0" {
+		return nil, errors.New("amount must be greater than zero")
+	}
+	return &model.PayoutItem{
+		ToAddress:    common.HexToAddress(address).Hex(),
+		TokenAddress: tokenAddress,
+		Amount:       amount.Raw,
+	}, nil
+}
+
+// parsePayoutCsv 解析 address,amount,token 格式的CSV, 首行为表头时跳过, token列可省略
+func parsePayoutCsv(content string) ([]model.PayoutRow, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows := make([]model.PayoutRow, 0)
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		if line == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		row := model.PayoutRow{Address: record[0]}
+		if len(record) > 1 {
+			row.Amount = record[1]
+		}
+		if len(record) > 2 {
+			row.Token = record[2]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// payoutBalance 获取付款地址的原生代币或代币余额
+func payoutBalance(ctx context.Context, client *ethclient.Client, tokenAddress string, owner common.Address) (*big.Int, error) {
+	if isNativeToken(tokenAddress) {
+		return client.BalanceAt(ctx, owner, nil)
+	}
+	erc20, err := token.NewERC20(common.HexToAddress(tokenAddress), client)
+	if err != nil {
+		return nil, err
+	}
+	return erc20.BalanceOf(owner)
+}
+
+// payoutDisperseAddress 获取链上Disperse合约地址, 未配置时使用默认部署地址
+func payoutDisperseAddress(ctx context.Context, chainId uint64) common.Address {
+	address := g.Cfg().MustGet(ctx, fmt.Sprintf("payout.disperse.%d", chainId), disperse.DefaultAddress).String()
+	return common.HexToAddress(address)
+}
@@ -0,0 +1,306 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+)
+
+const (
+	defaultReindexJobsPerRound = 5   // 每轮处理的任务数
+	defaultReindexStaleTimeout = 300 // 执行中超过该秒数未更新视为执行实例中断
+)
+
+type ReindexLogic struct{}
+
+// Create 校验目标与区块范围并创建重新索引任务, 由worker的reindex任务或reindex命令执行
+func (s *ReindexLogic) Create(ctx context.Context, params *model.ReindexParams) (*model.ReindexJob, error) {
+	//1.确定链, 指定合约时以合约所在链为准
+	chainId := params.ChainId
+	if params.ContractId > 0 {
+		contract, err := dao.Contract.GetById(ctx, params.ContractId)
+		if err != nil {
+			return nil, err
+		}
+		if contract == nil {
+			return nil, errors.New("contract not found")
+		}
+		if chainId > 0 && chainId != contract.ChainId {
+			return nil, fmt.Errorf("contract %d is deployed on chain %d", contract.Id, contract.ChainId)
+		}
+		chainId = contract.ChainId
+	} else if params.Event != "" {
+		return nil, errors.New("event requires a contract")
+	}
+	if chainId == 0 {
+		return nil, errors.New("chain or contract is required")
+	}
+
+	//2.确认存在匹配的事件来源
+	now := time.Now().Unix()
+	job := &model.ReindexJob{
+		ChainId:    chainId,
+		ContractId: params.ContractId,
+		Event:      params.Event,
+		FromBlock:  params.FromBlock,
+		ToBlock:    params.ToBlock,
+		NextBlock:  params.FromBlock,
+		Status:     model.ReindexJobStatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	sources, err := s.sources(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("no indexed events match the target")
+	}
+
+	//3.校验区块范围, 结束区块不超过已确认高度
+	run, err := (&EventIndexerLogic{}).newRun(ctx, "", chainId)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("chain %d has no confirmed blocks yet", chainId)
+	}
+	if job.ToBlock == 0 {
+		job.ToBlock = run.safe
+	}
+	if job.ToBlock > run.safe {
+		return nil, fmt.Errorf("to block %d exceeds confirmed head %d", job.ToBlock, run.safe)
+	}
+	if job.FromBlock > job.ToBlock {
+		return nil, fmt.Errorf("from block %d is after to block %d", job.FromBlock, job.ToBlock)
+	}
+
+	if err := dao.Reindex.Insert(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Get 获取重新索引任务
+func (s *ReindexLogic) Get(ctx context.Context, id uint64) (*model.ReindexJob, error) {
+	job, err := dao.Reindex.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, errors.New("reindex job not found")
+	}
+	return job, nil
+}
+
+// GetList 获取重新索引任务列表
+func (s *ReindexLogic) GetList(ctx context.Context, page, pageSize int) ([]*model.ReindexJob, int, error) {
+	return dao.Reindex.GetList(ctx, page, pageSize)
+}
+
+// Cancel 取消待执行或执行中的任务, 已处理的区块段不回滚
+func (s *ReindexLogic) Cancel(ctx context.Context, id uint64) error {
+	ok, err := dao.Reindex.Cancel(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("reindex job is not pending or running")
+	}
+	return nil
+}
+
+// Poll 执行待执行的任务, 并接管执行实例中断的任务
+func (s *ReindexLogic) Poll(ctx context.Context) error {
+	limit := g.Cfg().MustGet(ctx, "reindex.jobsPerRound", defaultReindexJobsPerRound).Int()
+	jobs, err := dao.Reindex.GetRunnable(ctx, s.staleBefore(ctx), limit)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if _, err := s.Run(ctx, job.Id); err != nil && ctx.Err() == nil {
+			g.Log().Warningf(ctx, "run reindex job %d failed: %v", job.Id, err)
+		}
+	}
+	return nil
+}
+
+// Run 在当前进程中认领并执行任务直到完成, 返回任务的最终状态;
+// ctx结束时任务交还为待执行, 由其他实例从已处理的区块之后继续
+func (s *ReindexLogic) Run(ctx context.Context, id uint64) (*model.ReindexJob, error) {
+	//1.认领任务
+	runner := reindexRunner()
+	ok, err := dao.Reindex.Claim(ctx, id, runner, s.staleBefore(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("reindex job is not pending or is running on another instance")
+	}
+	job, err := dao.Reindex.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.执行, 记录失败或交还任务
+	runErr := s.execute(ctx, job, runner)
+	saveCtx := context.WithoutCancel(ctx)
+	var data g.Map
+	switch {
+	case ctx.Err() != nil:
+		data = g.Map{"status": model.ReindexJobStatusPending, "runner": ""}
+	case runErr != nil:
+		data = g.Map{"status": model.ReindexJobStatusFailed, "error": runErr.Error(), "finished_at": time.Now().Unix()}
+	}
+	if data != nil {
+		if _, err := dao.Reindex.UpdateRunning(saveCtx, id, runner, data); err != nil {
+			return nil, err
+		}
+	}
+	job, err = dao.Reindex.Get(saveCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	return job, runErr
+}
+
+// execute 按回放模式分段处理任务的区块范围, 每段完成后保存进度; 任务被取消或由其他实例接管时停止
+func (s *ReindexLogic) execute(ctx context.Context, job *model.ReindexJob, runner string) error {
+	//1.加载事件来源与链高度
+	indexer := &EventIndexerLogic{}
+	sources, err := s.sources(ctx, job)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return errors.New("no indexed events match the target")
+	}
+	run, err := indexer.newRun(ctx, "", job.ChainId)
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		return fmt.Errorf("chain %d has no confirmed blocks yet", job.ChainId)
+	}
+
+	//2.分段处理, 处理函数只写入可重复写入的数据, 不发起解锁等外部操作
+	replay := withReplay(ctx)
+	logs, failures := job.Logs, job.Failures
+	for next := job.NextBlock; next <= job.ToBlock; {
+		found, end, _, err := indexer.filterLogs(ctx, run, sources, next, job.ToBlock)
+		if err != nil {
+			return err
+		}
+		journal, failed, err := indexer.dispatch(replay, run, sources, found)
+		if err != nil {
+			return err
+		}
+		if err := dao.Indexer.InsertLogs(ctx, journal); err != nil {
+			return err
+		}
+		logs += uint64(len(found))
+		failures += uint64(failed)
+		next = end + 1
+
+		ok, err := dao.Reindex.UpdateRunning(ctx, job.Id, runner, g.Map{
+			"next_block": next,
+			"logs":       logs,
+			"failures":   failures,
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			g.Log().Warningf(ctx, "reindex job %d cancelled or taken over, stopping at block %d", job.Id, next)
+			return nil
+		}
+		g.Log().Infof(ctx, "reindex job %d processed blocks %d-%d of %d, %d logs, %d failures",
+			job.Id, job.FromBlock, end, job.ToBlock, logs, failures)
+	}
+
+	//3.完成
+	_, err = dao.Reindex.UpdateRunning(ctx, job.Id, runner, g.Map{
+		"status":      model.ReindexJobStatusCompleted,
+		"finished_at": time.Now().Unix(),
+	})
+	return err
+}
+
+// sources 选择任务目标的事件来源: 指定合约时为该合约的NFT与合约事件来源, 否则为链上全部来源(包括跨链桥);
+// 指定事件时只保留该事件的处理函数
+func (s *ReindexLogic) sources(ctx context.Context, job *model.ReindexJob) ([]*EventSource, error) {
+	var topics map[common.Hash]bool
+	if job.Event != "" {
+		contract, err := dao.Contract.GetById(ctx, job.ContractId)
+		if err != nil {
+			return nil, err
+		}
+		if contract == nil {
+			return nil, errors.New("contract not found")
+		}
+		parsed, err := abi.JSON(strings.NewReader(contract.ABI))
+		if err != nil {
+			return nil, err
+		}
+		event, ok := parsed.Events[job.Event]
+		if !ok {
+			return nil, fmt.Errorf("event %s not found in contract %d abi", job.Event, contract.Id)
+		}
+		topics = map[common.Hash]bool{event.ID: true}
+	}
+
+	groups := make([]string, 0, len(eventGroups))
+	for group := range eventGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	indexer := &EventIndexerLogic{}
+	var sources []*EventSource
+	for _, group := range groups {
+		loaded, err := indexer.loadSources(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range loaded {
+			if source.ChainId != job.ChainId {
+				continue
+			}
+			if job.ContractId > 0 && source.Name != fmt.Sprintf("%s:%d", group, job.ContractId) {
+				continue
+			}
+			if topics != nil {
+				if source = source.only(topics); source == nil {
+					continue
+				}
+			}
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+// staleBefore 执行中的任务在该时间之后未更新视为执行实例中断
+func (s *ReindexLogic) staleBefore(ctx context.Context) int64 {
+	timeout := g.Cfg().MustGet(ctx, "reindex.staleTimeout", defaultReindexStaleTimeout).Int64()
+	return time.Now().Unix() - timeout
+}
+
+// reindexRunner 当前进程的实例标识
+func reindexRunner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "reindex"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
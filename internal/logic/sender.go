@@ -0,0 +1,223 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/ethclientx"
+	"go-wallet-defi/internal/pkg/simulate"
+)
+
+// signTransaction 通过签名器签名交易
+func signTransaction(ctx context.Context, client *ethclient.Client, from string, tx *types.Transaction) (*types.Transaction, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txSigner, err := getSigner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return txSigner.SignTx(ctx, common.HexToAddress(from), tx, chainID)
+}
+
+// sendTransaction 构建、签名并发送交易; 模拟执行中存在未上链的授权交易时不模拟, 返回授权交易的模拟结果
+func sendTransaction(ctx context.Context, client *ethclient.Client, from, to string, value *big.Int, data []byte) (string, error) {
+	toAddress := common.HexToAddress(to)
+
+	if approvals := simulate.Approvals(ctx); len(approvals) > 0 && !isApproval(data) {
+		return "", &simulate.DryRunError{Result: &model.SimulationResult{
+			From:      common.HexToAddress(from).Hex(),
+			To:        toAddress.Hex(),
+			Value:     value.String(),
+			Approvals: approvals,
+		}}
+	}
+
+	fees, err := estimateFees(ctx, client, nil)
+	if err != nil {
+		return "", err
+	}
+
+	gasLimit, err := estimateGas(ctx, client, from, &toAddress, value, data)
+	if err != nil {
+		return "", err
+	}
+
+	signedTx, err := broadcastTransaction(ctx, client, from, &toAddress, value, gasLimit, data, fees)
+	if err != nil {
+		return "", err
+	}
+
+	return signedTx.Hash().Hex(), nil
+}
+
+// sendApproval 发送代币授权交易并等待确认;
+// 模拟执行时授权交易不上链, 记录其模拟结果后返回, 依赖该授权的主交易返回未模拟的结果及授权交易的模拟结果
+func sendApproval(ctx context.Context, client *ethclient.Client, from, token string, data []byte) error {
+	hash, err := sendTransaction(ctx, client, from, token, big.NewInt(0), data)
+	if simulation, ok := simulate.AsDryRun(err); ok {
+		simulate.AddApproval(ctx, simulation)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = waitTransaction(ctx, client, hash)
+	return err
+}
+
+// isApproval 是否为ERC20 approve调用, 多个授权交易之间互不依赖
+func isApproval(data []byte) bool {
+	return len(data) >= 4 && bytes.Equal(data[:4], approveSelector)
+}
+
+// approveSelector ERC20 approve(address,uint256)的方法选择器
+var approveSelector = crypto.Keccak256([]byte("approve(address,uint256)"))[:4]
+
+// broadcastTransaction 模拟执行通过后从nonce管理器分配nonce, 构建、签名并发送交易, 未能广播时释放nonce;
+// 模拟执行(dry run)时返回携带模拟结果的DryRunError
+func broadcastTransaction(ctx context.Context, client *ethclient.Client, from string, to *common.Address, value *big.Int, gasLimit uint64, data []byte, fees *model.TxFees) (*types.Transaction, error) {
+	simulation, err := simulateTransaction(ctx, client, from, to, value, gasLimit, data)
+	if simulate.IsDryRun(ctx) && simulation != nil {
+		return nil, &simulate.DryRunError{Result: simulation}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := reserveNonce(ctx, client, from)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := func() (*types.Transaction, error) {
+		tx, err := newTransaction(ctx, client, reservation.Nonce, to, value, gasLimit, data, fees)
+		if err != nil {
+			return nil, err
+		}
+		return signTransaction(ctx, client, from, tx)
+	}()
+	if err != nil {
+		releaseNonce(ctx, reservation)
+		return nil, err
+	}
+
+	if err := sendSignedTransaction(ctx, client, signedTx, reservation); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// sendSignedTransaction 广播已签名交易并标记nonce已发送; 发送报错(如超时)时按哈希确认交易是否已进入节点,
+// 已进入时视为发送成功, 确认未进入时释放nonce(nonce已被其他交易使用时除外), 节点不可用无法确认时保留nonce
+func sendSignedTransaction(ctx context.Context, client *ethclient.Client, tx *types.Transaction, reservation *model.NonceReservation) error {
+	if sendErr := client.SendTransaction(ctx, tx); sendErr != nil {
+		_, _, err := client.TransactionByHash(ctx, tx.Hash())
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) && !isNonceTooLow(sendErr) {
+				releaseNonce(ctx, reservation)
+			}
+			return sendErr
+		}
+		g.Log().Warningf(ctx, "send transaction %s returned %v, but the node already has it", tx.Hash().Hex(), sendErr)
+	}
+	markNonceSent(ctx, reservation, tx.Hash().Hex())
+	return nil
+}
+
+// isNonceTooLow 发送错误是否为nonce已被其他交易使用
+func isNonceTooLow(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// isAlreadyKnown 发送错误是否为节点已有该交易
+func isAlreadyKnown(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "already known")
+}
+
+// newTransaction 按费用构建交易, 默认为EIP-1559动态费用交易, 链不支持时构建legacy交易; to为空时为合约创建
+func newTransaction(ctx context.Context, client *ethclient.Client, nonce uint64, to *common.Address, value *big.Int, gasLimit uint64, data []byte, fees *model.TxFees) (*types.Transaction, error) {
+	if fees.Legacy {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: fees.GasPrice,
+			Gas:      gasLimit,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}), nil
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: fees.MaxPriorityFeePerGas,
+		GasFeeCap: fees.MaxFeePerGas,
+		Gas:       gasLimit,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	}), nil
+}
+
+// applySignedTx 将已签名交易的链ID、类型与费用写入交易记录
+func applySignedTx(transaction *model.Transaction, tx *types.Transaction) {
+	transaction.ChainId = tx.ChainId().Uint64()
+	transaction.TxType = int(tx.Type())
+	transaction.GasPrice = tx.GasPrice().String()
+	if tx.Type() == types.DynamicFeeTxType {
+		transaction.MaxFeePerGas = tx.GasFeeCap().String()
+		transaction.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	}
+}
+
+// waitTransaction 等待交易确认
+func waitTransaction(ctx context.Context, client *ethclient.Client, hash string) (*types.Receipt, error) {
+	for {
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(hash))
+		if err != nil {
+			if err == ethereum.NotFound {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+			return nil, err
+		}
+		return receipt, nil
+	}
+}
+
+// chainClient 按链注册表获取链客户端, chainId为0时使用 chains.default 配置的默认链
+func chainClient(ctx context.Context, chainId uint64) (*ethclient.Client, uint64, error) {
+	if chainId == 0 {
+		chainId = g.Cfg().MustGet(ctx, "chains.default").Uint64()
+		if chainId == 0 {
+			return nil, 0, errors.New("chain id is required")
+		}
+	}
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return client, chainId, nil
+}
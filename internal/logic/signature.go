@@ -0,0 +1,183 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/ethclientx"
+)
+
+const (
+	VerifyMethodEcrecover = "ecrecover"
+	VerifyMethodEIP1271   = "eip1271"
+)
+
+// eip1271MagicValue isValidSignature(bytes32,bytes)返回的魔术值
+var eip1271MagicValue = common.FromHex("0x1626ba7e")
+
+// eip1271ABI EIP-1271合约钱包签名验证接口
+const eip1271ABI = `[{"inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+
+type SignatureLogic struct{}
+
+// SignMessage 使用托管钱包按EIP-191(personal_sign)签名消息
+func (s *SignatureLogic) SignMessage(ctx context.Context, address, message string) (string, error) {
+	txSigner, err := getSigner(ctx)
+	if err != nil {
+		return "", err
+	}
+	signature, err := txSigner.SignText(ctx, common.HexToAddress(address), messageBytes(message))
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(signature), nil
+}
+
+// SignTypedData 使用托管钱包按EIP-712签名结构化数据
+func (s *SignatureLogic) SignTypedData(ctx context.Context, address, typedDataJson string) (signature, hash string, err error) {
+	//1.解析结构化数据
+	typedData, err := parseTypedData(typedDataJson)
+	if err != nil {
+		return "", "", err
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return "", "", err
+	}
+
+	//2.签名
+	txSigner, err := getSigner(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	sig, err := txSigner.SignTypedData(ctx, common.HexToAddress(address), typedData)
+	if err != nil {
+		return "", "", err
+	}
+
+	return hexutil.Encode(sig), hexutil.Encode(digest), nil
+}
+
+// Verify 验证签名, message与typedData二选一
+// EOA签名通过ecrecover恢复地址; 恢复地址与期望地址不一致且期望地址为合约时, 通过eth_call调用EIP-1271 isValidSignature验证
+func (s *SignatureLogic) Verify(ctx context.Context, chainId uint64, address, message, typedDataJson, signature string) (*model.SignatureVerification, error) {
+	//1.计算消息哈希
+	var hash []byte
+	if typedDataJson != "" {
+		typedData, err := parseTypedData(typedDataJson)
+		if err != nil {
+			return nil, err
+		}
+		hash, _, err = apitypes.TypedDataAndHash(typedData)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		hash = accounts.TextHash(messageBytes(message))
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return nil, errors.New("invalid signature encoding")
+	}
+
+	result := &model.SignatureVerification{
+		Method: VerifyMethodEcrecover,
+		Hash:   hexutil.Encode(hash),
+	}
+
+	//2.ecrecover恢复签名地址
+	if recovered, err := recoverAddress(hash, sig); err == nil {
+		result.Recovered = recovered.Hex()
+		if address == "" || recovered == common.HexToAddress(address) {
+			result.Valid = address != ""
+			return result, nil
+		}
+	}
+	if address == "" {
+		return result, nil
+	}
+
+	//3.合约钱包按EIP-1271验证
+	if chainId == 0 {
+		return result, nil
+	}
+	client, err := ethclientx.GetClientByChainId(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	contractAddress := common.HexToAddress(address)
+	code, err := client.CodeAt(ctx, contractAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return result, nil
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(eip1271ABI))
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack("isValidSignature", common.BytesToHash(hash), sig)
+	if err != nil {
+		return nil, err
+	}
+	result.Method = VerifyMethodEIP1271
+	output, err := client.CallContract(ctx, ethereum.CallMsg{
+		To:   &contractAddress,
+		Data: data,
+	}, nil)
+	if err != nil {
+		// 合约回滚视为签名无效
+		return result, nil
+	}
+	result.Valid = len(output) >= 4 && bytes.Equal(output[:4], eip1271MagicValue)
+
+	return result, nil
+}
+
+// recoverAddress 从65字节签名恢复地址, 兼容V为0/1与27/28
+func recoverAddress(hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid signature length")
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// parseTypedData 解析EIP-712结构化数据JSON
+func parseTypedData(typedDataJson string) (apitypes.TypedData, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal([]byte(typedDataJson), &typedData); err != nil {
+		return typedData, errors.New("invalid typed data")
+	}
+	return typedData, nil
+}
+
+// messageBytes 与personal_sign保持一致: 0x开头的合法十六进制按字节处理, 否则按UTF-8文本处理
+func messageBytes(message string) []byte {
+	if data, err := hexutil.Decode(message); err == nil {
+		return data
+	}
+	return []byte(message)
+}
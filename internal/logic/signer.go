@@ -0,0 +1,119 @@
+package logic
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/pkg/cryptox"
+	"go-wallet-defi/internal/pkg/signer"
+)
+
+var ErrWatchOnlyWallet = errors.New("watch-only wallet cannot sign")
+
+var (
+	txSigner     signer.Signer
+	txSignerErr  error
+	txSignerOnce sync.Once
+)
+
+// getSigner 获取签名器, 由配置 signer.type 决定: local(默认) / web3signer / clef
+func getSigner(ctx context.Context) (signer.Signer, error) {
+	txSignerOnce.Do(func() {
+		signerType := g.Cfg().MustGet(ctx, "signer.type", "local").String()
+		switch signerType {
+		case "local":
+			txSigner = signer.NewLocalSigner(loadWalletKey)
+		case signer.ProtocolWeb3Signer, signer.ProtocolClef:
+			url := g.Cfg().MustGet(ctx, "signer.url").String()
+			txSigner, txSignerErr = signer.NewRemoteSigner(ctx, url, signerType)
+		default:
+			txSignerErr = errors.New("unsupported signer type: " + signerType)
+		}
+		if txSignerErr == nil {
+			txSigner = &walletGuardSigner{Signer: txSigner}
+		}
+	})
+	return txSigner, txSignerErr
+}
+
+// walletGuardSigner 在签名前拒绝观察钱包, 覆盖本地与远程签名器
+type walletGuardSigner struct {
+	signer.Signer
+}
+
+func (s *walletGuardSigner) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := checkSignable(ctx, address); err != nil {
+		return nil, err
+	}
+	return s.Signer.SignTx(ctx, address, tx, chainID)
+}
+
+func (s *walletGuardSigner) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	if err := checkSignable(ctx, address); err != nil {
+		return nil, err
+	}
+	return s.Signer.SignHash(ctx, address, hash)
+}
+
+func (s *walletGuardSigner) SignText(ctx context.Context, address common.Address, data []byte) ([]byte, error) {
+	if err := checkSignable(ctx, address); err != nil {
+		return nil, err
+	}
+	return s.Signer.SignText(ctx, address, data)
+}
+
+func (s *walletGuardSigner) SignTypedData(ctx context.Context, address common.Address, typedData apitypes.TypedData) ([]byte, error) {
+	if err := checkSignable(ctx, address); err != nil {
+		return nil, err
+	}
+	return s.Signer.SignTypedData(ctx, address, typedData)
+}
+
+// checkSignable 检查地址是否允许签名, 观察钱包一律拒绝
+func checkSignable(ctx context.Context, address common.Address) error {
+	wallet, err := dao.Wallet.GetByAddress(ctx, address.Hex())
+	if err != nil {
+		return err
+	}
+	if wallet != nil && wallet.IsWatchOnly() {
+		return ErrWatchOnlyWallet
+	}
+	return nil
+}
+
+// loadWalletKey 从钱包表加载并解密私钥
+func loadWalletKey(ctx context.Context, address common.Address) (*ecdsa.PrivateKey, error) {
+	wallet, err := dao.Wallet.GetByAddress(ctx, address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, signer.ErrAccountNotFound
+	}
+	if wallet.IsWatchOnly() {
+		return nil, ErrWatchOnlyWallet
+	}
+	if wallet.PrivateKey == "" {
+		return nil, signer.ErrAccountNotFound
+	}
+
+	plaintext, err := cryptox.Decrypt(wallet.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := hexutil.Decode(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ToECDSA(keyBytes)
+}
@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/simulate"
+)
+
+// simulateTransaction 在pending区块上通过eth_call模拟执行交易, 回滚时返回解码后的结构化错误
+func simulateTransaction(ctx context.Context, client *ethclient.Client, from string, to *common.Address, value *big.Int, gasLimit uint64, data []byte) (*model.SimulationResult, error) {
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	result := &model.SimulationResult{
+		From:      common.HexToAddress(from).Hex(),
+		Value:     value.String(),
+		GasLimit:  gasLimit,
+		Simulated: true,
+	}
+
+	//1.解析调用方法
+	var parsed *abi.ABI
+	if to != nil {
+		result.To = to.Hex()
+		if chainId, err := client.ChainID(ctx); err == nil {
+			parsed = contractABI(ctx, chainId.Uint64(), to.Hex())
+		}
+		if parsed != nil && len(data) >= 4 {
+			if method, err := parsed.MethodById(data[:4]); err == nil {
+				result.Method = method.Name
+			}
+		}
+	}
+
+	//2.模拟执行
+	output, err := client.PendingCallContract(ctx, ethereum.CallMsg{
+		From:  common.HexToAddress(from),
+		To:    to,
+		Gas:   gasLimit,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		revertData, ok := simulate.RevertData(err)
+		if !ok {
+			return nil, err
+		}
+		result.Revert = simulate.DecodeRevert(revertData, parsed)
+		return result, &simulate.CallError{To: result.To, Method: result.Method, Revert: result.Revert}
+	}
+
+	result.Success = true
+	result.ReturnData = hexutil.Encode(output)
+	return result, nil
+}
+
+// estimateGas 估算gas, 交易会回滚时返回解码后的回滚原因, 模拟执行时返回模拟结果
+func estimateGas(ctx context.Context, client *ethclient.Client, from string, to *common.Address, value *big.Int, data []byte) (uint64, error) {
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  common.HexToAddress(from),
+		To:    to,
+		Value: value,
+		Data:  data,
+	})
+	if err == nil {
+		return gasLimit, nil
+	}
+	if _, ok := simulate.RevertData(err); !ok {
+		return 0, err
+	}
+
+	result, simErr := simulateTransaction(ctx, client, from, to, value, 0, data)
+	if result == nil || simErr == nil {
+		return 0, err
+	}
+	if simulate.IsDryRun(ctx) {
+		return 0, &simulate.DryRunError{Result: result}
+	}
+	return 0, simErr
+}
+
+// contractABI 从合约表读取链上已登记合约的ABI, 未登记时返回nil
+func contractABI(ctx context.Context, chainId uint64, address string) *abi.ABI {
+	contract, err := dao.Contract.GetByAddress(ctx, chainId, address)
+	if err != nil || contract == nil {
+		return nil
+	}
+	parsed, err := abi.JSON(strings.NewReader(contract.ABI))
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -0,0 +1,361 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+)
+
+const (
+	defaultTrackerBatchSize     = 200 // 每轮处理的记录数
+	defaultTrackerConfirmations = 12  // 默认确认所需区块数
+	defaultTrackerFinalityDepth = 64  // 节点不支持finalized标签时视为最终确定的确认数
+	defaultTrackerDroppedAfter  = 600 // 节点中查不到交易超过该秒数视为被丢弃
+)
+
+// trackedSources 需跟踪交易状态的业务表
+var trackedSources = []*model.TrackedSource{
+	{
+		Name: "transaction", Table: "transaction", HashField: "hash", ChainField: "chain_id", StatusField: "status",
+		PendingStatus: model.TransactionStatusPending, SuccessStatus: model.TransactionStatusSuccess, FailedStatus: model.TransactionStatusFailed,
+		BlockFields: true,
+	},
+	{
+		Name: "dex_trade", Table: "dex_trade", HashField: "hash", ChainField: "chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.TransactionStatusPending, SuccessStatus: model.TransactionStatusSuccess, FailedStatus: model.TransactionStatusFailed,
+	},
+	{
+		Name: "liquidity", Table: "liquidity", HashField: "hash", ChainField: "chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.TransactionStatusPending, SuccessStatus: model.TransactionStatusSuccess, FailedStatus: model.TransactionStatusFailed,
+	},
+	{
+		Name: "lending", Table: "lending", HashField: "hash", ChainField: "chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.TransactionStatusPending, SuccessStatus: model.TransactionStatusSuccess, FailedStatus: model.TransactionStatusFailed,
+	},
+	{
+		Name: "yield_farm", Table: "yield_farm", HashField: "hash", ChainField: "chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.TransactionStatusPending, SuccessStatus: model.TransactionStatusSuccess, FailedStatus: model.TransactionStatusFailed,
+	},
+	{
+		Name: "vault", Table: "vault", HashField: "hash", ChainField: "chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.TransactionStatusPending, SuccessStatus: model.TransactionStatusSuccess, FailedStatus: model.TransactionStatusFailed,
+	},
+	// 跨链锁定交易, 成功后的状态由跨链桥事件推进
+	{
+		Name: "cross_transfer", Table: "cross_transfer", HashField: "from_hash", ChainField: "from_chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.CrossTransferStatusPending, SuccessStatus: -1, FailedStatus: model.CrossTransferStatusFailed,
+	},
+	// 跨链解锁交易
+	{
+		Name: "cross_transfer_unlock", Table: "cross_transfer", HashField: "to_hash", ChainField: "to_chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.CrossTransferStatusLocked, SuccessStatus: -1, FailedStatus: model.CrossTransferStatusFailed,
+	},
+	// 批量付款交易批次, 明细行状态由批量付款任务汇总
+	{
+		Name: "payout_batch", Table: "payout_batch", HashField: "hash", ChainField: "chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.PayoutBatchStatusSent, SuccessStatus: model.PayoutBatchStatusConfirmed, FailedStatus: model.PayoutBatchStatusFailed,
+	},
+	{
+		Name: "contract_call", Table: "contract_call", HashField: "hash", ChainField: "chain_id", StatusField: "status", ErrorField: "error",
+		PendingStatus: model.TransactionStatusPending, SuccessStatus: model.TransactionStatusSuccess, FailedStatus: model.TransactionStatusFailed,
+	},
+}
+
+type TrackerLogic struct{}
+
+// trackerHead 单条链本轮跟踪使用的区块高度与确认参数
+type trackerHead struct {
+	chainId       uint64
+	number        uint64 // 最新区块高度
+	finalized     uint64 // 最终确定区块高度, 0表示节点不支持
+	required      uint64 // 确认所需区块数
+	finalityDepth uint64 // 视为最终确定的确认数
+	droppedAfter  int64  // 视为丢弃的秒数
+}
+
+// Poll 登记各业务表待确认交易并推进跟踪状态
+func (s *TrackerLogic) Poll(ctx context.Context) error {
+	batchSize := g.Cfg().MustGet(ctx, "tracker.batchSize", defaultTrackerBatchSize).Int()
+
+	//1.登记待跟踪交易
+	s.enroll(ctx, batchSize)
+
+	//2.获取未终结的跟踪记录
+	list, err := dao.Tracker.GetActive(ctx, batchSize)
+	if err != nil {
+		return err
+	}
+
+	//3.按链推进状态
+	byChain := make(map[uint64][]*model.TxTracking)
+	for _, t := range list {
+		byChain[t.ChainId] = append(byChain[t.ChainId], t)
+	}
+	for chainId, items := range byChain {
+		if err := s.advanceChain(ctx, chainId, items); err != nil {
+			g.Log().Warningf(ctx, "track transactions on chain %d failed: %v", chainId, err)
+		}
+	}
+	return nil
+}
+
+// TrackHash 立即推进指定交易的跟踪状态, chainId为0时使用默认链
+func (s *TrackerLogic) TrackHash(ctx context.Context, chainId uint64, hash string) error {
+	_, chainId, err := chainClient(ctx, chainId)
+	if err != nil {
+		return err
+	}
+	list, err := dao.Tracker.GetByHash(ctx, chainId, hash)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		s.enroll(ctx, g.Cfg().MustGet(ctx, "tracker.batchSize", defaultTrackerBatchSize).Int())
+		if list, err = dao.Tracker.GetByHash(ctx, chainId, hash); err != nil {
+			return err
+		}
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("transaction %s is not tracked", hash)
+	}
+	return s.advanceChain(ctx, chainId, list)
+}
+
+// enroll 登记各业务表中尚未跟踪的待确认交易
+func (s *TrackerLogic) enroll(ctx context.Context, limit int) {
+	now := time.Now().Unix()
+	for _, source := range trackedSources {
+		rows, err := dao.Tracker.GetPendingRows(ctx, source, limit)
+		if err != nil {
+			g.Log().Warningf(ctx, "load pending %s failed: %v", source.Name, err)
+			continue
+		}
+		for _, row := range rows {
+			_, chainId, err := chainClient(ctx, row.ChainId)
+			if err != nil {
+				g.Log().Warningf(ctx, "resolve chain for %s %d failed: %v", source.Name, row.Id, err)
+				continue
+			}
+			err = dao.Tracker.Enroll(ctx, &model.TxTracking{
+				ChainId:   chainId,
+				TxHash:    row.Hash,
+				Source:    source.Name,
+				SourceId:  row.Id,
+				State:     model.TxStatePending,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+			if err != nil {
+				g.Log().Warningf(ctx, "enroll %s %d failed: %v", source.Name, row.Id, err)
+			}
+		}
+	}
+}
+
+// advanceChain 推进同一条链上的跟踪记录
+func (s *TrackerLogic) advanceChain(ctx context.Context, chainId uint64, list []*model.TxTracking) error {
+	client, _, err := chainClient(ctx, chainId)
+	if err != nil {
+		return err
+	}
+
+	//1.获取最新区块与最终确定区块
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	head := &trackerHead{
+		chainId:       chainId,
+		number:        header.Number.Uint64(),
+		required:      defaultTrackerConfirmations,
+		finalityDepth: g.Cfg().MustGet(ctx, "tracker.finalityDepth", defaultTrackerFinalityDepth).Uint64(),
+		droppedAfter:  g.Cfg().MustGet(ctx, "tracker.droppedAfter", defaultTrackerDroppedAfter).Int64(),
+	}
+	if finalized, err := client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber))); err == nil {
+		head.finalized = finalized.Number.Uint64()
+	}
+
+	//2.确认数: 链配置 > 全局配置
+	if v := g.Cfg().MustGet(ctx, "tracker.confirmations").Uint64(); v > 0 {
+		head.required = v
+	}
+	if chain, err := dao.Chain.GetByChainId(ctx, chainId); err == nil && chain != nil && chain.Confirmations > 0 {
+		head.required = uint64(chain.Confirmations)
+	}
+
+	//3.逐笔推进
+	for _, t := range list {
+		if err := s.advance(ctx, client, head, t); err != nil {
+			g.Log().Warningf(ctx, "track %s %s failed: %v", t.Source, t.TxHash, err)
+		}
+	}
+	return nil
+}
+
+// advance 推进单笔交易: pending → included → confirmed(N) → finalized, 或 failed/dropped/replaced
+func (s *TrackerLogic) advance(ctx context.Context, client *ethclient.Client, head *trackerHead, t *model.TxTracking) error {
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(t.TxHash))
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+
+	//1.未打包: 已打包的交易查不到收据说明所在区块被重组
+	if receipt == nil {
+		if t.State != model.TxStatePending {
+			g.Log().Warningf(ctx, "tx %s reorged out of block %d", t.TxHash, t.BlockNumber)
+			return s.transition(ctx, client, t, model.TxStatePending, g.Map{
+				"block_number":  0,
+				"block_hash":    "",
+				"confirmations": 0,
+			}, nil, "")
+		}
+		return s.checkPending(ctx, client, head, t)
+	}
+
+	//2.已打包, 计算确认数
+	blockNumber := receipt.BlockNumber.Uint64()
+	if t.BlockHash != "" && t.BlockHash != receipt.BlockHash.Hex() {
+		g.Log().Warningf(ctx, "tx %s moved from block %s to %s by reorg", t.TxHash, t.BlockHash, receipt.BlockHash.Hex())
+	}
+	var confirmations uint64
+	if head.number >= blockNumber {
+		confirmations = head.number - blockNumber + 1
+	}
+	data := g.Map{
+		"block_number":  blockNumber,
+		"block_hash":    receipt.BlockHash.Hex(),
+		"confirmations": confirmations,
+	}
+
+	//3.判断状态
+	state := model.TxStateIncluded
+	if (head.finalized > 0 && blockNumber <= head.finalized) || confirmations >= head.finalityDepth {
+		state = model.TxStateFinalized
+	} else if confirmations >= head.required {
+		state = model.TxStateConfirmed
+	}
+	if state != model.TxStateIncluded && receipt.Status == types.ReceiptStatusFailed {
+		return s.transition(ctx, client, t, model.TxStateFailed, data, receipt, "execution reverted")
+	}
+	return s.transition(ctx, client, t, state, data, receipt, "")
+}
+
+// checkPending 检查未打包交易是否被替换或丢弃
+func (s *TrackerLogic) checkPending(ctx context.Context, client *ethclient.Client, head *trackerHead, t *model.TxTracking) error {
+	//1.交易仍在节点中, 记录发送地址与nonce
+	tx, _, err := client.TransactionByHash(ctx, common.HexToHash(t.TxHash))
+	if err == nil {
+		data := g.Map{}
+		if t.FromAddress == "" {
+			if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+				data["from_address"] = from.Hex()
+				data["nonce"] = tx.Nonce()
+			}
+		}
+		return dao.Tracker.Update(ctx, t.Id, data)
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+
+	//2.nonce已被链上其他交易使用, 视为被替换
+	if t.FromAddress != "" {
+		latest, err := client.NonceAt(ctx, common.HexToAddress(t.FromAddress), nil)
+		if err != nil {
+			return err
+		}
+		if latest > t.Nonce {
+			replacement, err := dao.Tracker.GetReplacement(ctx, t.ChainId, t.FromAddress, t.Nonce, t.TxHash)
+			if err != nil {
+				return err
+			}
+			if replacement != nil {
+				return s.transition(ctx, client, t, model.TxStateReplaced, g.Map{"replaced_by": replacement.TxHash}, nil, "replaced by "+replacement.TxHash)
+			}
+			return s.transition(ctx, client, t, model.TxStateReplaced, nil, nil, fmt.Sprintf("nonce %d used by another transaction", t.Nonce))
+		}
+	}
+
+	//3.超时仍查不到交易, 视为被丢弃并释放nonce
+	if time.Now().Unix()-t.CreatedAt < head.droppedAfter {
+		return dao.Tracker.Update(ctx, t.Id, g.Map{})
+	}
+	if err := dao.Nonce.ReleaseByHash(ctx, t.ChainId, t.TxHash); err != nil {
+		return err
+	}
+	return s.transition(ctx, client, t, model.TxStateDropped, nil, nil, "dropped from mempool")
+}
+
+// transition 更新跟踪状态, 对应的业务状态变化时回写业务表
+func (s *TrackerLogic) transition(ctx context.Context, client *ethclient.Client, t *model.TxTracking, state int, data g.Map, receipt *types.Receipt, errMsg string) error {
+	if data == nil {
+		data = g.Map{}
+	}
+	data["state"] = state
+	data["error"] = errMsg
+	if err := dao.Tracker.Update(ctx, t.Id, data); err != nil {
+		return err
+	}
+
+	//1.业务状态未变化时无需回写
+	source := findTrackedSource(t.Source)
+	if source == nil {
+		return nil
+	}
+	status := sourceStatus(source, state)
+	if status < 0 || status == sourceStatus(source, t.State) {
+		return nil
+	}
+
+	//2.回写业务表
+	update := g.Map{source.StatusField: status}
+	if source.ErrorField != "" {
+		update[source.ErrorField] = errMsg
+	}
+	if source.BlockFields {
+		update["block_number"] = 0
+		update["block_time"] = 0
+		if receipt != nil && state != model.TxStatePending {
+			header, err := client.HeaderByHash(ctx, receipt.BlockHash)
+			if err != nil {
+				return err
+			}
+			update["block_number"] = receipt.BlockNumber.Int64()
+			update["block_time"] = header.Time
+		}
+	}
+	return dao.Tracker.UpdateSource(ctx, source.Table, t.SourceId, update)
+}
+
+// sourceStatus 跟踪状态对应的业务状态
+func sourceStatus(source *model.TrackedSource, state int) int {
+	switch state {
+	case model.TxStateConfirmed, model.TxStateFinalized:
+		return source.SuccessStatus
+	case model.TxStateFailed, model.TxStateDropped, model.TxStateReplaced:
+		return source.FailedStatus
+	default:
+		return source.PendingStatus
+	}
+}
+
+// findTrackedSource 根据名称查找业务表
+func findTrackedSource(name string) *model.TrackedSource {
+	for _, source := range trackedSources {
+		if source.Name == name {
+			return source
+		}
+	}
+	return nil
+}
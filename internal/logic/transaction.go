@@ -2,93 +2,75 @@ package logic
 
 import (
 	"context"
-	"github.com/ethereum/go-ethereum"
+	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	_ "github.com/ethereum/go-ethereum/ethclient"
-	"github.com/gogf/gf/v2/frame/g"
 	"go-wallet-defi/internal/dao"
 	"go-wallet-defi/internal/model"
 	"go-wallet-defi/internal/pkg/contracts/ercx20"
-	"go-wallet-defi/internal/pkg/ethclientx"
 	"math/big"
 	"time"
 )
 
 type TransactionLogic struct{}
 
-// TransferEth 转账ETH
-func (s *TransactionLogic) TransferEth(ctx context.Context, from, to, amount string, gasPrice string, gasLimit uint64) (string, error) {
-	client := ethclientx.GetClient(ctx)
+// TransferEth 转账链原生代币
+func (s *TransactionLogic) TransferEth(ctx context.Context, chainId uint64, from, to, amount string, feeParams *model.TxFeeParams, gasLimit uint64) (string, error) {
+	client, _, err := chainClient(ctx, chainId)
+	if err != nil {
+		return "", err
+	}
 	//1.解析地址
-	fromAddress := common.HexToAddress(from)
 	toAddress := common.HexToAddress(to)
 
-	//2.获取nonce
-	nonce, err := client.PendingNonceAt(ctx, fromAddress)
-	if err != nil {
-		return "", err
+	//2.解析金额
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid amount %q", amount)
 	}
-	//3.解析金额
-	value := new(big.Int)
-	value.SetString(amount, 10)
 
-	//4.获取gas价格
-	var gp *big.Int
-	if gasPrice == "" {
-		gp, err = client.SuggestGasPrice(ctx)
-		if err != nil {
-			return "", err
-		}
-	} else {
-		gp = new(big.Int)
-		gp.SetString(gasPrice, 10)
+	//3.估算交易费用
+	fees, err := estimateFees(ctx, client, feeParams)
+	if err != nil {
+		return "", err
 	}
 
-	//5.使用默认gas限制
+	//4.使用默认gas限制
 	if gasLimit == 0 {
 		gasLimit = 21000
 	}
 
-	//6.构建交易
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, gp, nil)
-	//7.获取chainID
-	chainID, err := client.NetworkID(ctx)
-	if err != nil {
-		return "", err
-	}
-	//8.获取私钥
+	//5.获取钱包
 	wallet, err := dao.Wallet.GetByAddress(ctx, from)
-	privateKey, err := crypto.HexToECDSA(wallet.PrivateKey)
 	if err != nil {
 		return "", err
 	}
-	//9.签名交易
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		return "", err
+	if wallet == nil {
+		return "", errors.New("wallet not found")
 	}
-	//10.发送交易
-	err = client.SendTransaction(ctx, signedTx)
+	//6.分配nonce, 签名并发送交易
+	signedTx, err := broadcastTransaction(ctx, client, from, &toAddress, value, gasLimit, nil, fees)
 	if err != nil {
 		return "", err
 	}
 
-	//11.保存交易记录
+	//7.保存交易记录
 	transaction := &model.Transaction{
 		UserId:      wallet.UserId,
 		FromAddress: from,
 		ToAddress:   to,
 		Amount:      amount,
 		Hash:        signedTx.Hash().Hex(),
-		Nonce:       nonce,
-		GasPrice:    gp.String(),
+		LogIndex:    model.NativeLogIndex,
+		Direction:   model.TransactionDirectionOut,
+		Nonce:       signedTx.Nonce(),
 		GasLimit:    gasLimit,
 		Status:      0,
 		CreatedAt:   time.Now().Unix(),
 		UpdatedAt:   time.Now().Unix(),
 	}
+	applySignedTx(transaction, signedTx)
 
 	err = dao.Transaction.Insert(ctx, transaction)
 	if err != nil {
@@ -99,8 +81,11 @@ func (s *TransactionLogic) TransferEth(ctx context.Context, from, to, amount str
 }
 
 // TransferToken 转账代币
-func (s *TransactionLogic) TransferToken(ctx context.Context, from, to, amount, token string, gasPrice string, gasLimit uint64) (string, error) {
-	client := ethclientx.GetClient(ctx)
+func (s *TransactionLogic) TransferToken(ctx context.Context, chainId uint64, from, to, amount, token string, feeParams *model.TxFeeParams, gasLimit uint64) (string, error) {
+	client, _, err := chainClient(ctx, chainId)
+	if err != nil {
+		return "", err
+	}
 
 	// 解析合约地址
 	tokenAddress := common.HexToAddress(token)
@@ -112,38 +97,25 @@ func (s *TransactionLogic) TransferToken(ctx context.Context, from, to, amount,
 	}
 
 	// 解析转账参数
-	fromAddress := common.HexToAddress(from)
 	toAddress := common.HexToAddress(to)
-	value := new(big.Int)
-	value.SetString(amount, 10)
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid amount %q", amount)
+	}
 
-	// 获取nonce
-	nonce, err := client.PendingNonceAt(ctx, fromAddress)
+	// 估算交易费用
+	fees, err := estimateFees(ctx, client, feeParams)
 	if err != nil {
 		return "", err
 	}
 
-	// 获取gas价格
-	var gp *big.Int
-	if gasPrice == "" {
-		gp, err = client.SuggestGasPrice(ctx)
-		if err != nil {
-			return "", err
-		}
-	} else {
-		gp = new(big.Int)
-		gp.SetString(gasPrice, 10)
-	}
-
-	// 获取私钥
+	// 获取钱包
 	wallet, err := dao.Wallet.GetByAddress(ctx, from)
 	if err != nil {
 		return "", err
 	}
-
-	privateKey, err := crypto.HexToECDSA(wallet.PrivateKey)
-	if err != nil {
-		return "", err
+	if wallet == nil {
+		return "", errors.New("wallet not found")
 	}
 
 	// 构建交易数据
@@ -154,11 +126,7 @@ func (s *TransactionLogic) TransferToken(ctx context.Context, from, to, amount,
 
 	// 预估gas
 	if gasLimit == 0 {
-		gasLimit, err = client.EstimateGas(ctx, ethereum.CallMsg{
-			From: fromAddress,
-			To:   &tokenAddress,
-			Data: data,
-		})
+		gasLimit, err = estimateGas(ctx, client, from, &tokenAddress, nil, data)
 		if err != nil {
 			return "", err
 		}
@@ -166,23 +134,8 @@ func (s *TransactionLogic) TransferToken(ctx context.Context, from, to, amount,
 		gasLimit = gasLimit * 12 / 10
 	}
 
-	// 构建交易
-	tx := types.NewTransaction(nonce, tokenAddress, big.NewInt(0), gasLimit, gp, data)
-
-	// 获取chainID
-	chainID, err := client.NetworkID(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	// 签名交易
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		return "", err
-	}
-
-	// 发送交易
-	err = client.SendTransaction(ctx, signedTx)
+	// 分配nonce, 签名并发送交易
+	signedTx, err := broadcastTransaction(ctx, client, from, &tokenAddress, big.NewInt(0), gasLimit, data, fees)
 	if err != nil {
 		return "", err
 	}
@@ -195,14 +148,15 @@ func (s *TransactionLogic) TransferToken(ctx context.Context, from, to, amount,
 		Amount:       amount,
 		TokenAddress: token,
 		Hash:         signedTx.Hash().Hex(),
-		Nonce:        nonce,
-		GasPrice:     gp.String(),
+		Direction:    model.TransactionDirectionOut,
+		Nonce:        signedTx.Nonce(),
 		GasLimit:     gasLimit,
 		Data:         common.Bytes2Hex(data),
 		Status:       0,
 		CreatedAt:    time.Now().Unix(),
 		UpdatedAt:    time.Now().Unix(),
 	}
+	applySignedTx(transaction, signedTx)
 
 	err = dao.Transaction.Insert(ctx, transaction)
 	if err != nil {
@@ -217,29 +171,7 @@ func (s *TransactionLogic) GetTransactions(ctx context.Context, address string,
 	return dao.Transaction.GetList(ctx, address, page, pageSize)
 }
 
-// UpdateTransactionStatus 更新交易状态
-func (s *TransactionLogic) UpdateTransactionStatus(ctx context.Context, hash string) error {
-	client := ethclientx.GetClient(ctx)
-
-	// 获取交易收据
-	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(hash))
-	if err != nil {
-		return err
-	}
-
-	// 更新状态
-	data := g.Map{
-		"status":       receipt.Status,
-		"block_number": receipt.BlockNumber.Int64(),
-		"updated_at":   time.Now().Unix(),
-	}
-
-	// 获取区块时间
-	block, err := client.BlockByNumber(ctx, receipt.BlockNumber)
-	if err != nil {
-		return err
-	}
-	data["block_time"] = block.Time()
-
-	return dao.Transaction.Update(ctx, hash, data)
+// UpdateTransactionStatus 立即推进交易的跟踪状态, 达到确认数后才更新为已确认
+func (s *TransactionLogic) UpdateTransactionStatus(ctx context.Context, chainId uint64, hash string) error {
+	return new(TrackerLogic).TrackHash(ctx, chainId, hash)
 }
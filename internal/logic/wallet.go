@@ -3,14 +3,21 @@ package logic
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/google/uuid"
 	"github.com/tyler-smith/go-bip39"
 	"go-wallet-defi/internal/dao"
 	"go-wallet-defi/internal/model"
 	"go-wallet-defi/internal/pkg/cryptox"
+	"go-wallet-defi/internal/pkg/hdwallet"
 	"go-wallet-defi/internal/service"
+	"strings"
 	"time"
 )
 
@@ -19,11 +26,6 @@ var _ service.IWallet = (*WalletLogic)(nil)
 
 type WalletLogic struct{}
 
-func (s *WalletLogic) GetBalance(ctx context.Context, address string) (string, error) {
-	//TODO implement me
-	panic("implement me")
-}
-
 func (s *WalletLogic) Create(ctx context.Context, chain string) (*model.Wallet, error) {
 	// 生成助记词
 	entropy, err := bip39.NewEntropy(128)
@@ -35,11 +37,102 @@ func (s *WalletLogic) Create(ctx context.Context, chain string) (*model.Wallet,
 		return nil, err
 	}
 
-	// 从助记词生成种子
-	//seed := bip39.NewSeed(mnemonic, "")
+	// 按BIP-44派生第0个账户
+	wallet, err := s.deriveWallet(mnemonic, chain, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保存到数据库
+	if err := dao.Wallet.Insert(ctx, wallet); err != nil {
+		return nil, err
+	}
+
+	// 返回时解密助记词(只在创建时返回明文助记词)
+	wallet.Mnemonic = mnemonic
+
+	return wallet, nil
+}
+
+// Import 导入钱包
+func (s *WalletLogic) Import(ctx context.Context, chain, mnemonic string, index uint32) (*model.Wallet, error) {
+	// 验证助记词
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
 
-	// 生成私钥
-	privateKey, err := crypto.GenerateKey()
+	// 按BIP-44派生指定账户
+	wallet, err := s.deriveWallet(mnemonic, chain, index)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查地址是否已存在
+	exists, err := dao.Wallet.CheckAddressExists(ctx, wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("wallet already exists")
+	}
+
+	// 保存到数据库
+	if err := dao.Wallet.Insert(ctx, wallet); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// Derive 从已有钱包的助记词派生第index个账户
+func (s *WalletLogic) Derive(ctx context.Context, address string, index uint32) (*model.Wallet, error) {
+	//1.获取源钱包
+	source, err := dao.Wallet.GetByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("wallet not found")
+	}
+	if source.Mnemonic == "" {
+		return nil, errors.New("wallet has no mnemonic")
+	}
+
+	//2.解密助记词
+	mnemonic, err := cryptox.Decrypt(source.Mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	//3.派生账户
+	wallet, err := s.deriveWallet(mnemonic, source.Chain, index)
+	if err != nil {
+		return nil, err
+	}
+
+	//4.检查地址是否已存在
+	exists, err := dao.Wallet.CheckAddressExists(ctx, wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("wallet already exists")
+	}
+
+	//5.保存到数据库
+	wallet.UserId = source.UserId
+	if err := dao.Wallet.Insert(ctx, wallet); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// deriveWallet 按m/44'/60'/0'/0/index派生账户并加密敏感信息
+func (s *WalletLogic) deriveWallet(mnemonic, chain string, index uint32) (*model.Wallet, error) {
+	// 派生私钥
+	path := hdwallet.AccountPath(index)
+	privateKey, err := hdwallet.DeriveFromMnemonic(mnemonic, "", path)
 	if err != nil {
 		return nil, err
 	}
@@ -66,13 +159,95 @@ func (s *WalletLogic) Create(ctx context.Context, chain string) (*model.Wallet,
 		return nil, err
 	}
 
+	return &model.Wallet{
+		Address:        address.Hex(),
+		Mnemonic:       encryptedMnemonic,
+		PrivateKey:     encryptedPrivateKey,
+		PublicKey:      hexutil.Encode(crypto.FromECDSAPub(publicKeyECDSA)),
+		DerivationPath: path,
+		Chain:          chain,
+		WalletType:     model.WalletTypeMnemonic,
+		CreatedAt:      time.Now().Unix(),
+		UpdatedAt:      time.Now().Unix(),
+	}, nil
+}
+
+// ImportPrivateKey 导入私钥
+func (s *WalletLogic) ImportPrivateKey(ctx context.Context, chain, privateKey string) (*model.Wallet, error) {
+	keyBytes, err := hexutil.Decode(ensureHexPrefix(strings.TrimSpace(privateKey)))
+	if err != nil {
+		return nil, errors.New("invalid private key")
+	}
+	key, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, errors.New("invalid private key")
+	}
+
+	return s.importKey(ctx, chain, key, model.WalletTypePrivateKey)
+}
+
+// ImportKeystore 导入V3 Keystore JSON
+func (s *WalletLogic) ImportKeystore(ctx context.Context, chain, keystoreJson, password string) (*model.Wallet, error) {
+	key, err := keystore.DecryptKey([]byte(keystoreJson), password)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.importKey(ctx, chain, key.PrivateKey, model.WalletTypeKeystore)
+}
+
+// ExportKeystore 导出V3 Keystore JSON, 使用调用方提供的密码加密
+func (s *WalletLogic) ExportKeystore(ctx context.Context, address, password string) (string, error) {
+	//1.加载私钥
+	privateKey, err := loadWalletKey(ctx, common.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+
+	//2.生成Keystore
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	key := &keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+	keyJson, err := keystore.EncryptKey(key, password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return "", err
+	}
+
+	return string(keyJson), nil
+}
+
+// importKey 保存私钥钱包
+func (s *WalletLogic) importKey(ctx context.Context, chain string, privateKey *ecdsa.PrivateKey, walletType int) (*model.Wallet, error) {
+	// 获取地址
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	// 检查地址是否已存在
+	exists, err := dao.Wallet.CheckAddressExists(ctx, address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("wallet already exists")
+	}
+
+	// 加密存储
+	encryptedPrivateKey, err := cryptox.Encrypt(hexutil.Encode(crypto.FromECDSA(privateKey)))
+	if err != nil {
+		return nil, err
+	}
+
 	wallet := &model.Wallet{
 		Address:    address.Hex(),
-		Mnemonic:   encryptedMnemonic,
 		PrivateKey: encryptedPrivateKey,
-		PublicKey:  hexutil.Encode(crypto.FromECDSAPub(publicKeyECDSA)),
+		PublicKey:  hexutil.Encode(crypto.FromECDSAPub(&privateKey.PublicKey)),
 		Chain:      chain,
-		WalletType: 1,
+		WalletType: walletType,
 		CreatedAt:  time.Now().Unix(),
 		UpdatedAt:  time.Now().Unix(),
 	}
@@ -82,40 +257,56 @@ func (s *WalletLogic) Create(ctx context.Context, chain string) (*model.Wallet,
 		return nil, err
 	}
 
-	// 返回时解密助记词(只在创建时返回明文助记词)
-	wallet.Mnemonic = mnemonic
-
 	return wallet, nil
 }
 
-// Import 导入钱包
-func (s *WalletLogic) Import(ctx context.Context, chain, mnemonic string) (*model.Wallet, error) {
-	// 验证助记词
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return nil, errors.New("invalid mnemonic")
+// ensureHexPrefix 补全0x前缀
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s
 	}
+	return "0x" + s
+}
 
-	// 从助记词生成种子
-	seed := bip39.NewSeed(mnemonic, "")
-
-	// 生成私钥
-	privateKey, err := crypto.ToECDSA(seed[:32])
+// GetList 获取钱包列表
+func (s *WalletLogic) GetList(ctx context.Context, filter *model.WalletFilter, page, pageSize int) ([]*model.Wallet, int, error) {
+	wallets, total, err := dao.Wallet.GetList(ctx, filter, page, pageSize)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	// 获取公钥
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, errors.New("failed to get public key")
+	// 批量查询本页钱包的余额, 列表只展示钱包所属链的原生代币余额
+	addresses := make([]string, 0, len(wallets))
+	for _, wallet := range wallets {
+		addresses = append(addresses, wallet.Address)
+	}
+	balances, err := s.GetBalances(ctx, addresses, "")
+	for _, wallet := range wallets {
+		wallet.Balance = "0"
+		if err != nil {
+			continue
+		}
+		for _, chainBalance := range balances[wallet.Address].Chains {
+			if chainBalance.Error == "" && (strings.EqualFold(chainBalance.Name, wallet.Chain) || strings.EqualFold(chainBalance.Native.Symbol, wallet.Chain)) {
+				wallet.Balance = chainBalance.Native.Balance
+				break
+			}
+		}
 	}
 
-	// 获取地址
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
+	return wallets, total, nil
+}
 
-	// 检查地址是否已存在
-	exists, err := dao.Wallet.CheckAddressExists(ctx, address.Hex())
+// AddWatchOnly 添加观察钱包, 仅记录地址不保存任何密钥
+func (s *WalletLogic) AddWatchOnly(ctx context.Context, chain, address, label string, tags []string, groupId uint64) (*model.Wallet, error) {
+	//1.校验地址
+	if !common.IsHexAddress(address) {
+		return nil, errors.New("invalid address")
+	}
+	checksumAddress := common.HexToAddress(address).Hex()
+
+	//2.检查地址是否已存在
+	exists, err := dao.Wallet.CheckAddressExists(ctx, checksumAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -123,30 +314,26 @@ func (s *WalletLogic) Import(ctx context.Context, chain, mnemonic string) (*mode
 		return nil, errors.New("wallet already exists")
 	}
 
-	// 加密存储
-	encryptedMnemonic, err := cryptox.Encrypt(mnemonic)
-	if err != nil {
+	//3.校验分组
+	if err := s.checkGroup(ctx, groupId); err != nil {
 		return nil, err
 	}
 
-	privateKeyBytes := crypto.FromECDSA(privateKey)
-	encryptedPrivateKey, err := cryptox.Encrypt(hexutil.Encode(privateKeyBytes))
+	//4.保存到数据库
+	tagsJson, err := json.Marshal(normalizeTags(tags))
 	if err != nil {
 		return nil, err
 	}
-
 	wallet := &model.Wallet{
-		Address:    address.Hex(),
-		Mnemonic:   encryptedMnemonic,
-		PrivateKey: encryptedPrivateKey,
-		PublicKey:  hexutil.Encode(crypto.FromECDSAPub(publicKeyECDSA)),
+		Address:    checksumAddress,
 		Chain:      chain,
-		WalletType: 2, // 私钥导入
+		WalletType: model.WalletTypeWatchOnly,
+		Label:      label,
+		Tags:       string(tagsJson),
+		GroupId:    groupId,
 		CreatedAt:  time.Now().Unix(),
 		UpdatedAt:  time.Now().Unix(),
 	}
-
-	// 保存到数据库
 	if err := dao.Wallet.Insert(ctx, wallet); err != nil {
 		return nil, err
 	}
@@ -154,24 +341,71 @@ func (s *WalletLogic) Import(ctx context.Context, chain, mnemonic string) (*mode
 	return wallet, nil
 }
 
-// GetList 获取钱包列表
-func (s *WalletLogic) GetList(ctx context.Context, page, pageSize int) ([]*model.Wallet, error) {
-	wallets, err := dao.Wallet.GetList(ctx, page, pageSize)
+// UpdateMeta 更新钱包标签名称、标签列表与分组
+func (s *WalletLogic) UpdateMeta(ctx context.Context, address, label string, tags []string, groupId uint64) error {
+	if err := s.checkGroup(ctx, groupId); err != nil {
+		return err
+	}
+	tagsJson, err := json.Marshal(normalizeTags(tags))
 	if err != nil {
+		return err
+	}
+	return dao.Wallet.UpdateMeta(ctx, address, g.Map{
+		"label":      label,
+		"tags":       string(tagsJson),
+		"group_id":   groupId,
+		"updated_at": time.Now().Unix(),
+	})
+}
+
+// CreateGroup 创建钱包分组
+func (s *WalletLogic) CreateGroup(ctx context.Context, userId uint64, name, description string) (*model.WalletGroup, error) {
+	group := &model.WalletGroup{
+		UserId:      userId,
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now().Unix(),
+		UpdatedAt:   time.Now().Unix(),
+	}
+	if err := dao.Wallet.InsertGroup(ctx, group); err != nil {
 		return nil, err
 	}
+	return group, nil
+}
 
-	// 查询每个钱包的余额
-	for _, wallet := range wallets {
-		balance, err := s.GetBalance(ctx, wallet.Address)
-		if err != nil {
-			wallet.Balance = "0"
+// GetGroups 获取钱包分组列表
+func (s *WalletLogic) GetGroups(ctx context.Context, userId uint64) ([]*model.WalletGroup, error) {
+	return dao.Wallet.GetGroups(ctx, userId)
+}
+
+// checkGroup 校验分组是否存在
+func (s *WalletLogic) checkGroup(ctx context.Context, groupId uint64) error {
+	if groupId == 0 {
+		return nil
+	}
+	group, err := dao.Wallet.GetGroupById(ctx, groupId)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return errors.New("wallet group not found")
+	}
+	return nil
+}
+
+// normalizeTags 去除空白与重复标签
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
 			continue
 		}
-		wallet.Balance = balance
+		seen[tag] = true
+		result = append(result, tag)
 	}
-
-	return wallets, nil
+	return result
 }
 
 func init() {
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/text/gstr"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+)
+
+// workerErrorLength 保存的错误信息最大长度
+const workerErrorLength = 1000
+
+type WorkerLogic struct{}
+
+// Acquire 获取或续约任务租约, 返回当前实例是否为该任务的leader
+func (s *WorkerLogic) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	return dao.Worker.AcquireLease(ctx, name, holder, time.Now().Add(ttl).Unix())
+}
+
+// Release 释放任务租约, 其他实例可立即接管
+func (s *WorkerLogic) Release(ctx context.Context, name, holder string) error {
+	return dao.Worker.ReleaseLease(ctx, name, holder)
+}
+
+// Start 记录任务开始执行
+func (s *WorkerLogic) Start(ctx context.Context, name, holder string) error {
+	return dao.Worker.UpdateJob(ctx, name, holder, g.Map{
+		"state":           model.WorkerJobStateRunning,
+		"runs":            &gdb.Counter{Field: "runs", Value: 1},
+		"last_started_at": time.Now().Unix(),
+	})
+}
+
+// Finish 记录任务执行结束, runErr不为空时累计失败次数并保存错误
+func (s *WorkerLogic) Finish(ctx context.Context, name, holder string, runErr error) error {
+	now := time.Now().Unix()
+	data := g.Map{
+		"state":            model.WorkerJobStateIdle,
+		"last_finished_at": now,
+	}
+	if runErr != nil {
+		data["failures"] = &gdb.Counter{Field: "failures", Value: 1}
+		data["last_error"] = gstr.StrLimitRune(runErr.Error(), workerErrorLength)
+		data["last_error_at"] = now
+	}
+	return dao.Worker.UpdateJob(ctx, name, holder, data)
+}
@@ -0,0 +1,16 @@
+package model
+
+// TokenAmount 按代币精度解析后的金额
+type TokenAmount struct {
+	TokenAddress string `json:"tokenAddress" description:"代币地址, 原生代币为空"`
+	Symbol       string `json:"symbol"       description:"代币符号"`
+	Decimals     int    `json:"decimals"     description:"精度"`
+	Raw          string `json:"raw"          description:"最小单位金额"`
+	Formatted    string `json:"formatted"    description:"按精度换算后的金额"`
+}
+
+// TokenMeta 代币元数据
+type TokenMeta struct {
+	Symbol   string `json:"symbol"   description:"代币符号"`
+	Decimals int    `json:"decimals" description:"精度"`
+}
@@ -9,11 +9,29 @@ type Chain struct {
 	ExplorerUrl   string `json:"explorerUrl"`   // 浏览器地址
 	RpcUrls       string `json:"rpcUrls"`       // RPC节点地址
 	BridgeAddress string `json:"bridgeAddress"` // 跨链桥合约地址
+	BridgeHeight  int64  `json:"bridgeHeight"`  // 跨链桥合约部署区块, 事件索引的起始高度
+	LegacyTx      bool   `json:"legacyTx"`      // 是否仅支持legacy交易(不支持EIP-1559)
+	Confirmations int    `json:"confirmations"` // 交易确认所需区块数, 0时使用默认值
 	Status        int    `json:"status"`        // 状态
 	CreatedAt     int64  `json:"createdAt"`     // 创建时间
 	UpdatedAt     int64  `json:"updatedAt"`     // 更新时间
 }
 
+// ChainConfig 链注册表文件中的链配置, 启动时按ChainId同步到chain表
+type ChainConfig struct {
+	Name          string        `json:"name"`          // 链名称
+	ChainId       uint64        `json:"chainId"`       // 链ID
+	Symbol        string        `json:"symbol"`        // 原生代币符号
+	Decimals      int           `json:"decimals"`      // 原生代币精度, 默认18
+	ExplorerUrl   string        `json:"explorerUrl"`   // 浏览器地址
+	RpcUrls       []interface{} `json:"rpcUrls"`       // RPC节点, 元素为地址或 {url, weight}
+	BridgeAddress string        `json:"bridgeAddress"` // 跨链桥合约地址
+	BridgeHeight  int64         `json:"bridgeHeight"`  // 跨链桥合约部署区块
+	LegacyTx      bool          `json:"legacyTx"`      // 是否仅支持legacy交易
+	Confirmations int           `json:"confirmations"` // 交易确认所需区块数
+	Enabled       *bool         `json:"enabled"`       // 是否启用, 默认启用
+}
+
 // ContractMapping 合约地址映射
 type ContractMapping struct {
 	Id          uint64 `json:"id"`          // ID
@@ -36,11 +54,20 @@ type CrossTransfer struct {
 	TokenAddress string `json:"tokenAddress"` // 代币地址
 	Amount       string `json:"amount"`       // 金额
 	Fee          string `json:"fee"`          // 手续费
-	Nonce        uint64 `json:"nonce"`        // 交易序号
+	Nonce        uint64 `json:"nonce"`        // 跨链序号, 取记录ID, 锁定与解锁事件按该序号匹配记录
 	FromHash     string `json:"fromHash"`     // 来源链交易哈希
 	ToHash       string `json:"toHash"`       // 目标链交易哈希
+	UnlockTx     string `json:"unlockTx"`     // 已签名的解锁交易(hex), 广播前保存, 用于重新广播
 	Status       int    `json:"status"`       // 状态
 	Error        string `json:"error"`        // 错误信息
 	CreatedAt    int64  `json:"createdAt"`    // 创建时间
 	UpdatedAt    int64  `json:"updatedAt"`    // 更新时间
 }
+
+// CrossTransfer 状态
+const (
+	CrossTransferStatusPending   = 0 // 待锁定
+	CrossTransferStatusLocked    = 1 // 已锁定
+	CrossTransferStatusCompleted = 3 // 已完成
+	CrossTransferStatusFailed    = 4 // 失败
+)
@@ -7,7 +7,7 @@ type Contract struct {
 	Address      string `json:"address"`      // 合约地址
 	ABI          string `json:"abi"`          // 合约ABI
 	Bytecode     string `json:"bytecode"`     // 合约字节码
-	Network      string `json:"network"`      // 网络
+	ChainId      uint64 `json:"chainId"`      // 链ID
 	DeployHash   string `json:"deployHash"`   // 部署交易哈希
 	DeployHeight int64  `json:"deployHeight"` // 部署区块高度
 	Creator      string `json:"creator"`      // 创建者地址
@@ -16,10 +16,11 @@ type Contract struct {
 	UpdatedAt    int64  `json:"updatedAt"`    // 更新时间
 }
 
-// ContractEvent 合约事件
+// ContractEvent 合约事件, 按(chainId, txHash, logIndex)唯一, 重复索引同一日志时忽略
 type ContractEvent struct {
 	Id          uint64 `json:"id"`          // 事件ID
 	ContractId  uint64 `json:"contractId"`  // 合约ID
+	ChainId     uint64 `json:"chainId"`     // 链ID
 	Name        string `json:"name"`        // 事件名称
 	Signature   string `json:"signature"`   // 事件签名
 	Topics      string `json:"topics"`      // 事件topics
@@ -36,6 +37,7 @@ type ContractEvent struct {
 type ContractCall struct {
 	Id         uint64 `json:"id"`         // 调用ID
 	ContractId uint64 `json:"contractId"` // 合约ID
+	ChainId    uint64 `json:"chainId"`    // 链ID
 	Method     string `json:"method"`     // 方法名称
 	Params     string `json:"params"`     // 调用参数
 	From       string `json:"from"`       // 调用地址
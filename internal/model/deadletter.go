@@ -0,0 +1,33 @@
+package model
+
+// EventDeadLetter 处理失败的事件日志, 按(chainId, source, txHash, logIndex)唯一; 保存日志内容以便重试处理函数
+type EventDeadLetter struct {
+	Id          uint64 `json:"id"`          // ID
+	ChainId     uint64 `json:"chainId"`     // 链ID
+	EventGroup  string `json:"eventGroup"`  // 事件索引分组
+	Source      string `json:"source"`      // 事件来源
+	Address     string `json:"address"`     // 合约地址
+	Topics      string `json:"topics"`      // 主题, 逗号分隔
+	Data        string `json:"data"`        // 日志数据(hex)
+	BlockNumber uint64 `json:"blockNumber"` // 区块高度
+	BlockHash   string `json:"blockHash"`   // 区块哈希
+	TxHash      string `json:"txHash"`      // 交易哈希
+	TxIndex     uint   `json:"txIndex"`     // 交易索引
+	LogIndex    uint   `json:"logIndex"`    // 日志索引
+	Replay      bool   `json:"replay"`      // 是否在回放模式下失败, 重试时同样不发起解锁等外部操作
+	Attempts    int    `json:"attempts"`    // 已执行次数, 包括首次处理; 认领重试时以该值为条件, 防止同一记录被并发重试
+	Error       string `json:"error"`       // 最近一次错误
+	Status      int    `json:"status"`      // 状态
+	NextRetryAt int64  `json:"nextRetryAt"` // 下次重试时间
+	CreatedAt   int64  `json:"createdAt"`   // 创建时间
+	UpdatedAt   int64  `json:"updatedAt"`   // 更新时间
+}
+
+// EventDeadLetter 状态
+const (
+	EventDeadLetterStatusPending   = 0 // 待重试
+	EventDeadLetterStatusResolved  = 1 // 重试成功
+	EventDeadLetterStatusDiscarded = 2 // 已人工丢弃
+	EventDeadLetterStatusExhausted = 3 // 达到最大重试次数, 待人工处理
+	EventDeadLetterStatusRunning   = 4 // 重试中, 由认领的实例执行处理函数
+)
@@ -1,6 +1,10 @@
 package model
 
-import "github.com/gogf/gf/v2/os/gtime"
+import (
+	"math/big"
+
+	"github.com/gogf/gf/v2/os/gtime"
+)
 
 // BatchTransaction 批量交易
 type BatchTransaction struct {
@@ -39,6 +43,22 @@ type GasStrategy struct {
 	UpdatedAt    *gtime.Time `json:"updated_at"   description:"更新时间"`
 }
 
+// TxFeeParams 交易费用参数, 未指定的字段按Gas策略估算
+type TxFeeParams struct {
+	Strategy             string `json:"strategy"             description:"Gas策略 FASTEST/FAST/STANDARD/SLOW, 默认STANDARD"`
+	GasPrice             string `json:"gasPrice"             description:"gas价格(wei), 指定时使用legacy交易"`
+	MaxFeePerGas         string `json:"maxFeePerGas"         description:"最大费用(wei)"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas" description:"最大优先费用(wei)"`
+}
+
+// TxFees 估算后的交易费用
+type TxFees struct {
+	Legacy               bool     `json:"legacy"               description:"是否使用legacy交易"`
+	GasPrice             *big.Int `json:"gasPrice"             description:"legacy交易gas价格"`
+	MaxFeePerGas         *big.Int `json:"maxFeePerGas"         description:"EIP-1559最大费用"`
+	MaxPriorityFeePerGas *big.Int `json:"maxPriorityFeePerGas" description:"EIP-1559最大优先费用"`
+}
+
 // MEVProtection MEV防护记录
 type MEVProtection struct {
 	Id          uint64      `json:"id"           description:"ID"`
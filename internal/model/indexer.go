@@ -0,0 +1,46 @@
+package model
+
+// IndexerCursor 索引游标, 按(chainId, source)唯一
+type IndexerCursor struct {
+	Id          uint64 `json:"id"`          // ID
+	ChainId     uint64 `json:"chainId"`     // 链ID
+	Source      string `json:"source"`      // 索引来源
+	BlockNumber uint64 `json:"blockNumber"` // 已处理的最高区块
+	UpdatedAt   int64  `json:"updatedAt"`   // 更新时间
+}
+
+// IndexerSourceInbound 托管钱包收支索引
+const IndexerSourceInbound = "inbound"
+
+// 合约事件索引分组, 每组由一个后台任务驱动, 组内每个事件来源独立维护游标
+const (
+	EventGroupBridge   = "bridge"   // 跨链桥Lock/Unlock事件, 来源为 bridge
+	EventGroupNFT      = "nft"      // NFT转移事件, 来源为 nft:<合约ID>
+	EventGroupContract = "contract" // 合约ABI中声明的全部事件, 来源为 contract:<合约ID>
+)
+
+// IndexerBlock 事件索引已处理区块的哈希, 按(chainId, eventGroup, blockNumber)唯一, 用于检测重组
+type IndexerBlock struct {
+	Id          uint64 `json:"id"`          // ID
+	ChainId     uint64 `json:"chainId"`     // 链ID
+	EventGroup  string `json:"eventGroup"`  // 事件索引分组
+	BlockNumber uint64 `json:"blockNumber"` // 区块高度
+	BlockHash   string `json:"blockHash"`   // 处理时的区块哈希
+	CreatedAt   int64  `json:"createdAt"`   // 创建时间
+}
+
+// IndexerLog 重组窗口内已处理的事件日志, 发生重组时据此回滚派生数据
+type IndexerLog struct {
+	Id          uint64 `json:"id"`          // ID
+	ChainId     uint64 `json:"chainId"`     // 链ID
+	Source      string `json:"source"`      // 事件来源
+	Address     string `json:"address"`     // 合约地址
+	Topics      string `json:"topics"`      // 主题, 逗号分隔
+	Data        string `json:"data"`        // 日志数据(hex)
+	BlockNumber uint64 `json:"blockNumber"` // 区块高度
+	BlockHash   string `json:"blockHash"`   // 区块哈希
+	TxHash      string `json:"txHash"`      // 交易哈希
+	TxIndex     uint   `json:"txIndex"`     // 交易索引
+	LogIndex    uint   `json:"logIndex"`    // 日志索引
+	CreatedAt   int64  `json:"createdAt"`   // 创建时间
+}
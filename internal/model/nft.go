@@ -20,7 +20,7 @@ type NFT struct {
 	UpdatedAt   int64  `json:"updatedAt"`   // 更新时间
 }
 
-// NFTTransfer NFT交易记录
+// NFTTransfer NFT交易记录, 链上索引的记录按(chainId, hash, logIndex)唯一, 重复索引同一日志时忽略
 type NFTTransfer struct {
 	Id          uint64 `json:"id"`          // ID
 	NftId       uint64 `json:"nftId"`       // NFT ID
@@ -29,6 +29,8 @@ type NFTTransfer struct {
 	Amount      uint64 `json:"amount"`      // 数量
 	Type        string `json:"type"`        // 类型
 	Hash        string `json:"hash"`        // 交易哈希
+	ChainId     uint64 `json:"chainId"`     // 链ID, 仅链上索引的记录设置
+	LogIndex    uint   `json:"logIndex"`    // 日志索引, 仅链上索引的记录设置
 	BlockNumber int64  `json:"blockNumber"` // 区块高度
 	BlockTime   int64  `json:"blockTime"`   // 区块时间
 	CreatedAt   int64  `json:"createdAt"`   // 创建时间
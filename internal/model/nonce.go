@@ -0,0 +1,29 @@
+package model
+
+// NonceAccount 地址nonce账户, 按(chainId, address)唯一
+type NonceAccount struct {
+	Id        uint64 `json:"id"`        // ID
+	ChainId   uint64 `json:"chainId"`   // 链ID
+	Address   string `json:"address"`   // 地址
+	NextNonce uint64 `json:"nextNonce"` // 下一个待分配的nonce
+	UpdatedAt int64  `json:"updatedAt"` // 更新时间
+}
+
+// NonceReservation nonce预留记录
+type NonceReservation struct {
+	Id        uint64 `json:"id"`        // ID
+	ChainId   uint64 `json:"chainId"`   // 链ID
+	Address   string `json:"address"`   // 地址
+	Nonce     uint64 `json:"nonce"`     // 预留的nonce
+	TxHash    string `json:"txHash"`    // 交易哈希
+	Status    int    `json:"status"`    // 状态
+	CreatedAt int64  `json:"createdAt"` // 创建时间
+	UpdatedAt int64  `json:"updatedAt"` // 更新时间
+}
+
+// NonceReservation 状态
+const (
+	NonceStatusReserved = 0 // 已预留
+	NonceStatusSent     = 1 // 已广播
+	NonceStatusReleased = 2 // 已释放, 可被复用填补空缺
+)
@@ -0,0 +1,81 @@
+package model
+
+// OfflineTransaction 离线签名交易, 私钥不在服务端的钱包(如冷钱包/国库钱包)先生成待签名信封, 外部签名后提交广播
+type OfflineTransaction struct {
+	Id            uint64 `json:"id"`            // ID, 即信封ID
+	UserId        uint64 `json:"userId"`        // 用户ID
+	ChainId       uint64 `json:"chainId"`       // 链ID
+	FromAddress   string `json:"fromAddress"`   // 发送地址
+	ToAddress     string `json:"toAddress"`     // 接收地址, 代币转账为收款地址
+	TokenAddress  string `json:"tokenAddress"`  // 代币地址, 原生代币为空
+	Amount        string `json:"amount"`        // 转账金额(最小单位)
+	Nonce         uint64 `json:"nonce"`         // 交易nonce
+	ReservationId uint64 `json:"reservationId"` // nonce预留记录ID
+	SigningHash   string `json:"signingHash"`   // 待签名哈希
+	Envelope      string `json:"envelope"`      // 待签名信封JSON
+	Hash          string `json:"hash"`          // 已广播交易哈希
+	Status        int    `json:"status"`        // 状态
+	Error         string `json:"error"`         // 错误信息
+	ExpireAt      int64  `json:"expireAt"`      // 过期时间
+	CreatedAt     int64  `json:"createdAt"`     // 创建时间
+	UpdatedAt     int64  `json:"updatedAt"`     // 更新时间
+}
+
+// OfflineTransaction 状态
+const (
+	OfflineTxStatusPending   = 0 // 待签名
+	OfflineTxStatusSubmitted = 1 // 已广播
+	OfflineTxStatusExpired   = 2 // 已过期
+)
+
+// OfflineTxParams 生成待签名交易的参数
+type OfflineTxParams struct {
+	ChainId  uint64       // 链ID, 0表示链注册表的默认链
+	From     string       // 发送地址
+	To       string       // 接收地址或合约地址, 为空且Data非空时为合约创建
+	Token    string       // 代币地址, 非空时构建ERC20转账
+	Amount   string       // 金额
+	Unit     string       // 金额单位, decimal或raw, 为空时使用配置的默认单位
+	Data     string       // 合约调用数据(十六进制), 与Token互斥
+	GasLimit uint64       // gas限制, 0时自动估算
+	Fee      *TxFeeParams // 费用参数
+}
+
+// TxEnvelope 可移植的待签名交易信封
+type TxEnvelope struct {
+	Id                   uint64     `json:"id"`                             // 信封ID
+	ChainId              uint64     `json:"chainId"`                        // 链ID
+	From                 string     `json:"from"`                           // 发送地址
+	To                   string     `json:"to"`                             // 交易目标地址, 合约创建时为空
+	Nonce                uint64     `json:"nonce"`                          // 交易nonce
+	Value                string     `json:"value"`                          // 转账金额(wei)
+	Data                 string     `json:"data"`                           // 交易数据(0x十六进制)
+	GasLimit             uint64     `json:"gasLimit"`                       // gas限制
+	TxType               int        `json:"txType"`                         // 交易类型 0:legacy 2:EIP-1559
+	GasPrice             string     `json:"gasPrice,omitempty"`             // gas价格(wei), legacy交易
+	MaxFeePerGas         string     `json:"maxFeePerGas,omitempty"`         // EIP-1559最大费用(wei)
+	MaxPriorityFeePerGas string     `json:"maxPriorityFeePerGas,omitempty"` // EIP-1559最大优先费用(wei)
+	UnsignedTx           string     `json:"unsignedTx"`                     // 未签名交易编码(0x十六进制)
+	SigningHash          string     `json:"signingHash"`                    // 待签名哈希
+	Summary              *TxSummary `json:"summary"`                        // 可读摘要
+	ExpireAt             int64      `json:"expireAt"`                       // 过期时间
+}
+
+// TxSummary 交易可读摘要
+type TxSummary struct {
+	Action        string            `json:"action"`                  // 交易类型
+	Description   string            `json:"description"`             // 可读描述
+	Recipient     string            `json:"recipient,omitempty"`     // 收款地址
+	Amount        *TokenAmount      `json:"amount,omitempty"`        // 转账金额
+	Method        string            `json:"method,omitempty"`        // 调用方法
+	Args          map[string]string `json:"args,omitempty"`          // 调用参数
+	MaxNetworkFee *TokenAmount      `json:"maxNetworkFee,omitempty"` // 最高网络费用
+}
+
+// TxSummary 交易类型
+const (
+	TxActionNativeTransfer   = "NATIVE_TRANSFER"
+	TxActionTokenTransfer    = "TOKEN_TRANSFER"
+	TxActionContractCall     = "CONTRACT_CALL"
+	TxActionContractCreation = "CONTRACT_CREATION"
+)
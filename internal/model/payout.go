@@ -0,0 +1,103 @@
+package model
+
+// PayoutJob 批量付款任务
+type PayoutJob struct {
+	Id          uint64 `json:"id"`          // 任务ID
+	UserId      uint64 `json:"userId"`      // 用户ID
+	ChainId     uint64 `json:"chainId"`     // 链ID
+	FromAddress string `json:"fromAddress"` // 付款地址
+	Mode        string `json:"mode"`        // 执行方式
+	Total       int    `json:"total"`       // 总行数
+	Succeeded   int    `json:"succeeded"`   // 成功行数
+	Failed      int    `json:"failed"`      // 失败行数
+	Status      int    `json:"status"`      // 状态
+	Error       string `json:"error"`       // 错误信息
+	CreatedAt   int64  `json:"createdAt"`   // 创建时间
+	UpdatedAt   int64  `json:"updatedAt"`   // 更新时间
+}
+
+// PayoutJob 执行方式
+const (
+	PayoutModeSequential = "SEQUENTIAL" // 逐笔转账, 由nonce管理器连续分配nonce
+	PayoutModeDisperse   = "DISPERSE"   // 按代币合并为Disperse合约批量分发交易
+)
+
+// PayoutJob 状态
+const (
+	PayoutJobStatusPending   = 0 // 待执行
+	PayoutJobStatusRunning   = 1 // 执行中
+	PayoutJobStatusCompleted = 2 // 全部成功
+	PayoutJobStatusPartial   = 3 // 部分失败
+	PayoutJobStatusFailed    = 4 // 全部失败
+)
+
+// PayoutItem 批量付款明细行
+type PayoutItem struct {
+	Id           uint64 `json:"id"`           // ID
+	JobId        uint64 `json:"jobId"`        // 任务ID
+	RowNo        int    `json:"rowNo"`        // 原始行号, 从1开始
+	ToAddress    string `json:"toAddress"`    // 收款地址
+	TokenAddress string `json:"tokenAddress"` // 代币地址, 原生代币为空
+	Amount       string `json:"amount"`       // 金额(最小单位)
+	BatchId      uint64 `json:"batchId"`      // 所属交易批次ID
+	Hash         string `json:"hash"`         // 交易哈希
+	Status       int    `json:"status"`       // 状态
+	Error        string `json:"error"`        // 错误信息
+	CreatedAt    int64  `json:"createdAt"`    // 创建时间
+	UpdatedAt    int64  `json:"updatedAt"`    // 更新时间
+}
+
+// PayoutItem 状态
+const (
+	PayoutItemStatusPending   = 0 // 待发送
+	PayoutItemStatusSubmitted = 1 // 已广播, 待确认
+	PayoutItemStatusConfirmed = 2 // 已确认
+	PayoutItemStatusFailed    = 3 // 失败
+)
+
+// PayoutBatch 批量付款交易批次, 逐笔模式下每行一笔, Disperse模式下每种代币按批次大小拆分
+type PayoutBatch struct {
+	Id            uint64 `json:"id"`            // ID
+	JobId         uint64 `json:"jobId"`         // 任务ID
+	ChainId       uint64 `json:"chainId"`       // 链ID
+	TokenAddress  string `json:"tokenAddress"`  // 代币地址, 原生代币为空
+	Nonce         uint64 `json:"nonce"`         // 交易nonce
+	ReservationId uint64 `json:"reservationId"` // nonce预留记录ID
+	Hash          string `json:"hash"`          // 交易哈希
+	RawTx         string `json:"rawTx"`         // 已签名原始交易, 崩溃恢复时重新广播
+	Status        int    `json:"status"`        // 状态
+	Error         string `json:"error"`         // 错误信息
+	CreatedAt     int64  `json:"createdAt"`     // 创建时间
+	UpdatedAt     int64  `json:"updatedAt"`     // 更新时间
+}
+
+// PayoutBatch 状态
+const (
+	PayoutBatchStatusPending   = 0 // 待签名
+	PayoutBatchStatusSigned    = 1 // 已签名并保存, 待广播
+	PayoutBatchStatusSent      = 2 // 已广播, 待确认
+	PayoutBatchStatusConfirmed = 3 // 已确认
+	PayoutBatchStatusFailed    = 4 // 失败
+)
+
+// PayoutParams 创建批量付款任务的参数
+type PayoutParams struct {
+	ChainId uint64      // 链ID, 0表示链注册表的默认链
+	From    string      // 付款地址
+	Mode    string      // 执行方式, 默认逐笔转账
+	Csv     string      // CSV内容, 列为 address,amount,token, 首行可为表头
+	Rows    []PayoutRow // JSON输入行, 与Csv二选一
+}
+
+// PayoutRow 批量付款输入行
+type PayoutRow struct {
+	Address string `json:"address"` // 收款地址
+	Amount  string `json:"amount"`  // 金额, 按代币精度的十进制数
+	Token   string `json:"token"`   // 代币地址, 为空表示原生代币
+}
+
+// PayoutRowError 批量付款输入行校验错误
+type PayoutRowError struct {
+	RowNo int    `json:"rowNo"` // 行号, 从1开始
+	Error string `json:"error"` // 错误信息
+}
@@ -0,0 +1,38 @@
+package model
+
+// ReindexJob 重新索引任务: 按回放模式重新处理区块范围内链、合约或单个事件的日志, 不移动实时索引的游标
+type ReindexJob struct {
+	Id         uint64 `json:"id"`         // 任务ID
+	ChainId    uint64 `json:"chainId"`    // 链ID
+	ContractId uint64 `json:"contractId"` // 合约ID, 0表示链上全部事件来源
+	Event      string `json:"event"`      // 事件名称, 为空表示全部事件
+	FromBlock  uint64 `json:"fromBlock"`  // 起始区块
+	ToBlock    uint64 `json:"toBlock"`    // 结束区块
+	NextBlock  uint64 `json:"nextBlock"`  // 下一个待处理的区块
+	Logs       uint64 `json:"logs"`       // 已处理日志数
+	Failures   uint64 `json:"failures"`   // 处理失败的日志数
+	Runner     string `json:"runner"`     // 执行中的实例
+	Status     int    `json:"status"`     // 状态
+	Error      string `json:"error"`      // 错误信息
+	CreatedAt  int64  `json:"createdAt"`  // 创建时间
+	UpdatedAt  int64  `json:"updatedAt"`  // 更新时间, 执行中每处理一段区块刷新
+	FinishedAt int64  `json:"finishedAt"` // 结束时间
+}
+
+// ReindexJob 状态
+const (
+	ReindexJobStatusPending   = 0 // 待执行
+	ReindexJobStatusRunning   = 1 // 执行中
+	ReindexJobStatusCompleted = 2 // 已完成
+	ReindexJobStatusFailed    = 3 // 失败
+	ReindexJobStatusCancelled = 4 // 已取消
+)
+
+// ReindexParams 创建重新索引任务的参数
+type ReindexParams struct {
+	ChainId    uint64 // 链ID, 指定合约时可为0
+	ContractId uint64 // 合约ID, 0表示链上全部事件来源
+	Event      string // 事件名称, 需同时指定合约
+	FromBlock  uint64 // 起始区块
+	ToBlock    uint64 // 结束区块, 0表示当前已确认高度
+}
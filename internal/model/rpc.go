@@ -0,0 +1,17 @@
+package model
+
+// RpcEndpointHealth RPC节点健康状态
+type RpcEndpointHealth struct {
+	ChainId     uint64  `json:"chainId"`     // 链ID
+	Url         string  `json:"url"`         // 节点地址, 已隐藏路径与凭证
+	Weight      int     `json:"weight"`      // 配置权重
+	Healthy     bool    `json:"healthy"`     // 是否健康
+	BlockNumber uint64  `json:"blockNumber"` // 最近探测到的区块高度
+	Lag         uint64  `json:"lag"`         // 落后于同链最高节点的区块数
+	LatencyMs   int64   `json:"latencyMs"`   // 平均延迟(毫秒)
+	ErrorRate   float64 `json:"errorRate"`   // 最近请求的错误率
+	Requests    uint64  `json:"requests"`    // 累计请求数
+	Failures    uint64  `json:"failures"`    // 累计失败数
+	LastError   string  `json:"lastError"`   // 最近一次错误
+	LastProbeAt int64   `json:"lastProbeAt"` // 最近探测时间
+}
@@ -0,0 +1,71 @@
+package model
+
+// ScheduledTransfer 定时转账, 按cron表达式周期执行ETH或代币转账
+type ScheduledTransfer struct {
+	Id            uint64 `json:"id"`            // ID
+	UserId        uint64 `json:"userId"`        // 用户ID
+	ChainId       uint64 `json:"chainId"`       // 链ID
+	FromAddress   string `json:"fromAddress"`   // 付款地址
+	ToAddress     string `json:"toAddress"`     // 收款地址
+	TokenAddress  string `json:"tokenAddress"`  // 代币地址, 原生代币为空
+	Amount        string `json:"amount"`        // 每次转账金额(最小单位)
+	Cron          string `json:"cron"`          // cron表达式: 分 时 日 月 周, 按服务器时区
+	GasStrategy   string `json:"gasStrategy"`   // Gas策略
+	StartAt       int64  `json:"startAt"`       // 开始时间
+	EndAt         int64  `json:"endAt"`         // 结束时间, 0表示不限
+	MaxRuns       int    `json:"maxRuns"`       // 最大执行次数, 0表示不限
+	RunCount      int    `json:"runCount"`      // 已执行次数
+	MaxRetries    int    `json:"maxRetries"`    // 单次执行失败后的最大重试次数
+	RetryInterval int64  `json:"retryInterval"` // 首次重试间隔(秒), 之后按指数退避
+	NextRunAt     int64  `json:"nextRunAt"`     // 下次执行时间
+	LastRunAt     int64  `json:"lastRunAt"`     // 上次执行时间
+	Status        int    `json:"status"`        // 状态
+	CreatedAt     int64  `json:"createdAt"`     // 创建时间
+	UpdatedAt     int64  `json:"updatedAt"`     // 更新时间
+}
+
+// ScheduledTransfer 状态
+const (
+	ScheduleStatusActive    = 0 // 生效中
+	ScheduleStatusPaused    = 1 // 已暂停
+	ScheduleStatusFinished  = 2 // 已结束(达到结束时间或最大次数)
+	ScheduleStatusCancelled = 3 // 已取消
+)
+
+// ScheduledRun 定时转账的单次执行记录
+type ScheduledRun struct {
+	Id          uint64 `json:"id"`          // ID
+	ScheduleId  uint64 `json:"scheduleId"`  // 定时转账ID
+	ScheduledAt int64  `json:"scheduledAt"` // 计划执行时间
+	Attempt     int    `json:"attempt"`     // 已尝试次数
+	Hash        string `json:"hash"`        // 交易哈希
+	Status      int    `json:"status"`      // 状态
+	Error       string `json:"error"`       // 最近一次错误信息
+	NextRetryAt int64  `json:"nextRetryAt"` // 下次重试时间
+	CreatedAt   int64  `json:"createdAt"`   // 创建时间
+	UpdatedAt   int64  `json:"updatedAt"`   // 更新时间
+}
+
+// ScheduledRun 状态
+const (
+	ScheduledRunStatusRunning  = 0 // 执行中
+	ScheduledRunStatusSent     = 1 // 已广播
+	ScheduledRunStatusRetrying = 2 // 失败待重试
+	ScheduledRunStatusFailed   = 3 // 失败, 重试次数用尽或执行中断
+)
+
+// ScheduleParams 创建定时转账的参数
+type ScheduleParams struct {
+	ChainId       uint64 // 链ID
+	From          string // 付款地址
+	To            string // 收款地址
+	Token         string // 代币地址, 为空表示原生代币
+	Amount        string // 每次转账金额(最小单位)
+	Cron          string // cron表达式
+	GasStrategy   string // Gas策略
+	StartAt       int64  // 开始时间, 0表示立即
+	EndAt         int64  // 结束时间, 0表示不限
+	MaxRuns       int    // 最大执行次数, 0表示不限
+	MaxRetries    int    // 最大重试次数
+	RetryInterval int64  // 首次重试间隔(秒)
+}
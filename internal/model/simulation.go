@@ -0,0 +1,34 @@
+package model
+
+// SimulationResult 交易模拟执行结果
+type SimulationResult struct {
+	From       string        `json:"from"       dc:"发送地址"`
+	To         string        `json:"to"         dc:"目标地址, 合约创建时为空"`
+	Value      string        `json:"value"      dc:"转账金额(wei)"`
+	Method     string        `json:"method"     dc:"调用方法, ABI可解析时返回"`
+	GasLimit   uint64        `json:"gasLimit"   dc:"gas限制"`
+	Simulated  bool          `json:"simulated"  dc:"是否已模拟执行, 依赖的授权交易未上链时为false"`
+	Success    bool          `json:"success"    dc:"是否执行成功"`
+	ReturnData string        `json:"returnData" dc:"返回数据"`
+	Revert     *RevertReason `json:"revert,omitempty" dc:"回滚原因"`
+
+	Approvals []*SimulationResult `json:"approvals,omitempty" dc:"需先发送的授权交易及其模拟结果; 不为空时本交易依赖的授权未上链, 未模拟执行"`
+}
+
+// RevertReason 回滚原因
+type RevertReason struct {
+	Kind     string                 `json:"kind"     dc:"类型 Error/Panic/Custom/Unknown"`
+	Selector string                 `json:"selector" dc:"错误选择器"`
+	Name     string                 `json:"name"     dc:"错误名称"`
+	Reason   string                 `json:"reason"   dc:"可读原因"`
+	Args     map[string]interface{} `json:"args,omitempty" dc:"错误参数"`
+	Data     string                 `json:"data"     dc:"原始回滚数据"`
+}
+
+// RevertReason 类型
+const (
+	RevertKindError   = "Error"
+	RevertKindPanic   = "Panic"
+	RevertKindCustom  = "Custom"
+	RevertKindUnknown = "Unknown"
+)
@@ -0,0 +1,52 @@
+package model
+
+// TxTracking 交易生命周期跟踪记录, 按(source, sourceId, txHash)唯一
+type TxTracking struct {
+	Id            uint64 `json:"id"`            // ID
+	ChainId       uint64 `json:"chainId"`       // 链ID
+	TxHash        string `json:"txHash"`        // 交易哈希
+	Source        string `json:"source"`        // 来源表
+	SourceId      uint64 `json:"sourceId"`      // 来源记录ID
+	FromAddress   string `json:"fromAddress"`   // 发送地址, 节点首次返回交易后记录
+	Nonce         uint64 `json:"nonce"`         // 交易nonce
+	State         int    `json:"state"`         // 状态
+	BlockNumber   uint64 `json:"blockNumber"`   // 所在区块高度
+	BlockHash     string `json:"blockHash"`     // 所在区块哈希
+	Confirmations uint64 `json:"confirmations"` // 确认数
+	ReplacedBy    string `json:"replacedBy"`    // 替换交易哈希
+	Error         string `json:"error"`         // 错误信息
+	CreatedAt     int64  `json:"createdAt"`     // 创建时间
+	UpdatedAt     int64  `json:"updatedAt"`     // 更新时间
+}
+
+// TxTracking 状态
+const (
+	TxStatePending   = 0 // 已广播, 未打包
+	TxStateIncluded  = 1 // 已打包, 确认数不足
+	TxStateConfirmed = 2 // 达到所需确认数
+	TxStateFinalized = 3 // 已最终确定
+	TxStateFailed    = 4 // 执行失败
+	TxStateDropped   = 5 // 被节点丢弃
+	TxStateReplaced  = 6 // 被同nonce交易替换
+)
+
+// TrackedSource 需跟踪交易状态的业务表
+type TrackedSource struct {
+	Name          string // 来源名称, 同一张表的不同交易哈希字段使用不同名称
+	Table         string // 表名
+	HashField     string // 交易哈希字段
+	ChainField    string // 链ID字段, 为空时使用默认链
+	StatusField   string // 状态字段
+	ErrorField    string // 错误信息字段, 为空时不回写
+	PendingStatus int    // 待确认状态值, 仅跟踪该状态的记录
+	SuccessStatus int    // 确认成功后写入的状态值, 小于0时不更新
+	FailedStatus  int    // 失败后写入的状态值
+	BlockFields   bool   // 是否回写block_number/block_time
+}
+
+// TrackedRow 业务表中待跟踪的记录
+type TrackedRow struct {
+	Id      uint64 `json:"id"`
+	Hash    string `json:"hash"`
+	ChainId uint64 `json:"chainId"`
+}
@@ -2,22 +2,28 @@ package model
 
 // Transaction 交易记录
 type Transaction struct {
-	Id           uint64 `json:"id" dc:"交易ID"`
-	UserId       uint64 `json:"userId" dc:"用户ID"`
-	FromAddress  string `json:"fromAddress" dc:"发送地址"`
-	ToAddress    string `json:"toAddress" dc:"接收地址"`
-	Amount       string `json:"amount" dc:"转账金额"`
-	TokenAddress string `json:"tokenAddress" dc:"代币合约地址"`
-	Hash         string `json:"hash" dc:"交易哈希"`
-	Nonce        uint64 `json:"nonce" dc:"交易nonce"`
-	GasPrice     string `json:"gasPrice" dc:"gas价格(wei)"`
-	GasLimit     uint64 `json:"gasLimit" dc:"gas限制"`
-	Data         string `json:"data" dc:"交易数据"`
-	Status       int    `json:"status" dc:"状态 0:待处理 1:已确认 2:失败"`
-	BlockNumber  int64  `json:"blockNumber" dc:"区块高度"`
-	BlockTime    int64  `json:"blockTime" dc:"区块时间"`
-	CreatedAt    int64  `json:"createdAt" dc:"创建时间"`
-	UpdatedAt    int64  `json:"updatedAt" dc:"更新时间"`
+	Id                   uint64 `json:"id" dc:"交易ID"`
+	UserId               uint64 `json:"userId" dc:"用户ID"`
+	ChainId              uint64 `json:"chainId" dc:"链ID"`
+	FromAddress          string `json:"fromAddress" dc:"发送地址"`
+	ToAddress            string `json:"toAddress" dc:"接收地址"`
+	Amount               string `json:"amount" dc:"转账金额"`
+	TokenAddress         string `json:"tokenAddress" dc:"代币合约地址"`
+	Hash                 string `json:"hash" dc:"交易哈希"`
+	LogIndex             int    `json:"logIndex" dc:"代币Transfer日志序号, 原生转账为-1"`
+	Direction            string `json:"direction" dc:"方向 OUT:转出 IN:转入 INTERNAL:托管钱包之间"`
+	Nonce                uint64 `json:"nonce" dc:"交易nonce"`
+	TxType               int    `json:"txType" dc:"交易类型 0:legacy 2:EIP-1559"`
+	GasPrice             string `json:"gasPrice" dc:"gas价格(wei), EIP-1559交易为maxFeePerGas"`
+	MaxFeePerGas         string `json:"maxFeePerGas" dc:"EIP-1559最大费用(wei)"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas" dc:"EIP-1559最大优先费用(wei)"`
+	GasLimit             uint64 `json:"gasLimit" dc:"gas限制"`
+	Data                 string `json:"data" dc:"交易数据"`
+	Status               int    `json:"status" dc:"状态 0:待处理 1:已确认 2:失败"`
+	BlockNumber          int64  `json:"blockNumber" dc:"区块高度"`
+	BlockTime            int64  `json:"blockTime" dc:"区块时间"`
+	CreatedAt            int64  `json:"createdAt" dc:"创建时间"`
+	UpdatedAt            int64  `json:"updatedAt" dc:"更新时间"`
 }
 
 // TransactionStatus 交易状态
@@ -27,6 +33,16 @@ const (
 	TransactionStatusFailed  = 2 // 失败
 )
 
+// Transaction 方向
+const (
+	TransactionDirectionOut      = "OUT"      // 托管钱包转出
+	TransactionDirectionIn       = "IN"       // 转入托管钱包
+	TransactionDirectionInternal = "INTERNAL" // 托管钱包之间转账
+)
+
+// NativeLogIndex 原生转账的日志序号
+const NativeLogIndex = -1
+
 // GetStatusDesc 获取状态描述
 func (t *Transaction) GetStatusDesc() string {
 	switch t.Status {
@@ -1,15 +1,103 @@
 package model
 
 type Wallet struct {
-	Id         uint64 `json:"id"           description:"钱包ID"`
+	Id             uint64 `json:"id"           description:"钱包ID"`
+	UserId         uint64 `json:"userId"       description:"用户ID"`
+	Balance        string `json:"balance"      description:"账户余额"`
+	Address        string `json:"address"      description:"钱包地址"`
+	Mnemonic       string `json:"mnemonic"     description:"助记词(加密存储)"`
+	PrivateKey     string `json:"privateKey"   description:"私钥(加密存储)"`
+	PublicKey      string `json:"publicKey"    description:"公钥"`
+	DerivationPath string `json:"derivationPath" description:"派生路径 m/44'/60'/0'/0/i"`
+	Chain          string `json:"chain"        description:"链名称 ETH/BSC"`
+	WalletType     int    `json:"walletType"   description:"钱包类型 1:助记词 2:私钥导入 3:Keystore导入 4:观察钱包"`
+	Label          string `json:"label"        description:"标签名称"`
+	Tags           string `json:"tags"         description:"标签列表(JSON数组)"`
+	GroupId        uint64 `json:"groupId"      description:"分组ID"`
+	CreatedAt      int64  `json:"createdAt"    description:"创建时间"`
+	UpdatedAt      int64  `json:"updatedAt"    description:"更新时间"`
+}
+
+// WalletType 钱包类型
+const (
+	WalletTypeMnemonic   = 1 // 助记词
+	WalletTypePrivateKey = 2 // 私钥导入
+	WalletTypeKeystore   = 3 // Keystore导入
+	WalletTypeWatchOnly  = 4 // 观察钱包, 无私钥
+)
+
+// WalletGroup 钱包分组
+type WalletGroup struct {
+	Id          uint64 `json:"id"           description:"分组ID"`
+	UserId      uint64 `json:"userId"       description:"用户ID"`
+	Name        string `json:"name"         description:"分组名称"`
+	Description string `json:"description"  description:"分组描述"`
+	CreatedAt   int64  `json:"createdAt"    description:"创建时间"`
+	UpdatedAt   int64  `json:"updatedAt"    description:"更新时间"`
+}
+
+// WalletFilter 钱包列表筛选条件, 零值表示不筛选
+type WalletFilter struct {
 	UserId     uint64 `json:"userId"       description:"用户ID"`
-	Balance    string `json:"balance"      description:"账户余额"`
-	Address    string `json:"address"      description:"钱包地址"`
-	Mnemonic   string `json:"mnemonic"     description:"助记词(加密存储)"`
-	PrivateKey string `json:"privateKey"   description:"私钥(加密存储)"`
-	PublicKey  string `json:"publicKey"    description:"公钥"`
-	Chain      string `json:"chain"        description:"链名称 ETH/BSC"`
-	WalletType int    `json:"walletType"   description:"钱包类型 1:助记词 2:私钥导入"`
-	CreatedAt  int64  `json:"createdAt"    description:"创建时间"`
-	UpdatedAt  int64  `json:"updatedAt"    description:"更新时间"`
+	Chain      string `json:"chain"        description:"链名称"`
+	WalletType int    `json:"walletType"   description:"钱包类型"`
+	GroupId    uint64 `json:"groupId"      description:"分组ID"`
+	Tag        string `json:"tag"          description:"包含的标签"`
+	Label      string `json:"label"        description:"标签名称关键字"`
+}
+
+// IsWatchOnly 是否为观察钱包
+func (w *Wallet) IsWatchOnly() bool {
+	return w.WalletType == WalletTypeWatchOnly
+}
+
+// KeyRotationResult 密钥轮换结果
+type KeyRotationResult struct {
+	Scanned int `json:"scanned"      description:"扫描钱包数"`
+	Rotated int `json:"rotated"      description:"重新加密钱包数"`
+	Skipped int `json:"skipped"      description:"已是当前密钥或被并发修改跳过数"`
+	Failed  int `json:"failed"       description:"解密失败数"`
+}
+
+// WalletBalance 钱包多链余额
+type WalletBalance struct {
+	Address      string          `json:"address"      description:"钱包地址"`
+	FiatCurrency string          `json:"fiatCurrency" description:"法币币种"`
+	FiatValue    string          `json:"fiatValue"    description:"法币估值合计"`
+	Chains       []*ChainBalance `json:"chains"       description:"各链余额"`
+}
+
+// ChainBalance 单链余额
+type ChainBalance struct {
+	ChainId   uint64          `json:"chainId"      description:"链ID"`
+	Name      string          `json:"name"         description:"链名称"`
+	Native    *TokenBalance   `json:"native"       description:"原生代币余额"`
+	Tokens    []*TokenBalance `json:"tokens"       description:"代币余额"`
+	FiatValue string          `json:"fiatValue"    description:"法币估值"`
+	Error     string          `json:"error"        description:"查询失败原因"`
+}
+
+// TokenBalance 代币余额
+type TokenBalance struct {
+	TokenAddress string `json:"tokenAddress" description:"代币地址, 原生代币为空"`
+	Symbol       string `json:"symbol"       description:"代币符号"`
+	Decimals     int    `json:"decimals"     description:"精度"`
+	Raw          string `json:"raw"          description:"最小单位余额"`
+	Balance      string `json:"balance"      description:"按精度换算后的余额"`
+	FiatValue    string `json:"fiatValue"    description:"法币估值"`
+}
+
+// BalanceToken 余额查询代币配置
+type BalanceToken struct {
+	Address  string `json:"address"      description:"代币地址"`
+	Symbol   string `json:"symbol"       description:"代币符号"`
+	Decimals int    `json:"decimals"     description:"精度"`
+}
+
+// SignatureVerification 签名验证结果
+type SignatureVerification struct {
+	Valid     bool   `json:"valid"        description:"签名是否有效"`
+	Method    string `json:"method"       description:"验证方式 ecrecover/eip1271"`
+	Recovered string `json:"recovered"    description:"ecrecover恢复出的签名地址"`
+	Hash      string `json:"hash"         description:"被签名的消息哈希"`
 }
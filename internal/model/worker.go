@@ -0,0 +1,27 @@
+package model
+
+// WorkerJob 后台任务的租约与运行状态, 按name唯一
+// 各worker实例竞争同一行租约, 持有未过期租约的实例(leader)执行任务并定期续约, 运行状态由leader写入
+type WorkerJob struct {
+	Id             uint64 `json:"id"`             // ID
+	Name           string `json:"name"`           // 任务名称
+	Holder         string `json:"holder"`         // 租约持有实例, 释放后为空
+	LeaseExpiresAt int64  `json:"leaseExpiresAt"` // 租约到期时间
+	LeaseVersion   uint64 `json:"leaseVersion"`   // 每次获取或续约递增
+	State          string `json:"state"`          // 运行状态
+	Runs           uint64 `json:"runs"`           // 累计执行次数
+	Failures       uint64 `json:"failures"`       // 累计失败次数
+	LastStartedAt  int64  `json:"lastStartedAt"`  // 最近一次开始时间
+	LastFinishedAt int64  `json:"lastFinishedAt"` // 最近一次结束时间
+	LastError      string `json:"lastError"`      // 最近一次错误
+	LastErrorAt    int64  `json:"lastErrorAt"`    // 最近一次错误时间
+	UpdatedAt      int64  `json:"updatedAt"`      // 更新时间
+}
+
+// 后台任务运行状态
+const (
+	WorkerJobStateRunning = "running" // 执行中, 常驻任务持有租约期间始终为该状态
+	WorkerJobStateIdle    = "idle"    // 等待下一轮执行
+	WorkerJobStateStopped = "stopped" // 实例退出时已释放租约
+	WorkerJobStateLost    = "lost"    // 租约过期仍未释放, leader实例异常退出或无法访问数据库
+)
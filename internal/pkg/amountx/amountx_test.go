@@ -0,0 +1,187 @@
+package amountx
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int
+		want     string // 为空表示期望返回错误
+	}{
+		{"1.5", 6, "1500000"},
+		{"1", 18, "1000000000000000000"},
+		{"0.000000000000000001", 18, "1"},
+		{" 2.25 ", 2, "225"},
+		{"007", 2, "700"},
+		{"0", 6, "0"},
+		{"115792089237316195423570985008687907853269984665640564039457.584007913129639935", 18,
+			"115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		// 末尾的0不计入小数位数
+		{"1.50", 1, "15"},
+		{"1.000000", 0, "1"},
+		{"0.000", 0, "0"},
+		// decimals为0
+		{"42", 0, "42"},
+		{"1.5", 0, ""},
+		// 超出精度
+		{"0.0000001", 6, ""},
+		{"1.0000001", 6, ""},
+		// 格式错误
+		{"", 6, ""},
+		{"   ", 6, ""},
+		{"-1", 6, ""},
+		{"+1", 6, ""},
+		{"1.", 6, ""},
+		{".5", 6, ""},
+		{"1..5", 6, ""},
+		{"1.2.3", 6, ""},
+		{"1e18", 18, ""},
+		{"0x10", 6, ""},
+		{"1,000", 6, ""},
+		{"1 000", 6, ""},
+		{"abc", 6, ""},
+		{"1", -1, ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.in, tt.decimals)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseUnits(%q, %d) = %s, want error", tt.in, tt.decimals, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d) error = %v", tt.in, tt.decimals, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %s, want %s", tt.in, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnitsErrors(t *testing.T) {
+	if _, err := ParseUnits("", 6); !errors.Is(err, ErrEmptyAmount) {
+		t.Errorf("empty amount error = %v, want %v", err, ErrEmptyAmount)
+	}
+	if _, err := ParseUnits("-1.5", 6); !errors.Is(err, ErrNegativeAmount) {
+		t.Errorf("negative amount error = %v, want %v", err, ErrNegativeAmount)
+	}
+}
+
+func TestParseRaw(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // 为空表示期望返回错误
+	}{
+		{"1000000", "1000000"},
+		{" 0 ", "0"},
+		{"007", "7"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935",
+			"115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{"", ""},
+		{"-1", ""},
+		{"1.0", ""},
+		{"1.5", ""},
+		{"1e6", ""},
+		{"0x10", ""},
+		{"1 USDC", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseRaw(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseRaw(%q) = %s, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRaw(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseRaw(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	if _, err := ParseRaw("-1"); !errors.Is(err, ErrNegativeAmount) {
+		t.Errorf("negative raw amount error = %v, want %v", err, ErrNegativeAmount)
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		raw      string
+		decimals int
+		want     string
+	}{
+		{"1500000", 6, "1.5"},
+		{"1000000", 6, "1"},
+		{"1", 18, "0.000000000000000001"},
+		{"123456789", 6, "123.456789"},
+		{"100", 6, "0.0001"},
+		{"0", 6, "0"},
+		{"100", 0, "100"},
+		{"-1500000", 6, "-1.5"},
+		{"-1", 2, "-0.01"},
+	}
+	for _, tt := range tests {
+		raw, _ := new(big.Int).SetString(tt.raw, 10)
+		if got := FormatUnits(raw, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%s, %d) = %s, want %s", tt.raw, tt.decimals, got, tt.want)
+		}
+	}
+	if got := FormatUnits(nil, 6); got != "0" {
+		t.Errorf("FormatUnits(nil, 6) = %s, want 0", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int
+		want     string // 规范化后的十进制数
+	}{
+		{"1.5", 6, "1.5"},
+		{"1.500000", 6, "1.5"},
+		{"0.000001", 6, "0.000001"},
+		{"00012.340", 18, "12.34"},
+		{"42", 0, "42"},
+		{"0", 18, "0"},
+	}
+	for _, tt := range tests {
+		raw, err := ParseUnits(tt.in, tt.decimals)
+		if err != nil {
+			t.Fatalf("ParseUnits(%q, %d) error = %v", tt.in, tt.decimals, err)
+		}
+		formatted := FormatUnits(raw, tt.decimals)
+		if formatted != tt.want {
+			t.Errorf("FormatUnits(ParseUnits(%q, %d)) = %s, want %s", tt.in, tt.decimals, formatted, tt.want)
+		}
+		again, err := ParseUnits(formatted, tt.decimals)
+		if err != nil || again.Cmp(raw) != 0 {
+			t.Errorf("ParseUnits(%q, %d) = %v, %v, want %s", formatted, tt.decimals, again, err, raw)
+		}
+	}
+}
+
+func TestSplitSymbol(t *testing.T) {
+	tests := []struct {
+		in, amount, symbol string
+	}{
+		{"1.5 USDC", "1.5", "USDC"},
+		{" 1.5   USDC ", "1.5", "USDC"},
+		{"1.5", "1.5", ""},
+		{"", "", ""},
+		{"1 Wrapped Ether", "1", "Wrapped Ether"},
+	}
+	for _, tt := range tests {
+		amount, symbol := SplitSymbol(tt.in)
+		if amount != tt.amount || symbol != tt.symbol {
+			t.Errorf("SplitSymbol(%q) = %q, %q, want %q, %q", tt.in, amount, symbol, tt.amount, tt.symbol)
+		}
+	}
+}
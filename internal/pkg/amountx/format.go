@@ -0,0 +1,43 @@
+package amountx
+
+import (
+	"math/big"
+	"strings"
+)
+
+// FormatUnits 将最小单位整数按精度格式化为十进制字符串, 去除末尾多余的0
+func FormatUnits(raw *big.Int, decimals int) string {
+	if raw == nil {
+		return "0"
+	}
+	if decimals <= 0 {
+		return raw.String()
+	}
+
+	negative := raw.Sign() < 0
+	digits := new(big.Int).Abs(raw).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-decimals]
+	fracPart := strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	result := intPart
+	if fracPart != "" {
+		result += "." + fracPart
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// ToRat 将最小单位整数按精度转换为有理数, 用于估值计算
+func ToRat(raw *big.Int, decimals int) *big.Rat {
+	if raw == nil {
+		return new(big.Rat)
+	}
+	denominator := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Rat).SetFrac(raw, denominator)
+}
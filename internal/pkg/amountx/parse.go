@@ -0,0 +1,97 @@
+package amountx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrEmptyAmount    = errors.New("amount is empty")
+	ErrNegativeAmount = errors.New("amount must not be negative")
+)
+
+// 金额单位
+const (
+	UnitDecimal = "decimal" // 按代币精度的十进制数, 如 1.5
+	UnitRaw     = "raw"     // 最小单位整数, 如 1500000
+)
+
+// SplitSymbol 拆分金额与代币符号, 如 "1.5 USDC" 返回 "1.5", "USDC"
+func SplitSymbol(s string) (amount, symbol string) {
+	fields := strings.Fields(s)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return fields[0], ""
+	default:
+		return fields[0], strings.Join(fields[1:], " ")
+	}
+}
+
+// ParseUnits 将十进制金额按精度转换为最小单位整数, 小数位数超过精度时返回错误
+func ParseUnits(s string, decimals int) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrEmptyAmount
+	}
+	if strings.HasPrefix(s, "-") {
+		return nil, ErrNegativeAmount
+	}
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid decimals %d", decimals)
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+		if intPart == "" || fracPart == "" {
+			return nil, fmt.Errorf("malformed amount %q", s)
+		}
+	}
+	if !isDigits(intPart) || (fracPart != "" && !isDigits(fracPart)) {
+		return nil, fmt.Errorf("malformed amount %q", s)
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > decimals {
+		return nil, fmt.Errorf("amount %q has %d decimal places, exceeds token precision of %d", s, len(fracPart), decimals)
+	}
+
+	raw, ok := new(big.Int).SetString(intPart+fracPart+strings.Repeat("0", decimals-len(fracPart)), 10)
+	if !ok {
+		return nil, fmt.Errorf("malformed amount %q", s)
+	}
+	return raw, nil
+}
+
+// ParseRaw 解析最小单位整数金额
+func ParseRaw(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrEmptyAmount
+	}
+	if strings.HasPrefix(s, "-") {
+		return nil, ErrNegativeAmount
+	}
+	if !isDigits(s) {
+		return nil, fmt.Errorf("malformed raw amount %q, expected an integer in the smallest unit", s)
+	}
+	raw, _ := new(big.Int).SetString(s, 10)
+	return raw, nil
+}
+
+// isDigits 判断字符串是否全部为十进制数字
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
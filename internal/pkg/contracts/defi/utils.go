@@ -145,6 +145,28 @@ func (v *YearnVault) PricePerShare(ctx context.Context) (*big.Int, error) {
 	return result, err
 }
 
+// Token 获取存款代币地址
+func (v *YearnVault) Token(ctx context.Context) (common.Address, error) {
+	data, err := v.abi.Pack("token")
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	msg := ethereum.CallMsg{
+		To:   &v.address,
+		Data: data,
+	}
+
+	output, err := v.client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var result common.Address
+	err = v.abi.UnpackIntoInterface(&result, "token", output)
+	return result, err
+}
+
 // Farm 收益农场合约
 type Farm struct {
 	address common.Address
@@ -202,3 +224,25 @@ func (f *Farm) Earned(ctx context.Context, account common.Address) (*big.Int, er
 	err = f.abi.UnpackIntoInterface(&result, "earned", output)
 	return result, err
 }
+
+// StakingToken 获取质押代币地址
+func (f *Farm) StakingToken(ctx context.Context) (common.Address, error) {
+	data, err := f.abi.Pack("stakingToken")
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	msg := ethereum.CallMsg{
+		To:   &f.address,
+		Data: data,
+	}
+
+	output, err := f.client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var result common.Address
+	err = f.abi.UnpackIntoInterface(&result, "stakingToken", output)
+	return result, err
+}
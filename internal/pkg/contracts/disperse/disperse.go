@@ -0,0 +1,73 @@
+package disperse
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultAddress Disperse合约在主流EVM链上的部署地址
+// 代币分发通过transferFrom从调用者扣款, 授权给该合约不会被第三方利用;
+// Multicall3中子调用的msg.sender为Multicall3自身, 不能用于代币分发
+const DefaultAddress = "0xD152f549545093347A162Dce210e7293f1452150"
+
+// DisperseABI Disperse合约ABI
+const DisperseABI = `[
+    {
+        "inputs": [
+            {"name": "recipients", "type": "address[]"},
+            {"name": "values", "type": "uint256[]"}
+        ],
+        "name": "disperseEther",
+        "outputs": [],
+        "stateMutability": "payable",
+        "type": "function"
+    },
+    {
+        "inputs": [
+            {"name": "token", "type": "address"},
+            {"name": "recipients", "type": "address[]"},
+            {"name": "values", "type": "uint256[]"}
+        ],
+        "name": "disperseToken",
+        "outputs": [],
+        "stateMutability": "nonpayable",
+        "type": "function"
+    }
+]`
+
+// Disperse 批量分发合约
+type Disperse struct {
+	address common.Address
+	abi     abi.ABI
+}
+
+// NewDisperse 创建Disperse实例
+func NewDisperse(address common.Address) (*Disperse, error) {
+	parsed, err := abi.JSON(strings.NewReader(DisperseABI))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Disperse{
+		address: address,
+		abi:     parsed,
+	}, nil
+}
+
+// Address 合约地址
+func (d *Disperse) Address() common.Address {
+	return d.address
+}
+
+// PackDisperseEther 编码原生代币批量分发, 交易value需等于values之和
+func (d *Disperse) PackDisperseEther(recipients []common.Address, values []*big.Int) ([]byte, error) {
+	return d.abi.Pack("disperseEther", recipients, values)
+}
+
+// PackDisperseToken 编码ERC20代币批量分发, 需先授权该合约不少于values之和
+func (d *Disperse) PackDisperseToken(token common.Address, recipients []common.Address, values []*big.Int) ([]byte, error) {
+	return d.abi.Pack("disperseToken", token, recipients, values)
+}
@@ -0,0 +1,143 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultAddress Multicall3在主流EVM链上的统一部署地址
+const DefaultAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// DefaultBatchSize 单次aggregate3调用的最大子调用数
+const DefaultBatchSize = 500
+
+// Multicall3ABI Multicall3合约ABI(仅包含使用到的方法)
+const Multicall3ABI = `[
+    {
+        "inputs": [
+            {
+                "components": [
+                    {"name": "target", "type": "address"},
+                    {"name": "allowFailure", "type": "bool"},
+                    {"name": "callData", "type": "bytes"}
+                ],
+                "name": "calls",
+                "type": "tuple[]"
+            }
+        ],
+        "name": "aggregate3",
+        "outputs": [
+            {
+                "components": [
+                    {"name": "success", "type": "bool"},
+                    {"name": "returnData", "type": "bytes"}
+                ],
+                "name": "returnData",
+                "type": "tuple[]"
+            }
+        ],
+        "stateMutability": "payable",
+        "type": "function"
+    },
+    {
+        "inputs": [{"name": "addr", "type": "address"}],
+        "name": "getEthBalance",
+        "outputs": [{"name": "balance", "type": "uint256"}],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// Call3 aggregate3子调用
+type Call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// Result aggregate3子调用结果
+type Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicall3 Multicall3合约
+type Multicall3 struct {
+	address common.Address
+	abi     abi.ABI
+	client  *ethclient.Client
+}
+
+// NewMulticall3 创建Multicall3实例
+func NewMulticall3(address common.Address, client *ethclient.Client) (*Multicall3, error) {
+	parsed, err := abi.JSON(strings.NewReader(Multicall3ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Multicall3{
+		address: address,
+		abi:     parsed,
+		client:  client,
+	}, nil
+}
+
+// Address 合约地址
+func (m *Multicall3) Address() common.Address {
+	return m.address
+}
+
+// PackGetEthBalance 编码原生代币余额查询
+func (m *Multicall3) PackGetEthBalance(addr common.Address) ([]byte, error) {
+	return m.abi.Pack("getEthBalance", addr)
+}
+
+// PackAggregate3 编码aggregate3调用
+func (m *Multicall3) PackAggregate3(calls []Call3) ([]byte, error) {
+	return m.abi.Pack("aggregate3", calls)
+}
+
+// Aggregate3 批量执行只读调用, 超过DefaultBatchSize时自动分批
+func (m *Multicall3) Aggregate3(ctx context.Context, calls []Call3, blockNumber *big.Int) ([]Result, error) {
+	results := make([]Result, 0, len(calls))
+	for start := 0; start < len(calls); start += DefaultBatchSize {
+		end := start + DefaultBatchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		data, err := m.PackAggregate3(calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := m.client.CallContract(ctx, ethereum.CallMsg{
+			To:   &m.address,
+			Data: data,
+		}, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []Result
+		if err := m.abi.UnpackIntoInterface(&batch, "aggregate3", output); err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+	return results, nil
+}
+
+// UnpackUint256 解码uint256返回值
+func UnpackUint256(data []byte) *big.Int {
+	if len(data) < 32 {
+		return nil
+	}
+	return new(big.Int).SetBytes(data[:32])
+}
@@ -0,0 +1,111 @@
+package cronx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"* * * * *", false},
+		{"*/15 0-6,22 * * *", false},
+		{"5/20 * * * *", false},
+		{"0 9 * jan,jul mon-fri", false},
+		{"0 0 ? * 7", false},
+		{"@daily", false},
+		{"@WEEKLY", false},
+		{"", true},
+		{"* * * *", true},
+		{"* * * * * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * 32 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 8", true},
+		{"*/0 * * * *", true},
+		{"*/x * * * *", true},
+		{"5-1 * * * *", true},
+		{"a * * * *", true},
+		{"* * * foo *", true},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string // 为空表示找不到执行时间
+	}{
+		{"step", "*/15 * * * *", "2024-01-01 10:07:30", "2024-01-01 10:15:00"},
+		{"excludes current minute", "30 10 * * *", "2024-01-01 10:30:00", "2024-01-02 10:30:00"},
+		{"offset step", "5/20 * * * *", "2024-01-01 10:06:00", "2024-01-01 10:25:00"},
+		{"hour rollover", "0 * * * *", "2024-12-31 23:59:00", "2025-01-01 00:00:00"},
+		{"month rollover", "0 0 1 * *", "2024-01-31 12:00:00", "2024-02-01 00:00:00"},
+		{"year rollover", "0 0 1 1 *", "2024-06-01 00:00:00", "2025-01-01 00:00:00"},
+		{"skip short months", "0 0 31 * *", "2024-04-01 00:00:00", "2024-05-31 00:00:00"},
+		{"leap day", "0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"month names", "0 0 1 jul *", "2024-07-01 00:00:00", "2025-07-01 00:00:00"},
+		{"weekdays", "0 12 * * mon-fri", "2024-09-06 13:00:00", "2024-09-09 12:00:00"},
+		{"dow only", "0 0 * * 1", "2024-09-01 00:00:00", "2024-09-02 00:00:00"},
+		{"dom only", "0 0 13 * *", "2024-09-01 00:00:00", "2024-09-13 00:00:00"},
+		{"dom or dow", "0 0 13 * 5", "2024-09-01 00:00:00", "2024-09-06 00:00:00"},
+		{"dom or dow by dom", "0 0 13 * 5", "2024-09-07 00:00:00", "2024-09-13 00:00:00"},
+		{"starred dom step and dow", "0 0 */2 * 1", "2024-09-01 00:00:00", "2024-09-09 00:00:00"},
+		{"dom and starred dow step", "0 0 2 * */3", "2024-09-01 00:00:00", "2024-10-02 00:00:00"},
+		{"seven is sunday", "0 0 * * 7", "2024-09-02 00:00:00", "2024-09-08 00:00:00"},
+		{"sunday name", "0 0 * * sun", "2024-09-02 00:00:00", "2024-09-08 00:00:00"},
+		{"range ending at seven", "0 0 * * 6-7", "2024-09-02 00:00:00", "2024-09-07 00:00:00"},
+		{"descriptor", "@monthly", "2024-02-15 08:00:00", "2024-03-01 00:00:00"},
+		{"impossible february 30", "0 0 30 2 *", "2024-01-01 00:00:00", ""},
+		{"impossible april 31", "0 0 31 4 *", "2024-01-01 00:00:00", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			got := schedule.Next(at(tt.from))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Fatalf("Next(%s) = %s, want zero time", tt.from, got)
+				}
+				return
+			}
+			if want := at(tt.want); !got.Equal(want) {
+				t.Fatalf("Next(%s) = %s, want %s", tt.from, got, want)
+			}
+		})
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	schedule, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := schedule.Next(time.Date(2024, 1, 1, 10, 0, 0, 0, loc))
+	want := time.Date(2024, 1, 2, 9, 0, 0, 0, loc)
+	if !got.Equal(want) || got.Location() != loc {
+		t.Fatalf("Next = %s, want %s", got, want)
+	}
+}
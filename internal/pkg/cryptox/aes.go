@@ -3,41 +3,35 @@ package cryptox
 import (
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"encoding/base64"
-	"io"
 )
 
-const KEY = "your-secret-key-32-bytes-long!@#$"
+// LegacyDecrypter 解密无版本头的旧AES-CFB密文, 仅用于密钥轮换
+type LegacyDecrypter struct {
+	key []byte
+}
 
-func Encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher([]byte(KEY))
-	if err != nil {
-		return "", err
+// NewLegacyDecrypter 创建旧密文解密器
+func NewLegacyDecrypter(key []byte) (*LegacyDecrypter, error) {
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, err
 	}
-	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
-	iv := ciphertext[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return "", err
-	}
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(ciphertext[aes.BlockSize:], []byte(plaintext))
-	return base64.URLEncoding.EncodeToString(ciphertext), nil
+	return &LegacyDecrypter{key: key}, nil
 }
 
-func Decrypt(cryptoText string) (string, error) {
+func (d *LegacyDecrypter) Decrypt(cryptoText string) (string, error) {
 	ciphertext, err := base64.URLEncoding.DecodeString(cryptoText)
 	if err != nil {
 		return "", err
 	}
 
-	block, err := aes.NewCipher([]byte(KEY))
+	block, err := aes.NewCipher(d.key)
 	if err != nil {
 		return "", err
 	}
 
 	if len(ciphertext) < aes.BlockSize {
-		return "", err
+		return "", ErrMalformed
 	}
 	iv := ciphertext[:aes.BlockSize]
 	ciphertext = ciphertext[aes.BlockSize:]
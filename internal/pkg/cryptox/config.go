@@ -0,0 +1,135 @@
+package cryptox
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	ModeGCM      = "gcm"
+	ModeEnvelope = "envelope"
+)
+
+// LoadKeyring 从配置加载密钥环, 环境变量优先于配置文件
+//
+//	crypto.mode              / CRYPTO_MODE               gcm 或 envelope
+//	crypto.primaryKeyId      / CRYPTO_PRIMARY_KEY_ID     GCM当前密钥ID
+//	crypto.keys              / CRYPTO_KEYS               GCM密钥 id=base64,id=base64
+//	crypto.kms.primaryKeyId  / CRYPTO_KMS_PRIMARY_KEY_ID 本地KMS当前主密钥ID
+//	crypto.kms.keys          / CRYPTO_KMS_KEYS           本地KMS主密钥 id=base64,id=base64
+//	crypto.legacyKey         / CRYPTO_LEGACY_KEY         旧版AES-CFB密钥, 仅用于解密
+func LoadKeyring() (*Keyring, error) {
+	ctx := context.Background()
+	mode := loadString(ctx, "crypto.mode", "CRYPTO_MODE")
+	if mode == "" {
+		mode = ModeGCM
+	}
+
+	//1.加载GCM密钥
+	var gcm *GCMEncrypter
+	gcmKeys, err := loadKeys(ctx, "crypto.keys", "CRYPTO_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	if len(gcmKeys) > 0 {
+		gcm, err = NewGCMEncrypter(loadString(ctx, "crypto.primaryKeyId", "CRYPTO_PRIMARY_KEY_ID"), gcmKeys)
+		if err != nil {
+			return nil, fmt.Errorf("crypto.keys: %w", err)
+		}
+	}
+
+	//2.加载信封加密主密钥
+	var envelope *EnvelopeEncrypter
+	kmsKeys, err := loadKeys(ctx, "crypto.kms.keys", "CRYPTO_KMS_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	if len(kmsKeys) > 0 {
+		kms, err := NewLocalKMS(loadString(ctx, "crypto.kms.primaryKeyId", "CRYPTO_KMS_PRIMARY_KEY_ID"), kmsKeys)
+		if err != nil {
+			return nil, fmt.Errorf("crypto.kms.keys: %w", err)
+		}
+		envelope = NewEnvelopeEncrypter(kms)
+	}
+
+	//3.按模式选择主加密器, 其余用于解密历史密文
+	var keyring *Keyring
+	switch mode {
+	case ModeGCM:
+		if gcm == nil {
+			return nil, errors.New("crypto.keys is not configured")
+		}
+		if envelope != nil {
+			keyring = NewKeyring(gcm, envelope)
+		} else {
+			keyring = NewKeyring(gcm)
+		}
+	case ModeEnvelope:
+		if envelope == nil {
+			return nil, errors.New("crypto.kms.keys is not configured")
+		}
+		if gcm != nil {
+			keyring = NewKeyring(envelope, gcm)
+		} else {
+			keyring = NewKeyring(envelope)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported crypto.mode: %s", mode)
+	}
+
+	//4.旧版密文解密
+	if legacyKey := loadString(ctx, "crypto.legacyKey", "CRYPTO_LEGACY_KEY"); legacyKey != "" {
+		legacy, err := NewLegacyDecrypter([]byte(legacyKey))
+		if err != nil {
+			return nil, fmt.Errorf("crypto.legacyKey: %w", err)
+		}
+		keyring.WithLegacy(legacy)
+	}
+
+	return keyring, nil
+}
+
+// loadString 读取字符串配置, 环境变量优先
+func loadString(ctx context.Context, pattern, env string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return g.Cfg().MustGet(ctx, pattern).String()
+}
+
+// loadKeys 读取密钥映射, 环境变量格式为 id=base64,id=base64
+func loadKeys(ctx context.Context, pattern, env string) (map[string][]byte, error) {
+	encoded := make(map[string]string)
+	if v := os.Getenv(env); v != "" {
+		for _, item := range strings.Split(v, ",") {
+			id, key, ok := strings.Cut(strings.TrimSpace(item), "=")
+			if !ok {
+				return nil, fmt.Errorf("%s: invalid key entry", env)
+			}
+			encoded[id] = key
+		}
+	} else {
+		for id, key := range g.Cfg().MustGet(ctx, pattern).MapStrStr() {
+			encoded[id] = key
+		}
+	}
+
+	keys := make(map[string][]byte, len(encoded))
+	for id, key := range encoded {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("%s: key id %q must not contain ':'", pattern, id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %s is not valid base64", pattern, id)
+		}
+		keys[id] = raw
+	}
+	return keys, nil
+}
@@ -0,0 +1,167 @@
+package cryptox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownVersion = errors.New("unknown ciphertext version")
+	ErrUnknownKeyId   = errors.New("unknown encryption key id")
+	ErrMalformed      = errors.New("malformed ciphertext")
+)
+
+// KeyEncrypter 密钥加密器, 密文格式为 "<version>:<keyId>:<payload>"
+type KeyEncrypter interface {
+	// Version 密文版本前缀
+	Version() string
+	// KeyId 当前用于加密的密钥ID
+	KeyId() string
+	// Encrypt 加密明文, 返回带版本头的密文
+	Encrypt(plaintext []byte) (string, error)
+	// Decrypt 解密带版本头的密文
+	Decrypt(ciphertext string) ([]byte, error)
+}
+
+// Keyring 密钥环, 使用主加密器加密, 按版本头选择解密器
+type Keyring struct {
+	primary    KeyEncrypter
+	decrypters map[string]KeyEncrypter
+	legacy     *LegacyDecrypter
+}
+
+// NewKeyring 创建密钥环, others用于解密历史版本密文
+func NewKeyring(primary KeyEncrypter, others ...KeyEncrypter) *Keyring {
+	k := &Keyring{
+		primary:    primary,
+		decrypters: map[string]KeyEncrypter{primary.Version(): primary},
+	}
+	for _, e := range others {
+		if _, ok := k.decrypters[e.Version()]; !ok {
+			k.decrypters[e.Version()] = e
+		}
+	}
+	return k
+}
+
+// WithLegacy 设置无版本头旧密文的解密器
+func (k *Keyring) WithLegacy(legacy *LegacyDecrypter) *Keyring {
+	k.legacy = legacy
+	return k
+}
+
+// Encrypt 使用主加密器加密
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	return k.primary.Encrypt([]byte(plaintext))
+}
+
+// Decrypt 按版本头解密
+func (k *Keyring) Decrypt(ciphertext string) (string, error) {
+	version, _, _, err := parseHeader(ciphertext)
+	if err != nil {
+		// 无版本头, 尝试按旧格式解密
+		if k.legacy != nil {
+			return k.legacy.Decrypt(ciphertext)
+		}
+		return "", err
+	}
+	e, ok := k.decrypters[version]
+	if !ok {
+		return "", ErrUnknownVersion
+	}
+	plaintext, err := e.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation 判断密文是否需要使用主密钥重新加密
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	if ciphertext == "" {
+		return false
+	}
+	version, keyId, _, err := parseHeader(ciphertext)
+	if err != nil {
+		return true
+	}
+	return version != k.primary.Version() || keyId != k.primary.KeyId()
+}
+
+// Rotate 解密后使用主密钥重新加密, 无需轮换时原样返回
+func (k *Keyring) Rotate(ciphertext string) (string, bool, error) {
+	if !k.NeedsRotation(ciphertext) {
+		return ciphertext, false, nil
+	}
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil {
+		return "", false, err
+	}
+	rotated, err := k.Encrypt(plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return rotated, true, nil
+}
+
+// formatHeader 拼接带版本头的密文
+func formatHeader(version, keyId, payload string) string {
+	return fmt.Sprintf("%s:%s:%s", version, keyId, payload)
+}
+
+// parseHeader 解析密文版本头
+func parseHeader(ciphertext string) (version, keyId, payload string, err error) {
+	parts := strings.SplitN(ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", ErrMalformed
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+var (
+	defaultKeyring *Keyring
+	defaultErr     error
+	defaultOnce    sync.Once
+	defaultMutex   sync.RWMutex
+)
+
+// Default 获取默认密钥环, 首次调用时从配置加载
+func Default() (*Keyring, error) {
+	defaultOnce.Do(func() {
+		keyring, err := LoadKeyring()
+		defaultMutex.Lock()
+		defaultKeyring, defaultErr = keyring, err
+		defaultMutex.Unlock()
+	})
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultKeyring, defaultErr
+}
+
+// SetDefault 替换默认密钥环
+func SetDefault(k *Keyring) {
+	defaultOnce.Do(func() {})
+	defaultMutex.Lock()
+	defaultKeyring, defaultErr = k, nil
+	defaultMutex.Unlock()
+}
+
+// Encrypt 使用默认密钥环加密
+func Encrypt(plaintext string) (string, error) {
+	keyring, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return keyring.Encrypt(plaintext)
+}
+
+// Decrypt 使用默认密钥环解密
+func Decrypt(cryptoText string) (string, error) {
+	keyring, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return keyring.Decrypt(cryptoText)
+}
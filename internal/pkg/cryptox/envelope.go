@@ -0,0 +1,137 @@
+package cryptox
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// VersionEnvelope 信封加密: 每条数据使用独立数据密钥, 数据密钥由主密钥包裹
+const VersionEnvelope = "v2"
+
+// KMS 主密钥管理服务
+type KMS interface {
+	// PrimaryKeyId 当前用于包裹数据密钥的主密钥ID
+	PrimaryKeyId() string
+	// WrapKey 使用主密钥包裹数据密钥
+	WrapKey(keyId string, dek []byte) ([]byte, error)
+	// UnwrapKey 使用主密钥解开数据密钥
+	UnwrapKey(keyId string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKMS 本地KMS替身, 主密钥保存在进程内存中
+type LocalKMS struct {
+	primaryKeyId string
+	keys         map[string][]byte
+}
+
+// NewLocalKMS 创建本地KMS, keys为keyId到32字节主密钥的映射
+func NewLocalKMS(primaryKeyId string, keys map[string][]byte) (*LocalKMS, error) {
+	if _, ok := keys[primaryKeyId]; !ok {
+		return nil, ErrUnknownKeyId
+	}
+	for _, key := range keys {
+		if len(key) != 32 {
+			return nil, errors.New("kms master key must be 32 bytes")
+		}
+	}
+	return &LocalKMS{primaryKeyId: primaryKeyId, keys: keys}, nil
+}
+
+func (k *LocalKMS) PrimaryKeyId() string {
+	return k.primaryKeyId
+}
+
+func (k *LocalKMS) WrapKey(keyId string, dek []byte) ([]byte, error) {
+	key, ok := k.keys[keyId]
+	if !ok {
+		return nil, ErrUnknownKeyId
+	}
+	return sealGCM(key, dek, []byte(keyId))
+}
+
+func (k *LocalKMS) UnwrapKey(keyId string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyId]
+	if !ok {
+		return nil, ErrUnknownKeyId
+	}
+	return openGCM(key, wrapped, []byte(keyId))
+}
+
+// EnvelopeEncrypter 信封加密器
+// 密文格式 "v2:<masterKeyId>:<base64(wrappedDEK)>.<base64(nonce||ciphertext)>"
+type EnvelopeEncrypter struct {
+	kms KMS
+}
+
+// NewEnvelopeEncrypter 创建信封加密器
+func NewEnvelopeEncrypter(kms KMS) *EnvelopeEncrypter {
+	return &EnvelopeEncrypter{kms: kms}
+}
+
+func (e *EnvelopeEncrypter) Version() string {
+	return VersionEnvelope
+}
+
+func (e *EnvelopeEncrypter) KeyId() string {
+	return e.kms.PrimaryKeyId()
+}
+
+func (e *EnvelopeEncrypter) Encrypt(plaintext []byte) (string, error) {
+	//1.生成数据密钥
+	dek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", err
+	}
+
+	//2.使用数据密钥加密数据
+	keyId := e.kms.PrimaryKeyId()
+	sealed, err := sealGCM(dek, plaintext, []byte(keyId))
+	if err != nil {
+		return "", err
+	}
+
+	//3.使用主密钥包裹数据密钥
+	wrapped, err := e.kms.WrapKey(keyId, dek)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(wrapped) + "." + base64.RawURLEncoding.EncodeToString(sealed)
+	return formatHeader(VersionEnvelope, keyId, payload), nil
+}
+
+func (e *EnvelopeEncrypter) Decrypt(ciphertext string) ([]byte, error) {
+	version, keyId, payload, err := parseHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if version != VersionEnvelope {
+		return nil, ErrUnknownVersion
+	}
+
+	//1.拆分包裹密钥与密文
+	parts := strings.SplitN(payload, ".", 2)
+	if len(parts) != 2 {
+		return nil, ErrMalformed
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	//2.解开数据密钥
+	dek, err := e.kms.UnwrapKey(keyId, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	//3.解密数据
+	return openGCM(dek, sealed, []byte(keyId))
+}
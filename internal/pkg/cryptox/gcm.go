@@ -0,0 +1,101 @@
+package cryptox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+// VersionGCM AES-256-GCM直接加密
+const VersionGCM = "v1"
+
+// GCMEncrypter 使用AES-256-GCM直接加密, 支持多个密钥ID用于轮换
+type GCMEncrypter struct {
+	keyId string
+	keys  map[string][]byte
+}
+
+// NewGCMEncrypter 创建GCM加密器, keys为keyId到32字节密钥的映射
+func NewGCMEncrypter(primaryKeyId string, keys map[string][]byte) (*GCMEncrypter, error) {
+	if _, ok := keys[primaryKeyId]; !ok {
+		return nil, ErrUnknownKeyId
+	}
+	for _, key := range keys {
+		if len(key) != 32 {
+			return nil, errors.New("aes-256-gcm key must be 32 bytes")
+		}
+	}
+	return &GCMEncrypter{keyId: primaryKeyId, keys: keys}, nil
+}
+
+func (e *GCMEncrypter) Version() string {
+	return VersionGCM
+}
+
+func (e *GCMEncrypter) KeyId() string {
+	return e.keyId
+}
+
+func (e *GCMEncrypter) Encrypt(plaintext []byte) (string, error) {
+	sealed, err := sealGCM(e.keys[e.keyId], plaintext, []byte(e.keyId))
+	if err != nil {
+		return "", err
+	}
+	return formatHeader(VersionGCM, e.keyId, base64.RawURLEncoding.EncodeToString(sealed)), nil
+}
+
+func (e *GCMEncrypter) Decrypt(ciphertext string) ([]byte, error) {
+	version, keyId, payload, err := parseHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if version != VersionGCM {
+		return nil, ErrUnknownVersion
+	}
+	key, ok := e.keys[keyId]
+	if !ok {
+		return nil, ErrUnknownKeyId
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return openGCM(key, sealed, []byte(keyId))
+}
+
+// sealGCM 加密并返回 nonce||ciphertext
+func sealGCM(key, plaintext, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// openGCM 解密 nonce||ciphertext
+func openGCM(key, sealed, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
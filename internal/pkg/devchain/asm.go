@@ -0,0 +1,156 @@
+package devchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// errorSelector Error(string)的选择器, 用于返回revert原因
+const errorSelector = "0x08c379a0"
+
+// assemble 渲染EVM汇编模板并编译为运行时字节码
+// 模板函数:
+//   - dispatch: 读取calldata中的函数选择器
+//   - route "方法名或签名" "标签": 选择器匹配时跳转到标签
+//   - selectorWord "方法名或签名": 左对齐到一个字的选择器, 用于构造外部调用
+//   - topic "事件名或签名": 事件topic0
+//   - revert "原因": 以Error(string)回滚
+//   - returnString "内容": 返回ABI编码的字符串
+func assemble(name, source string, parsed abi.ABI, data interface{}) ([]byte, error) {
+	funcs := template.FuncMap{
+		"dispatch": func() string {
+			return lines("PUSH 0", "CALLDATALOAD", "PUSH 0xe0", "SHR")
+		},
+		"route": func(method, label string) (string, error) {
+			id, err := methodID(parsed, method)
+			if err != nil {
+				return "", err
+			}
+			return lines("DUP1", "PUSH 0x"+hex.EncodeToString(id), "EQ", "JUMPI @"+label), nil
+		},
+		"selectorWord": func(method string) (string, error) {
+			id, err := methodID(parsed, method)
+			if err != nil {
+				return "", err
+			}
+			return "0x" + hex.EncodeToString(common.RightPadBytes(id, 32)), nil
+		},
+		"topic": func(event string) (string, error) {
+			topic, err := eventTopic(parsed, event)
+			if err != nil {
+				return "", err
+			}
+			return topic.Hex(), nil
+		},
+		"revert": func(reason string) (string, error) {
+			word, err := wordOf(reason)
+			if err != nil {
+				return "", err
+			}
+			selector := common.RightPadBytes(common.FromHex(errorSelector), 32)
+			return lines(
+				"PUSH 0x"+hex.EncodeToString(selector), "PUSH 0", "MSTORE",
+				"PUSH 0x20", "PUSH 4", "MSTORE",
+				fmt.Sprintf("PUSH %d", len(reason)), "PUSH 0x24", "MSTORE",
+				"PUSH "+word, "PUSH 0x44", "MSTORE",
+				"PUSH 0x64", "PUSH 0", "REVERT",
+			), nil
+		},
+		"returnString": func(value string) (string, error) {
+			word, err := wordOf(value)
+			if err != nil {
+				return "", err
+			}
+			return lines(
+				"PUSH 0x20", "PUSH 0", "MSTORE",
+				fmt.Sprintf("PUSH %d", len(value)), "PUSH 0x20", "MSTORE",
+				"PUSH "+word, "PUSH 0x40", "MSTORE",
+				"PUSH 0x60", "PUSH 0", "RETURN",
+			), nil
+		},
+	}
+
+	//1.渲染模板
+	tpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	//2.编译
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex(buf.Bytes(), false))
+	code, errs := compiler.Compile()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("assemble %s: %v", name, errs[0])
+	}
+	return hex.DecodeString(code)
+}
+
+// creationCode 生成部署字节码: 将运行时字节码复制到内存并返回
+func creationCode(runtime []byte) []byte {
+	// PUSH2 len DUP1 PUSH2 offset PUSH1 0 CODECOPY PUSH1 0 RETURN
+	const prefixLen = 13
+	size := len(runtime)
+	prefix := []byte{
+		0x61, byte(size >> 8), byte(size),
+		0x80,
+		0x61, 0x00, prefixLen,
+		0x60, 0x00,
+		0x39,
+		0x60, 0x00,
+		0xf3,
+	}
+	return append(prefix, runtime...)
+}
+
+// methodID 按方法名从ABI中查找选择器, 传入完整签名时直接计算
+func methodID(parsed abi.ABI, method string) ([]byte, error) {
+	if strings.Contains(method, "(") {
+		return crypto.Keccak256([]byte(method))[:4], nil
+	}
+	m, ok := parsed.Methods[method]
+	if !ok {
+		return nil, fmt.Errorf("method %s not found in abi", method)
+	}
+	return m.ID, nil
+}
+
+// eventTopic 按事件名从ABI中查找topic0, 传入完整签名时直接计算
+func eventTopic(parsed abi.ABI, event string) (common.Hash, error) {
+	if strings.Contains(event, "(") {
+		return crypto.Keccak256Hash([]byte(event)), nil
+	}
+	e, ok := parsed.Events[event]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("event %s not found in abi", event)
+	}
+	return e.ID, nil
+}
+
+// wordOf 将不超过32字节的字符串右补零为一个字
+func wordOf(value string) (string, error) {
+	if len(value) > 32 {
+		return "", fmt.Errorf("%q is longer than 32 bytes", value)
+	}
+	if value == "" {
+		return "0", nil
+	}
+	return "0x" + hex.EncodeToString(common.RightPadBytes([]byte(value), 32)), nil
+}
+
+// lines 拼接汇编指令, 每条一行
+func lines(ops ...string) string {
+	return "\n    " + strings.Join(ops, "\n    ") + "\n"
+}
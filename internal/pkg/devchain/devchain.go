@@ -0,0 +1,321 @@
+package devchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"go-wallet-defi/internal/pkg/contracts/disperse"
+	"go-wallet-defi/internal/pkg/contracts/multicall"
+	"go-wallet-defi/internal/pkg/hdwallet"
+)
+
+// DefaultMnemonic Hardhat/Foundry通用的测试助记词, 仅用于本地开发
+const DefaultMnemonic = "test test test test test test test test test test test junk"
+
+// Config 模拟链配置
+type Config struct {
+	Mnemonic  string        // 预置账户助记词
+	Accounts  int           // 预置账户数量
+	Balance   *big.Int      // 每个账户的初始余额(wei)
+	Host      string        // RPC监听地址
+	HTTPPort  int           // HTTP RPC端口
+	WSPort    int           // websocket RPC端口
+	BlockTime time.Duration // 自动出块间隔, 0表示不自动出块
+}
+
+// Account 预置账户
+type Account struct {
+	Index   uint32
+	Address common.Address
+	Key     *ecdsa.PrivateKey
+}
+
+// Deployment 已部署的模拟合约
+type Deployment struct {
+	Name     string
+	Kind     string
+	Address  common.Address
+	ABI      string
+	Bytecode []byte
+	TxHash   common.Hash
+	Block    uint64
+}
+
+// Node 进程内的模拟链节点, 通过HTTP/websocket对外提供JSON-RPC
+type Node struct {
+	ChainId     *big.Int
+	HTTPURL     string
+	WSURL       string
+	Accounts    []*Account
+	Predeployed []*Deployment // 创世区块中预置在默认地址的Multicall3与Disperse
+
+	backend *simulated.Backend
+	client  simulated.Client
+	mutex   sync.Mutex // 串行化出块
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Start 启动模拟链: 为预置账户分配余额, 开放HTTP/websocket RPC, 并按间隔自动出块
+func Start(config Config) (dev *Node, err error) {
+	//1.派生预置账户
+	if config.Accounts <= 0 {
+		return nil, errors.New("at least one account is required")
+	}
+	accounts := make([]*Account, 0, config.Accounts)
+	alloc := make(types.GenesisAlloc)
+	for i := 0; i < config.Accounts; i++ {
+		key, err := hdwallet.DeriveFromMnemonic(config.Mnemonic, "", hdwallet.AccountPath(uint32(i)))
+		if err != nil {
+			return nil, err
+		}
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		accounts = append(accounts, &Account{Index: uint32(i), Address: address, Key: key})
+		alloc[address] = types.Account{Balance: new(big.Int).Set(config.Balance)}
+	}
+
+	//2.在默认地址预置Multicall3与Disperse, 余额查询与批量付款无需额外配置
+	var predeployed []*Deployment
+	for _, item := range []struct {
+		name    string
+		mock    *Mock
+		address common.Address
+	}{
+		{"Multicall3", MockMulticall3, common.HexToAddress(multicall.DefaultAddress)},
+		{"Disperse", MockDisperse, common.HexToAddress(disperse.DefaultAddress)},
+	} {
+		abiJson, err := item.mock.ABI()
+		if err != nil {
+			return nil, err
+		}
+		runtime, err := item.mock.Runtime(MockParams{})
+		if err != nil {
+			return nil, err
+		}
+		alloc[item.address] = types.Account{Code: runtime, Balance: new(big.Int)}
+		predeployed = append(predeployed, &Deployment{
+			Name:     item.name,
+			Kind:     item.mock.Kind,
+			Address:  item.address,
+			ABI:      abiJson,
+			Bytecode: runtime,
+		})
+	}
+
+	//3.启动节点, 端口被占用等错误在模拟后端中以panic抛出
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("start simulated backend: %v", r)
+		}
+	}()
+	backend := simulated.NewBackend(alloc, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		nodeConf.HTTPHost = config.Host
+		nodeConf.HTTPPort = config.HTTPPort
+		nodeConf.HTTPModules = []string{"eth", "net", "web3", "txpool"}
+		nodeConf.HTTPVirtualHosts = []string{"*"}
+		nodeConf.HTTPCors = []string{"*"}
+		nodeConf.WSHost = config.Host
+		nodeConf.WSPort = config.WSPort
+		nodeConf.WSModules = []string{"eth", "net", "web3", "txpool"}
+		nodeConf.WSOrigins = []string{"*"}
+	})
+
+	dev = &Node{
+		ChainId:     params.AllDevChainProtocolChanges.ChainID,
+		HTTPURL:     fmt.Sprintf("http://%s:%d", config.Host, config.HTTPPort),
+		WSURL:       fmt.Sprintf("ws://%s:%d", config.Host, config.WSPort),
+		Accounts:    accounts,
+		Predeployed: predeployed,
+		backend:     backend,
+		client:      backend.Client(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	//4.自动出块
+	go dev.mine(config.BlockTime)
+	return dev, nil
+}
+
+// Client 进程内客户端
+func (n *Node) Client() simulated.Client {
+	return n.client
+}
+
+// Commit 立即出块
+func (n *Node) Commit() common.Hash {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.backend.Commit()
+}
+
+// mine 按间隔出块直到节点关闭
+func (n *Node) mine(interval time.Duration) {
+	defer close(n.done)
+	if interval <= 0 {
+		<-n.stop
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+			n.Commit()
+		}
+	}
+}
+
+// Close 停止出块并关闭节点
+func (n *Node) Close() error {
+	close(n.stop)
+	<-n.done
+	return n.backend.Close()
+}
+
+// Deploy 由第一个预置账户部署模拟合约
+func (n *Node) Deploy(ctx context.Context, name string, mock *Mock, params MockParams) (*Deployment, error) {
+	abiJson, err := mock.ABI()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		return nil, err
+	}
+	code, err := mock.Code(params)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := n.transactor(ctx, n.Accounts[0])
+	if err != nil {
+		return nil, err
+	}
+	address, tx, _, err := bind.DeployContract(opts, parsed, code, n.client)
+	if err != nil {
+		return nil, fmt.Errorf("deploy %s: %w", name, err)
+	}
+	receipt, err := n.confirm(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("deploy %s: %w", name, err)
+	}
+	return &Deployment{
+		Name:     name,
+		Kind:     mock.Kind,
+		Address:  address,
+		ABI:      abiJson,
+		Bytecode: code,
+		TxHash:   tx.Hash(),
+		Block:    receipt.BlockNumber.Uint64(),
+	}, nil
+}
+
+// Transact 由指定账户调用已部署的合约方法并等待上链
+func (n *Node) Transact(ctx context.Context, from *Account, deployment *Deployment, method string, args ...interface{}) error {
+	parsed, err := abi.JSON(strings.NewReader(deployment.ABI))
+	if err != nil {
+		return err
+	}
+	opts, err := n.transactor(ctx, from)
+	if err != nil {
+		return err
+	}
+	contract := bind.NewBoundContract(deployment.Address, parsed, n.client, n.client, n.client)
+	tx, err := contract.Transact(opts, method, args...)
+	if err != nil {
+		return fmt.Errorf("%s.%s: %w", deployment.Name, method, err)
+	}
+	if _, err := n.confirm(ctx, tx); err != nil {
+		return fmt.Errorf("%s.%s: %w", deployment.Name, method, err)
+	}
+	return nil
+}
+
+// transactor 创建账户的交易签名参数
+func (n *Node) transactor(ctx context.Context, account *Account) (*bind.TransactOpts, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(account.Key, n.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	return opts, nil
+}
+
+// confirm 出块并确认交易执行成功
+func (n *Node) confirm(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	n.Commit()
+	receipt, err := bind.WaitMined(ctx, n.client, tx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return receipt, nil
+}
+
+// DeployMocks 部署开发环境使用的全部模拟合约, 并为每个预置账户铸造1,000,000个ERC20代币
+func (n *Node) DeployMocks(ctx context.Context) ([]*Deployment, error) {
+	//1.部署代币
+	usdc, err := n.Deploy(ctx, "Mock USDC", MockERC20, MockParams{Name: "USD Coin", Symbol: "USDC", Decimals: 6})
+	if err != nil {
+		return nil, err
+	}
+	weth, err := n.Deploy(ctx, "Mock WETH", MockERC20, MockParams{Name: "Wrapped Ether", Symbol: "WETH", Decimals: 18})
+	if err != nil {
+		return nil, err
+	}
+	deployments := []*Deployment{usdc, weth}
+
+	//2.部署NFT与DeFi合约
+	for _, item := range []struct {
+		name   string
+		mock   *Mock
+		params MockParams
+	}{
+		{"Mock NFT", MockERC721, MockParams{Name: "Dev NFT", Symbol: "DNFT"}},
+		{"Mock UniswapV2 Router", MockRouter, MockParams{}},
+		{"Mock Lending Pool", MockLendingPool, MockParams{}},
+		{"Mock Farm", MockFarm, MockParams{Token: usdc.Address}},
+		{"Mock Vault", MockVault, MockParams{Token: usdc.Address}},
+		{"Mock Bridge", MockBridge, MockParams{}},
+	} {
+		deployment, err := n.Deploy(ctx, item.name, item.mock, item.params)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	//3.铸造代币
+	for _, item := range []struct {
+		token    *Deployment
+		decimals int64
+	}{{usdc, 6}, {weth, 18}} {
+		amount := new(big.Int).Mul(big.NewInt(1000000), new(big.Int).Exp(big.NewInt(10), big.NewInt(item.decimals), nil))
+		for _, account := range n.Accounts {
+			if err := n.Transact(ctx, n.Accounts[0], item.token, "mint", account.Address, amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return deployments, nil
+}
@@ -0,0 +1,408 @@
+package devchain
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go-wallet-defi/internal/pkg/contracts/bridge"
+	"go-wallet-defi/internal/pkg/contracts/defi"
+	"go-wallet-defi/internal/pkg/contracts/multicall"
+	"go-wallet-defi/internal/pkg/contracts/token"
+)
+
+// testChain 已部署全部模拟合约的模拟链, 通过HTTP RPC与internal/pkg/contracts中的绑定交互
+type testChain struct {
+	t           *testing.T
+	ctx         context.Context
+	node        *Node
+	client      *ethclient.Client
+	deployments map[string]*Deployment
+}
+
+// freePort 获取一个空闲的本地端口
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func newTestChain(t *testing.T) *testChain {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+
+	node, err := Start(Config{
+		Mnemonic: DefaultMnemonic,
+		Accounts: 2,
+		Balance:  new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+		Host:     "127.0.0.1",
+		HTTPPort: freePort(t),
+		WSPort:   freePort(t),
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { node.Close() })
+
+	deployed, err := node.DeployMocks(ctx)
+	if err != nil {
+		t.Fatalf("DeployMocks() error = %v", err)
+	}
+	deployments := make(map[string]*Deployment)
+	for _, deployment := range append(node.Predeployed, deployed...) {
+		deployments[deployment.Name] = deployment
+	}
+
+	client, err := ethclient.DialContext(ctx, node.HTTPURL)
+	if err != nil {
+		t.Fatalf("dial %s: %v", node.HTTPURL, err)
+	}
+	t.Cleanup(client.Close)
+
+	return &testChain{t: t, ctx: ctx, node: node, client: client, deployments: deployments}
+}
+
+// address 按名称查找已部署合约的地址
+func (c *testChain) address(name string) common.Address {
+	c.t.Helper()
+	deployment, ok := c.deployments[name]
+	if !ok {
+		c.t.Fatalf("mock %q not deployed", name)
+	}
+	return deployment.Address
+}
+
+// call 只读调用合约
+func (c *testChain) call(to common.Address, data []byte) ([]byte, error) {
+	return c.client.CallContract(c.ctx, ethereum.CallMsg{From: c.node.Accounts[0].Address, To: &to, Data: data}, nil)
+}
+
+// send 由指定账户签名发送交易, 出块并返回成功的回执
+func (c *testChain) send(from *Account, to common.Address, data []byte) *types.Receipt {
+	c.t.Helper()
+	nonce, err := c.client.PendingNonceAt(c.ctx, from.Address)
+	if err != nil {
+		c.t.Fatalf("PendingNonceAt() error = %v", err)
+	}
+	gas, err := c.client.EstimateGas(c.ctx, ethereum.CallMsg{From: from.Address, To: &to, Data: data})
+	if err != nil {
+		c.t.Fatalf("EstimateGas() error = %v", err)
+	}
+	gasPrice, err := c.client.SuggestGasPrice(c.ctx)
+	if err != nil {
+		c.t.Fatalf("SuggestGasPrice() error = %v", err)
+	}
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Gas:      gas,
+		GasPrice: gasPrice,
+		Data:     data,
+	}), types.LatestSignerForChainID(c.node.ChainId), from.Key)
+	if err != nil {
+		c.t.Fatalf("SignTx() error = %v", err)
+	}
+	if err := c.client.SendTransaction(c.ctx, tx); err != nil {
+		c.t.Fatalf("SendTransaction() error = %v", err)
+	}
+	c.node.Commit()
+	receipt, err := bind.WaitMined(c.ctx, c.client, tx)
+	if err != nil {
+		c.t.Fatalf("WaitMined() error = %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		c.t.Fatalf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return receipt
+}
+
+func mustABI(t *testing.T, abiJson string) abi.ABI {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		t.Fatalf("parse abi: %v", err)
+	}
+	return parsed
+}
+
+func units(amount int64, decimals int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil))
+}
+
+func TestDeployMocks(t *testing.T) {
+	c := newTestChain(t)
+	for _, name := range []string{
+		"Multicall3", "Disperse", "Mock USDC", "Mock WETH", "Mock NFT", "Mock UniswapV2 Router",
+		"Mock Lending Pool", "Mock Farm", "Mock Vault", "Mock Bridge",
+	} {
+		code, err := c.client.CodeAt(c.ctx, c.address(name), nil)
+		if err != nil {
+			t.Fatalf("CodeAt(%s) error = %v", name, err)
+		}
+		if len(code) == 0 {
+			t.Errorf("%s has no code", name)
+		}
+	}
+
+	// 预置的Multicall3可直接用于余额查询
+	mc, err := multicall.NewMulticall3(c.address("Multicall3"), c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := mc.PackGetEthBalance(c.node.Accounts[1].Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := mc.Aggregate3(c.ctx, []multicall.Call3{{Target: mc.Address(), AllowFailure: false, CallData: data}}, nil)
+	if err != nil {
+		t.Fatalf("Aggregate3() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("Aggregate3() = %+v", results)
+	}
+	if got, want := multicall.UnpackUint256(results[0].ReturnData), units(100, 18); got.Cmp(want) != 0 {
+		t.Errorf("getEthBalance = %s, want %s", got, want)
+	}
+}
+
+func TestERC20TransferAndApprove(t *testing.T) {
+	c := newTestChain(t)
+	owner, receiver := c.node.Accounts[0], c.node.Accounts[1]
+	usdc, err := token.NewERC20(c.address("Mock USDC"), c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//1.元数据与铸造余额
+	if got, err := usdc.Name(); err != nil || got != "USD Coin" {
+		t.Errorf("Name() = %q, %v", got, err)
+	}
+	if got, err := usdc.Symbol(); err != nil || got != "USDC" {
+		t.Errorf("Symbol() = %q, %v", got, err)
+	}
+	if got, err := usdc.Decimals(); err != nil || got != 6 {
+		t.Errorf("Decimals() = %d, %v", got, err)
+	}
+	minted := units(1000000, 6)
+	if got, err := usdc.BalanceOf(receiver.Address); err != nil || got.Cmp(minted) != 0 {
+		t.Fatalf("BalanceOf() = %v, %v, want %s", got, err, minted)
+	}
+
+	//2.转账并校验Transfer事件
+	amount := units(250, 6)
+	data, err := usdc.PackTransfer(receiver.Address, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := c.send(owner, c.address("Mock USDC"), data)
+	if len(receipt.Logs) != 1 || receipt.Logs[0].Topics[0] != crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")) {
+		t.Fatalf("transfer logs = %+v", receipt.Logs)
+	}
+	if got, want := receipt.Logs[0].Topics[2], common.BytesToHash(receiver.Address.Bytes()); got != want {
+		t.Errorf("Transfer to = %s, want %s", got, want)
+	}
+	if got, _ := usdc.BalanceOf(owner.Address); got.Cmp(new(big.Int).Sub(minted, amount)) != 0 {
+		t.Errorf("sender balance = %s", got)
+	}
+	if got, _ := usdc.BalanceOf(receiver.Address); got.Cmp(new(big.Int).Add(minted, amount)) != 0 {
+		t.Errorf("receiver balance = %s", got)
+	}
+
+	//3.余额不足的转账回滚
+	data, err = usdc.PackTransfer(owner.Address, units(2000000, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.call(c.address("Mock USDC"), data); err == nil {
+		t.Error("transfer exceeding balance did not revert")
+	}
+
+	//4.授权
+	spender := c.address("Mock UniswapV2 Router")
+	data, err = usdc.PackApprove(spender, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.send(owner, c.address("Mock USDC"), data)
+	if got, err := usdc.Allowance(owner.Address, spender); err != nil || got.Cmp(amount) != 0 {
+		t.Errorf("Allowance() = %v, %v, want %s", got, err, amount)
+	}
+	if got, err := usdc.Allowance(receiver.Address, spender); err != nil || got.Sign() != 0 {
+		t.Errorf("Allowance() of other owner = %v, %v, want 0", got, err)
+	}
+}
+
+func TestRouterSwap(t *testing.T) {
+	c := newTestChain(t)
+	owner := c.node.Accounts[0]
+	address := c.address("Mock UniswapV2 Router")
+	router, err := defi.NewUniswapV2Router(address, c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := mustABI(t, defi.UniswapV2RouterABI)
+	path := []common.Address{c.address("Mock USDC"), c.address("Mock WETH")}
+	amount := units(10, 6)
+	deadline := big.NewInt(time.Now().Add(time.Hour).Unix())
+
+	//1.按1:1返回兑换数量
+	data, err := router.PackSwapExactTokensForTokens(amount, big.NewInt(0), path, owner.Address, deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := c.call(address, data)
+	if err != nil {
+		t.Fatalf("swapExactTokensForTokens error = %v", err)
+	}
+	var amounts []*big.Int
+	if err := parsed.UnpackIntoInterface(&amounts, "swapExactTokensForTokens", output); err != nil {
+		t.Fatal(err)
+	}
+	if len(amounts) != 2 || amounts[0].Cmp(amount) != 0 || amounts[1].Cmp(amount) != 0 {
+		t.Errorf("amounts = %v, want [%s %s]", amounts, amount, amount)
+	}
+	c.send(owner, address, data)
+
+	//2.过期的deadline回滚
+	data, err = router.PackSwapExactTokensForTokens(amount, big.NewInt(0), path, owner.Address, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.call(address, data); err == nil || !strings.Contains(err.Error(), "EXPIRED") {
+		t.Errorf("expired swap error = %v, want EXPIRED revert", err)
+	}
+}
+
+func TestDeposit(t *testing.T) {
+	c := newTestChain(t)
+	owner := c.node.Accounts[0]
+	usdc := c.address("Mock USDC")
+	amount := units(500, 6)
+
+	//1.收益金库: 份额与资产1:1
+	vaultAddress := c.address("Mock Vault")
+	vault, err := defi.NewYearnVault(vaultAddress, c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := vault.PackDeposit(amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := c.call(vaultAddress, data)
+	if err != nil {
+		t.Fatalf("deposit error = %v", err)
+	}
+	if got := new(big.Int).SetBytes(output); got.Cmp(amount) != 0 {
+		t.Errorf("deposit shares = %s, want %s", got, amount)
+	}
+	c.send(owner, vaultAddress, data)
+	if got, err := vault.PricePerShare(c.ctx); err != nil || got.Cmp(units(1, 18)) != 0 {
+		t.Errorf("PricePerShare() = %v, %v", got, err)
+	}
+	if got, err := vault.Token(c.ctx); err != nil || got != usdc {
+		t.Errorf("Token() = %s, %v, want %s", got, err, usdc)
+	}
+
+	//2.借贷池存入
+	pool, err := defi.NewAavePool(c.address("Mock Lending Pool"), c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = pool.PackSupply(usdc, amount, owner.Address, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.send(owner, c.address("Mock Lending Pool"), data)
+
+	//3.流动性挖矿质押
+	farm, err := defi.NewFarm(c.address("Mock Farm"), c.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = farm.PackStake(amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.send(owner, c.address("Mock Farm"), data)
+	if got, err := farm.StakingToken(c.ctx); err != nil || got != usdc {
+		t.Errorf("StakingToken() = %s, %v, want %s", got, err, usdc)
+	}
+	if got, err := farm.Earned(c.ctx, owner.Address); err != nil || got.Sign() != 0 {
+		t.Errorf("Earned() = %v, %v, want 0", got, err)
+	}
+}
+
+func TestBridgeLockAndUnlock(t *testing.T) {
+	c := newTestChain(t)
+	sender, receiver := c.node.Accounts[0], c.node.Accounts[1]
+	address := c.address("Mock Bridge")
+	usdc := c.address("Mock USDC")
+	parsed := mustABI(t, bridge.BridgeABI)
+	contract := bind.NewBoundContract(address, parsed, c.client, c.client, c.client)
+	amount := units(75, 6)
+
+	//1.lock发出Lock事件
+	data, err := parsed.Pack("lock", usdc, amount, big.NewInt(56), receiver.Address, big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := c.send(sender, address, data)
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("lock logs = %d, want 1", len(receipt.Logs))
+	}
+	var lock struct {
+		Token     common.Address
+		From      common.Address
+		Amount    *big.Int
+		ToChainId *big.Int
+		ToAddress common.Address
+		Nonce     *big.Int
+	}
+	if err := contract.UnpackLog(&lock, "Lock", *receipt.Logs[0]); err != nil {
+		t.Fatalf("unpack Lock: %v", err)
+	}
+	if lock.Token != usdc || lock.From != sender.Address || lock.Amount.Cmp(amount) != 0 ||
+		lock.ToChainId.Int64() != 56 || lock.ToAddress != receiver.Address || lock.Nonce.Int64() != 7 {
+		t.Errorf("Lock = %+v", lock)
+	}
+
+	//2.unlock发出Unlock事件
+	data, err = parsed.Pack("unlock", usdc, amount, receiver.Address, big.NewInt(56), big.NewInt(7), []byte{0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt = c.send(sender, address, data)
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("unlock logs = %d, want 1", len(receipt.Logs))
+	}
+	var unlock struct {
+		Token       common.Address
+		To          common.Address
+		Amount      *big.Int
+		FromChainId *big.Int
+		Nonce       *big.Int
+	}
+	if err := contract.UnpackLog(&unlock, "Unlock", *receipt.Logs[0]); err != nil {
+		t.Fatalf("unpack Unlock: %v", err)
+	}
+	if unlock.Token != usdc || unlock.To != receiver.Address || unlock.Amount.Cmp(amount) != 0 ||
+		unlock.FromChainId.Int64() != 56 || unlock.Nonce.Int64() != 7 {
+		t.Errorf("Unlock = %+v", unlock)
+	}
+}
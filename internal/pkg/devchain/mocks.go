@@ -0,0 +1,1149 @@
+package devchain
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"go-wallet-defi/internal/pkg/contracts/bridge"
+	"go-wallet-defi/internal/pkg/contracts/defi"
+	"go-wallet-defi/internal/pkg/contracts/disperse"
+	"go-wallet-defi/internal/pkg/contracts/multicall"
+	"go-wallet-defi/internal/pkg/contracts/nft"
+	"go-wallet-defi/internal/pkg/contracts/token"
+)
+
+// Mock 模拟合约: ABI取自internal/pkg/contracts并补充模拟合约额外提供的方法与事件, 运行时代码由EVM汇编模板生成
+type Mock struct {
+	Kind   string // 合约类型
+	abi    string // internal/pkg/contracts中的ABI
+	extra  string // 补充的ABI条目
+	source string // EVM汇编模板
+}
+
+// MockParams 渲染汇编模板的参数
+type MockParams struct {
+	Name     string
+	Symbol   string
+	Decimals uint8
+	Token    common.Address // farm/vault的质押代币
+}
+
+// ABI 模拟合约的完整ABI
+func (m *Mock) ABI() (string, error) {
+	var base, extra []json.RawMessage
+	if err := json.Unmarshal([]byte(m.abi), &base); err != nil {
+		return "", err
+	}
+	if m.extra != "" {
+		if err := json.Unmarshal([]byte(m.extra), &extra); err != nil {
+			return "", err
+		}
+	}
+	merged, err := json.Marshal(append(base, extra...))
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
+
+// Runtime 按参数生成运行时字节码
+func (m *Mock) Runtime(params MockParams) ([]byte, error) {
+	abiJson, err := m.ABI()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		return nil, err
+	}
+	return assemble(m.Kind, m.source+returnWordSource, parsed, params)
+}
+
+// Code 按参数生成部署字节码
+func (m *Mock) Code(params MockParams) ([]byte, error) {
+	runtime, err := m.Runtime(params)
+	if err != nil {
+		return nil, err
+	}
+	return creationCode(runtime), nil
+}
+
+// returnWordSource 公共片段: 返回栈顶的一个字
+const returnWordSource = `
+ret_word:
+    PUSH 0
+    MSTORE
+    PUSH 0x20
+    PUSH 0
+    RETURN
+`
+
+var (
+	// MockERC20 可增发的ERC20代币
+	MockERC20 = &Mock{
+		Kind: "erc20",
+		abi:  token.ERC20ABI,
+		extra: `[
+    {"inputs": [], "name": "totalSupply", "outputs": [{"name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"},
+    {"inputs": [{"name": "sender", "type": "address"}, {"name": "recipient", "type": "address"}, {"name": "amount", "type": "uint256"}], "name": "transferFrom", "outputs": [{"name": "", "type": "bool"}], "stateMutability": "nonpayable", "type": "function"},
+    {"inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}], "name": "mint", "outputs": [], "stateMutability": "nonpayable", "type": "function"},
+    {"anonymous": false, "inputs": [{"indexed": true, "name": "from", "type": "address"}, {"indexed": true, "name": "to", "type": "address"}, {"indexed": false, "name": "value", "type": "uint256"}], "name": "Transfer", "type": "event"},
+    {"anonymous": false, "inputs": [{"indexed": true, "name": "owner", "type": "address"}, {"indexed": true, "name": "spender", "type": "address"}, {"indexed": false, "name": "value", "type": "uint256"}], "name": "Approval", "type": "event"}
+]`,
+		source: erc20Source,
+	}
+
+	// MockERC721 可任意铸造的ERC721
+	MockERC721 = &Mock{
+		Kind: "erc721",
+		abi:  nft.ERC721ABI,
+		extra: `[
+    {"inputs": [{"name": "owner", "type": "address"}], "name": "balanceOf", "outputs": [{"name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"},
+    {"inputs": [{"name": "tokenId", "type": "uint256"}], "name": "ownerOf", "outputs": [{"name": "", "type": "address"}], "stateMutability": "view", "type": "function"},
+    {"inputs": [{"name": "from", "type": "address"}, {"name": "to", "type": "address"}, {"name": "tokenId", "type": "uint256"}], "name": "transferFrom", "outputs": [], "stateMutability": "nonpayable", "type": "function"},
+    {"inputs": [{"name": "owner", "type": "address"}, {"name": "operator", "type": "address"}], "name": "isApprovedForAll", "outputs": [{"name": "", "type": "bool"}], "stateMutability": "view", "type": "function"},
+    {"inputs": [{"name": "interfaceId", "type": "bytes4"}], "name": "supportsInterface", "outputs": [{"name": "", "type": "bool"}], "stateMutability": "view", "type": "function"},
+    {"inputs": [], "name": "name", "outputs": [{"name": "", "type": "string"}], "stateMutability": "view", "type": "function"},
+    {"inputs": [], "name": "symbol", "outputs": [{"name": "", "type": "string"}], "stateMutability": "view", "type": "function"},
+    {"anonymous": false, "inputs": [{"indexed": true, "name": "from", "type": "address"}, {"indexed": true, "name": "to", "type": "address"}, {"indexed": true, "name": "tokenId", "type": "uint256"}], "name": "Transfer", "type": "event"},
+    {"anonymous": false, "inputs": [{"indexed": true, "name": "owner", "type": "address"}, {"indexed": true, "name": "operator", "type": "address"}, {"indexed": false, "name": "approved", "type": "bool"}], "name": "ApprovalForAll", "type": "event"}
+]`,
+		source: erc721Source,
+	}
+
+	// MockRouter UniswapV2风格路由, 按1:1兑换, 不转移代币
+	MockRouter = &Mock{
+		Kind:   "router",
+		abi:    defi.UniswapV2RouterABI,
+		source: routerSource,
+	}
+
+	// MockLendingPool Aave风格借贷池, 记录调用并原样返回金额
+	MockLendingPool = &Mock{
+		Kind:   "lending",
+		abi:    defi.AavePoolABI,
+		source: lendingSource,
+	}
+
+	// MockFarm 流动性挖矿合约, 奖励恒为0
+	MockFarm = &Mock{
+		Kind:   "farm",
+		abi:    defi.FarmABI,
+		source: farmSource,
+	}
+
+	// MockVault Yearn风格收益金库, 份额与资产1:1
+	MockVault = &Mock{
+		Kind:   "vault",
+		abi:    defi.YearnVaultABI,
+		source: vaultSource,
+	}
+
+	// MockBridge 跨链桥, 锁定与解锁只发出事件, 不校验签名
+	MockBridge = &Mock{
+		Kind:   "bridge",
+		abi:    bridge.BridgeABI,
+		source: bridgeSource,
+	}
+
+	// MockMulticall3 Multicall3的aggregate3与getEthBalance, 预置在默认地址
+	MockMulticall3 = &Mock{
+		Kind:   "multicall",
+		abi:    multicall.Multicall3ABI,
+		source: multicallSource,
+	}
+
+	// MockDisperse Disperse批量转账, 预置在默认地址; 代币逐笔从调用方transferFrom给收款人
+	MockDisperse = &Mock{
+		Kind:   "disperse",
+		abi:    disperse.DisperseABI,
+		source: disperseSource,
+	}
+)
+
+// erc20Source 余额存放在以地址为键的槽位, 授权存放在keccak(owner, spender), 总量存放在2^160
+const erc20Source = `
+{{dispatch}}
+{{route "name" "name"}}
+{{route "symbol" "symbol"}}
+{{route "decimals" "decimals"}}
+{{route "totalSupply" "total_supply"}}
+{{route "balanceOf" "balance_of"}}
+{{route "allowance" "allowance"}}
+{{route "transfer" "transfer"}}
+{{route "approve" "approve"}}
+{{route "transferFrom" "transfer_from"}}
+{{route "mint" "mint"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+name:
+{{returnString .Name}}
+symbol:
+{{returnString .Symbol}}
+decimals:
+    PUSH {{.Decimals}}
+    JUMP @ret_word
+
+total_supply:
+    PUSH 0x010000000000000000000000000000000000000000
+    SLOAD
+    JUMP @ret_word
+
+balance_of:
+    PUSH 4
+    CALLDATALOAD
+    SLOAD
+    JUMP @ret_word
+
+allowance:
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    SLOAD
+    JUMP @ret_word
+
+transfer:
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 4
+    CALLDATALOAD
+    CALLER
+    JUMP @move
+
+approve:
+    CALLER
+    PUSH 0
+    MSTORE
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    SSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 4
+    CALLDATALOAD
+    CALLER
+    PUSH {{topic "Approval"}}
+    PUSH 0x20
+    PUSH 0
+    LOG3
+    PUSH 1
+    JUMP @ret_word
+
+transfer_from:
+    ;; 扣减授权 stack: amount allowed slot
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    CALLER
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    DUP1
+    SLOAD
+    PUSH 0x44
+    CALLDATALOAD
+    DUP2
+    DUP2
+    GT
+    JUMPI @insufficient_allowance
+    SWAP1
+    SUB
+    SWAP1
+    SSTORE
+    PUSH 0x44
+    CALLDATALOAD
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 4
+    CALLDATALOAD
+    JUMP @move
+
+move:
+    ;; stack: from to amount
+    DUP1
+    SLOAD
+    DUP4
+    DUP2
+    LT
+    JUMPI @insufficient_balance
+    DUP4
+    SWAP1
+    SUB
+    DUP2
+    SSTORE
+    DUP2
+    SLOAD
+    DUP4
+    ADD
+    DUP3
+    SSTORE
+    DUP3
+    PUSH 0
+    MSTORE
+    DUP2
+    DUP2
+    PUSH {{topic "Transfer"}}
+    PUSH 0x20
+    PUSH 0
+    LOG3
+    PUSH 1
+    JUMP @ret_word
+
+mint:
+    PUSH 0x24
+    CALLDATALOAD
+    DUP1
+    PUSH 0x010000000000000000000000000000000000000000
+    SLOAD
+    ADD
+    PUSH 0x010000000000000000000000000000000000000000
+    SSTORE
+    PUSH 4
+    CALLDATALOAD
+    DUP1
+    SLOAD
+    DUP3
+    ADD
+    DUP2
+    SSTORE
+    DUP2
+    PUSH 0
+    MSTORE
+    PUSH 0
+    PUSH {{topic "Transfer"}}
+    PUSH 0x20
+    PUSH 0
+    LOG3
+    STOP
+
+insufficient_balance:
+{{revert "ERC20: insufficient balance"}}
+insufficient_allowance:
+{{revert "ERC20: insufficient allowance"}}
+`
+
+// erc721Source 所有者存放在keccak(tokenId, 1), 持有数量存放在keccak(owner, 2), 授权存放在keccak(owner, operator)
+const erc721Source = `
+{{dispatch}}
+{{route "name" "name"}}
+{{route "symbol" "symbol"}}
+{{route "balanceOf" "balance_of"}}
+{{route "ownerOf" "owner_of"}}
+{{route "tokenURI" "token_uri"}}
+{{route "isApprovedForAll" "is_approved_for_all"}}
+{{route "supportsInterface" "supports_interface"}}
+{{route "mint" "mint"}}
+{{route "transferFrom" "transfer_from"}}
+{{route "safeTransferFrom" "transfer_from"}}
+{{route "safeTransferFrom(address,address,uint256,bytes)" "transfer_from"}}
+{{route "setApprovalForAll" "set_approval_for_all"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+name:
+{{returnString .Name}}
+symbol:
+{{returnString .Symbol}}
+token_uri:
+{{returnString ""}}
+
+balance_of:
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 2
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    SLOAD
+    JUMP @ret_word
+
+owner_of:
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 1
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    SLOAD
+    DUP1
+    ISZERO
+    JUMPI @invalid_token
+    JUMP @ret_word
+
+is_approved_for_all:
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    SLOAD
+    JUMP @ret_word
+
+supports_interface:
+    ;; ERC165 0x01ffc9a7, ERC721 0x80ac58cd, ERC721Metadata 0x5b5e139f
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0xe0
+    SHR
+    DUP1
+    PUSH 0x01ffc9a7
+    EQ
+    DUP2
+    PUSH 0x80ac58cd
+    EQ
+    OR
+    SWAP1
+    PUSH 0x5b5e139f
+    EQ
+    OR
+    JUMP @ret_word
+
+mint:
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 1
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    DUP1
+    SLOAD
+    JUMPI @token_exists
+    PUSH 4
+    CALLDATALOAD
+    SWAP1
+    SSTORE
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 2
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    DUP1
+    SLOAD
+    PUSH 1
+    ADD
+    SWAP1
+    SSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    PUSH {{topic "Transfer"}}
+    PUSH 0
+    PUSH 0
+    LOG4
+    STOP
+
+transfer_from:
+    ;; 校验from为所有者, 调用方为所有者或已授权 stack: slot
+    PUSH 0x44
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 1
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    DUP1
+    SLOAD
+    PUSH 4
+    CALLDATALOAD
+    EQ
+    ISZERO
+    JUMPI @not_owner
+    PUSH 4
+    CALLDATALOAD
+    CALLER
+    EQ
+    JUMPI @authorized
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    CALLER
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    SLOAD
+    JUMPI @authorized
+{{revert "ERC721: caller is not approved"}}
+
+authorized:
+    PUSH 0x24
+    CALLDATALOAD
+    SWAP1
+    SSTORE
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 2
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    DUP1
+    SLOAD
+    PUSH 1
+    SWAP1
+    SUB
+    SWAP1
+    SSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    DUP1
+    SLOAD
+    PUSH 1
+    ADD
+    SWAP1
+    SSTORE
+    PUSH 0x44
+    CALLDATALOAD
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 4
+    CALLDATALOAD
+    PUSH {{topic "Transfer"}}
+    PUSH 0
+    PUSH 0
+    LOG4
+    STOP
+
+set_approval_for_all:
+    CALLER
+    PUSH 0
+    MSTORE
+    PUSH 4
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0x40
+    PUSH 0
+    KECCAK256
+    SSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 4
+    CALLDATALOAD
+    CALLER
+    PUSH {{topic "ApprovalForAll"}}
+    PUSH 0x20
+    PUSH 0
+    LOG3
+    STOP
+
+invalid_token:
+{{revert "ERC721: invalid token ID"}}
+token_exists:
+{{revert "ERC721: token already minted"}}
+not_owner:
+{{revert "ERC721: incorrect owner"}}
+`
+
+// routerSource 兑换按1:1返回数量, 校验deadline
+const routerSource = `
+{{dispatch}}
+{{route "swapExactTokensForTokens" "swap"}}
+{{route "swapTokensForExactTokens" "swap"}}
+{{route "addLiquidity" "add_liquidity"}}
+{{route "removeLiquidity" "remove_liquidity"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+swap:
+    ;; amounts = [amount, amount]
+    TIMESTAMP
+    PUSH 0x84
+    CALLDATALOAD
+    LT
+    JUMPI @expired
+    PUSH 0x20
+    PUSH 0
+    MSTORE
+    PUSH 2
+    PUSH 0x20
+    MSTORE
+    PUSH 4
+    CALLDATALOAD
+    DUP1
+    PUSH 0x40
+    MSTORE
+    PUSH 0x60
+    MSTORE
+    PUSH 0x80
+    PUSH 0
+    RETURN
+
+add_liquidity:
+    ;; (amountA, amountB, liquidity=amountA)
+    TIMESTAMP
+    PUSH 0xa4
+    CALLDATALOAD
+    LT
+    JUMPI @expired
+    PUSH 4
+    CALLDATALOAD
+    DUP1
+    PUSH 0
+    MSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    MSTORE
+    PUSH 0x60
+    PUSH 0
+    RETURN
+
+remove_liquidity:
+    ;; (liquidity, liquidity)
+    TIMESTAMP
+    PUSH 0x84
+    CALLDATALOAD
+    LT
+    JUMPI @expired
+    PUSH 4
+    CALLDATALOAD
+    DUP1
+    PUSH 0
+    MSTORE
+    PUSH 0x20
+    MSTORE
+    PUSH 0x40
+    PUSH 0
+    RETURN
+
+expired:
+{{revert "UniswapV2Router: EXPIRED"}}
+`
+
+// lendingSource 存入与借出直接成功, 取回与还款返回请求金额
+const lendingSource = `
+{{dispatch}}
+{{route "supply" "done"}}
+{{route "borrow" "done"}}
+{{route "withdraw" "return_amount"}}
+{{route "repay" "return_amount"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+done:
+    STOP
+
+return_amount:
+    PUSH 0x24
+    CALLDATALOAD
+    JUMP @ret_word
+`
+
+// farmSource 质押相关调用直接成功, 奖励为0
+const farmSource = `
+{{dispatch}}
+{{route "stake" "done"}}
+{{route "withdraw" "done"}}
+{{route "getReward" "done"}}
+{{route "earned" "earned"}}
+{{route "stakingToken" "staking_token"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+done:
+    STOP
+
+earned:
+    PUSH 0
+    JUMP @ret_word
+
+staking_token:
+    PUSH {{.Token.Hex}}
+    JUMP @ret_word
+`
+
+// vaultSource 存取按1:1返回, 份额价格为1e18
+const vaultSource = `
+{{dispatch}}
+{{route "deposit" "return_amount"}}
+{{route "withdraw" "return_amount"}}
+{{route "pricePerShare" "price_per_share"}}
+{{route "token" "token"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+return_amount:
+    PUSH 4
+    CALLDATALOAD
+    JUMP @ret_word
+
+price_per_share:
+    PUSH 1000000000000000000
+    JUMP @ret_word
+
+token:
+    PUSH {{.Token.Hex}}
+    JUMP @ret_word
+`
+
+// bridgeSource lock发出Lock事件, unlock发出Unlock事件
+const bridgeSource = `
+{{dispatch}}
+{{route "lock" "lock"}}
+{{route "unlock" "unlock"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+lock:
+    ;; data: amount, toChainId, toAddress, nonce
+    PUSH 0x80
+    PUSH 0x24
+    PUSH 0
+    CALLDATACOPY
+    CALLER
+    PUSH 4
+    CALLDATALOAD
+    PUSH {{topic "Lock"}}
+    PUSH 0x80
+    PUSH 0
+    LOG3
+    STOP
+
+unlock:
+    ;; data: amount, fromChainId, nonce
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0
+    MSTORE
+    PUSH 0x64
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x84
+    CALLDATALOAD
+    PUSH 0x40
+    MSTORE
+    PUSH 0x44
+    CALLDATALOAD
+    PUSH 4
+    CALLDATALOAD
+    PUSH {{topic "Unlock"}}
+    PUSH 0x60
+    PUSH 0
+    LOG3
+    STOP
+`
+
+// multicallSource 变量存放在内存0x00-0x7f: i, n, 调用数组头部, 输出尾部; 输出从0x80开始
+const multicallSource = `
+{{dispatch}}
+{{route "aggregate3" "aggregate3"}}
+{{route "getEthBalance" "get_eth_balance"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+get_eth_balance:
+    PUSH 4
+    CALLDATALOAD
+    BALANCE
+    JUMP @ret_word
+
+aggregate3:
+    PUSH 4
+    CALLDATALOAD
+    PUSH 4
+    ADD
+    DUP1
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x20
+    ADD
+    PUSH 0x40
+    MSTORE
+    PUSH 0x20
+    PUSH 0x80
+    MSTORE
+    PUSH 0x20
+    MLOAD
+    PUSH 0xa0
+    MSTORE
+    PUSH 0x20
+    MLOAD
+    PUSH 5
+    SHL
+    PUSH 0xc0
+    ADD
+    PUSH 0x60
+    MSTORE
+    PUSH 0
+    PUSH 0
+    MSTORE
+
+aggregate_loop:
+    PUSH 0x20
+    MLOAD
+    PUSH 0
+    MLOAD
+    LT
+    ISZERO
+    JUMPI @aggregate_done
+    ;; tuple = head + calldata[head + i*32]
+    PUSH 0x40
+    MLOAD
+    DUP1
+    PUSH 0
+    MLOAD
+    PUSH 5
+    SHL
+    ADD
+    CALLDATALOAD
+    ADD
+    ;; 输出头部写入本条结果相对0xc0的偏移
+    PUSH 0xc0
+    PUSH 0x60
+    MLOAD
+    SUB
+    PUSH 0
+    MLOAD
+    PUSH 5
+    SHL
+    PUSH 0xc0
+    ADD
+    MSTORE
+    ;; callData复制到输出尾部+0x60 stack: len bytes tuple
+    DUP1
+    PUSH 0x40
+    ADD
+    CALLDATALOAD
+    DUP2
+    ADD
+    DUP1
+    CALLDATALOAD
+    DUP1
+    DUP3
+    PUSH 0x20
+    ADD
+    PUSH 0x60
+    MLOAD
+    PUSH 0x60
+    ADD
+    CALLDATACOPY
+    PUSH 0
+    PUSH 0
+    DUP3
+    PUSH 0x60
+    MLOAD
+    PUSH 0x60
+    ADD
+    PUSH 0
+    DUP8
+    CALLDATALOAD
+    GAS
+    CALL
+    ;; 失败且不允许失败时回滚 stack: success len bytes tuple
+    DUP1
+    DUP5
+    PUSH 0x20
+    ADD
+    CALLDATALOAD
+    OR
+    ISZERO
+    JUMPI @call_failed
+    PUSH 0x60
+    MLOAD
+    MSTORE
+    POP
+    POP
+    POP
+    PUSH 0x40
+    PUSH 0x60
+    MLOAD
+    PUSH 0x20
+    ADD
+    MSTORE
+    RETURNDATASIZE
+    PUSH 0x60
+    MLOAD
+    PUSH 0x40
+    ADD
+    MSTORE
+    RETURNDATASIZE
+    PUSH 0
+    PUSH 0x60
+    MLOAD
+    PUSH 0x60
+    ADD
+    RETURNDATACOPY
+    ;; 尾部后移 0x60 + 按字对齐的返回数据长度
+    PUSH 0x1f
+    RETURNDATASIZE
+    ADD
+    PUSH 5
+    SHR
+    PUSH 5
+    SHL
+    PUSH 0x60
+    MLOAD
+    ADD
+    PUSH 0x60
+    ADD
+    PUSH 0x60
+    MSTORE
+    PUSH 0
+    MLOAD
+    PUSH 1
+    ADD
+    PUSH 0
+    MSTORE
+    JUMP @aggregate_loop
+
+aggregate_done:
+    PUSH 0x80
+    PUSH 0x60
+    MLOAD
+    SUB
+    PUSH 0x80
+    RETURN
+
+call_failed:
+{{revert "Multicall3: call failed"}}
+`
+
+// disperseSource 变量存放在内存0x00-0x7f: i, n, 收款人数组, 金额数组; 0x80起构造transferFrom调用
+const disperseSource = `
+{{dispatch}}
+{{route "disperseEther" "disperse_ether"}}
+{{route "disperseToken" "disperse_token"}}
+    PUSH 0
+    DUP1
+    REVERT
+
+disperse_ether:
+    PUSH 4
+    CALLDATALOAD
+    PUSH 4
+    ADD
+    DUP1
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x20
+    ADD
+    PUSH 0x40
+    MSTORE
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 0x24
+    ADD
+    PUSH 0x60
+    MSTORE
+    PUSH 0
+    PUSH 0
+    MSTORE
+
+ether_loop:
+    PUSH 0x20
+    MLOAD
+    PUSH 0
+    MLOAD
+    LT
+    ISZERO
+    JUMPI @ether_done
+    PUSH 0
+    PUSH 0
+    PUSH 0
+    PUSH 0
+    PUSH 0
+    MLOAD
+    PUSH 5
+    SHL
+    PUSH 0x60
+    MLOAD
+    ADD
+    CALLDATALOAD
+    PUSH 0
+    MLOAD
+    PUSH 5
+    SHL
+    PUSH 0x40
+    MLOAD
+    ADD
+    CALLDATALOAD
+    GAS
+    CALL
+    ISZERO
+    JUMPI @transfer_failed
+    PUSH 0
+    MLOAD
+    PUSH 1
+    ADD
+    PUSH 0
+    MSTORE
+    JUMP @ether_loop
+
+ether_done:
+    ;; 余额退回调用方
+    PUSH 0
+    PUSH 0
+    PUSH 0
+    PUSH 0
+    SELFBALANCE
+    CALLER
+    GAS
+    CALL
+    POP
+    STOP
+
+disperse_token:
+    PUSH 0x24
+    CALLDATALOAD
+    PUSH 4
+    ADD
+    DUP1
+    CALLDATALOAD
+    PUSH 0x20
+    MSTORE
+    PUSH 0x20
+    ADD
+    PUSH 0x40
+    MSTORE
+    PUSH 0x44
+    CALLDATALOAD
+    PUSH 0x24
+    ADD
+    PUSH 0x60
+    MSTORE
+    PUSH 0
+    PUSH 0
+    MSTORE
+    PUSH {{selectorWord "transferFrom(address,address,uint256)"}}
+    PUSH 0x80
+    MSTORE
+    CALLER
+    PUSH 0x84
+    MSTORE
+
+token_loop:
+    PUSH 0x20
+    MLOAD
+    PUSH 0
+    MLOAD
+    LT
+    ISZERO
+    JUMPI @token_done
+    PUSH 0
+    MLOAD
+    PUSH 5
+    SHL
+    PUSH 0x40
+    MLOAD
+    ADD
+    CALLDATALOAD
+    PUSH 0xa4
+    MSTORE
+    PUSH 0
+    MLOAD
+    PUSH 5
+    SHL
+    PUSH 0x60
+    MLOAD
+    ADD
+    CALLDATALOAD
+    PUSH 0xc4
+    MSTORE
+    PUSH 0x20
+    PUSH 0x100
+    PUSH 0x64
+    PUSH 0x80
+    PUSH 0
+    PUSH 4
+    CALLDATALOAD
+    GAS
+    CALL
+    ISZERO
+    JUMPI @transfer_failed
+    PUSH 0x100
+    MLOAD
+    ISZERO
+    JUMPI @transfer_failed
+    PUSH 0
+    MLOAD
+    PUSH 1
+    ADD
+    PUSH 0
+    MSTORE
+    JUMP @token_loop
+
+token_done:
+    STOP
+
+transfer_failed:
+{{revert "Disperse: transfer failed"}}
+`
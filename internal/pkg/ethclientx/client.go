@@ -2,33 +2,27 @@ package ethclientx
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/gogf/gf/v2/frame/g"
 	"go-wallet-defi/internal/model"
+	"net/http"
+	"sort"
 	"sync"
 )
 
+// poolURL 节点池客户端使用的占位地址, 实际节点由Pool按请求选择
+const poolURL = "http://rpc-pool"
+
 var (
-	// 以太坊
-	client  *ethclient.Client
 	clients = make(map[uint64]*ethclient.Client)
-	once    sync.Once
+	pools   = make(map[uint64]*Pool)
+	wsUrls  = make(map[uint64][]string)
 	mutex   sync.RWMutex
 )
 
-func GetClient(ctx context.Context) *ethclient.Client {
-	once.Do(func() {
-		var err error
-		rpcURL := g.Cfg().MustGet(ctx, "ethereum.rpc").String()
-		client, err = ethclient.Dial(rpcURL)
-		if err != nil {
-			panic(err)
-		}
-	})
-	return client
-}
-
+// GetClientByChainId 按链注册表获取链的节点池客户端, 同一条链复用同一个节点池
 func GetClientByChainId(ctx context.Context, chainId uint64) (*ethclient.Client, error) {
 	mutex.RLock()
 	if client, ok := clients[chainId]; ok {
@@ -45,38 +39,86 @@ func GetClientByChainId(ctx context.Context, chainId uint64) (*ethclient.Client,
 	if err != nil {
 		return nil, err
 	}
+	if chain == nil {
+		return nil, fmt.Errorf("chain %d not found", chainId)
+	}
 	//2.解析RPC地址
-	var rpcUrls []string
-	err = json.Unmarshal([]byte(chain.RpcUrls), &rpcUrls)
+	configs, subscribeUrls, err := parseEndpoints(chain.RpcUrls)
 	if err != nil {
 		return nil, err
 	}
-	//3.连接RPC节点
-	var client *ethclient.Client
-	for _, url := range rpcUrls {
-		client, err = ethclient.Dial(url)
-		if err == nil {
-			break
-		}
-	}
+	//3.创建节点池客户端
+	client, pool, err := dialPool(ctx, chainId, configs)
 	if err != nil {
 		return nil, err
 	}
 
 	mutex.Lock()
+	defer mutex.Unlock()
+	// 并发创建时保留先登记的节点池
+	if existing, ok := clients[chainId]; ok {
+		client.Close()
+		pool.Close()
+		return existing, nil
+	}
 	clients[chainId] = client
-	mutex.Unlock()
-
+	pools[chainId] = pool
+	wsUrls[chainId] = subscribeUrls
 	return client, nil
 }
 
+// wsEndpoints 获取链的websocket订阅节点, 需先通过GetClientByChainId创建节点池
+func wsEndpoints(chainId uint64) []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return wsUrls[chainId]
+}
+
+// dialPool 创建节点池并返回经节点池转发请求的客户端
+func dialPool(ctx context.Context, chainId uint64, configs []EndpointConfig) (*ethclient.Client, *Pool, error) {
+	if len(configs) == 0 {
+		return nil, nil, fmt.Errorf("chain %d has no http rpc url", chainId)
+	}
+	pool := newPool(ctx, chainId, configs)
+	rpcClient, err := rpc.DialOptions(ctx, poolURL, rpc.WithHTTPClient(&http.Client{Transport: pool}))
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return ethclient.NewClient(rpcClient), pool, nil
+}
+
+// Health 获取全部节点池的节点健康状态, 按链ID排序
+func Health() []*model.RpcEndpointHealth {
+	mutex.RLock()
+	chainIds := make([]uint64, 0, len(pools))
+	for chainId := range pools {
+		chainIds = append(chainIds, chainId)
+	}
+	mutex.RUnlock()
+	sort.Slice(chainIds, func(i, j int) bool { return chainIds[i] < chainIds[j] })
+
+	list := make([]*model.RpcEndpointHealth, 0)
+	for _, chainId := range chainIds {
+		mutex.RLock()
+		pool := pools[chainId]
+		mutex.RUnlock()
+		list = append(list, pool.Health()...)
+	}
+	return list
+}
+
 // CloseAll 关闭所有客户端连接
 func CloseAll() {
 	mutex.Lock()
 	defer mutex.Unlock()
-	client.Close()
 	for _, client := range clients {
 		client.Close()
 	}
+	for _, pool := range pools {
+		pool.Close()
+	}
 	clients = make(map[uint64]*ethclient.Client)
+	pools = make(map[uint64]*Pool)
+	wsUrls = make(map[uint64][]string)
 }
@@ -0,0 +1,43 @@
+package ethclientx
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// limitExceededCode 节点拒绝过大请求时常用的JSON-RPC错误码
+const limitExceededCode = -32005
+
+// rangeLimitMessages 各节点服务商限制eth_getLogs区块范围或结果数量时的错误信息片段
+var rangeLimitMessages = []string{
+	"block range",
+	"range too large",
+	"range is too large",
+	"too many blocks",
+	"query returned more than",
+	"more than 10000 results",
+	"limit exceeded",
+	"response size",
+	"log response size exceeded",
+	"query timeout exceeded",
+}
+
+// IsRangeLimitError 判断eth_getLogs错误是否因区块范围或结果数量超出节点限制, 缩小查询范围后可重试
+func IsRangeLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == limitExceededCode {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	for _, fragment := range rangeLimitMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
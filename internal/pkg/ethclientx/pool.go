@@ -0,0 +1,346 @@
+package ethclientx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/model"
+)
+
+const (
+	defaultProbeInterval = "15s" // 健康探测间隔
+	defaultProbeTimeout  = "5s"  // 单次探测超时
+	defaultMaxBlockLag   = 5     // 落后超过该区块数视为不健康
+	defaultMaxErrorRate  = 0.5   // 错误率超过该值视为不健康
+	defaultMaxAttempts   = 3     // 幂等请求最多尝试的节点数
+	errorWindowSize      = 50    // 错误率统计的最近请求数
+	minErrorSamples      = 5     // 样本数不足时不按错误率判定
+	latencySmoothing     = 0.2   // 延迟指数移动平均系数
+)
+
+// EndpointConfig 节点配置, chain.rpc_urls 中的元素可为地址字符串或 {"url": "...", "weight": 2}
+type EndpointConfig struct {
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// endpoint 单个RPC节点及其健康统计
+type endpoint struct {
+	url    string
+	weight int
+
+	mu          sync.Mutex
+	probeOK     bool
+	blockNumber uint64
+	lag         uint64
+	latency     float64 // 毫秒, 指数移动平均
+	window      [errorWindowSize]bool
+	windowPos   int
+	windowLen   int
+	requests    uint64
+	failures    uint64
+	lastError   string
+	lastProbeAt int64
+}
+
+// record 记录一次请求结果
+func (e *endpoint) record(latency time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ms := float64(latency.Milliseconds())
+	if e.latency == 0 {
+		e.latency = ms
+	} else {
+		e.latency = e.latency*(1-latencySmoothing) + ms*latencySmoothing
+	}
+	e.window[e.windowPos] = err != nil
+	e.windowPos = (e.windowPos + 1) % errorWindowSize
+	if e.windowLen < errorWindowSize {
+		e.windowLen++
+	}
+	e.requests++
+	if err != nil {
+		e.failures++
+		e.lastError = err.Error()
+	}
+}
+
+// errorRate 最近请求的错误率, 调用方需持有锁
+func (e *endpoint) errorRate() float64 {
+	if e.windowLen == 0 {
+		return 0
+	}
+	failed := 0
+	for i := 0; i < e.windowLen; i++ {
+		if e.window[i] {
+			failed++
+		}
+	}
+	return float64(failed) / float64(e.windowLen)
+}
+
+// healthy 判断节点是否健康, 调用方需持有锁
+func (e *endpoint) healthy(maxLag uint64, maxErrorRate float64) bool {
+	if !e.probeOK || e.lag > maxLag {
+		return false
+	}
+	return e.windowLen < minErrorSamples || e.errorRate() <= maxErrorRate
+}
+
+// Pool 同一条链的RPC节点池, 定期探测区块高度、延迟与错误率, 按权重选择健康节点
+type Pool struct {
+	chainId      uint64
+	endpoints    []*endpoint
+	httpClient   *http.Client
+	maxLag       uint64
+	maxErrorRate float64
+	maxAttempts  int
+	stop         chan struct{}
+	stopOnce     sync.Once
+}
+
+// newPool 创建节点池并同步完成首次探测
+func newPool(ctx context.Context, chainId uint64, configs []EndpointConfig) *Pool {
+	p := &Pool{
+		chainId:      chainId,
+		httpClient:   &http.Client{},
+		maxLag:       g.Cfg().MustGet(ctx, "rpc.maxBlockLag", defaultMaxBlockLag).Uint64(),
+		maxErrorRate: g.Cfg().MustGet(ctx, "rpc.maxErrorRate", defaultMaxErrorRate).Float64(),
+		maxAttempts:  g.Cfg().MustGet(ctx, "rpc.maxAttempts", defaultMaxAttempts).Int(),
+		stop:         make(chan struct{}),
+	}
+	for _, config := range configs {
+		weight := config.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		p.endpoints = append(p.endpoints, &endpoint{url: config.Url, weight: weight})
+	}
+
+	p.probe(ctx)
+	go p.probeLoop(g.Cfg().MustGet(ctx, "rpc.probeInterval", defaultProbeInterval).Duration())
+	return p
+}
+
+// probeLoop 定期探测节点
+func (p *Pool) probeLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.probe(context.Background())
+		}
+	}
+}
+
+// probe 并发探测全部节点的区块高度, 并按同链最高高度计算落后区块数
+func (p *Pool) probe(ctx context.Context) {
+	timeout := g.Cfg().MustGet(ctx, "rpc.probeTimeout", defaultProbeTimeout).Duration()
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			number, err := p.blockNumber(probeCtx, e.url)
+			e.record(time.Since(start), err)
+
+			e.mu.Lock()
+			e.probeOK = err == nil
+			if err == nil {
+				e.blockNumber = number
+			}
+			e.lastProbeAt = time.Now().Unix()
+			e.mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+
+	var highest uint64
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		if e.probeOK && e.blockNumber > highest {
+			highest = e.blockNumber
+		}
+		e.mu.Unlock()
+	}
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		e.lag = 0
+		if e.probeOK && highest > e.blockNumber {
+			e.lag = highest - e.blockNumber
+		}
+		e.mu.Unlock()
+	}
+}
+
+// blockNumber 通过eth_blockNumber获取节点区块高度
+func (p *Pool) blockNumber(ctx context.Context, rawurl string) (uint64, error) {
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawurl, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("probe %s: http status %d", redactURL(rawurl), resp.StatusCode)
+	}
+
+	var result struct {
+		Result hexutil.Uint64 `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return 0, err
+	}
+	if result.Error != nil {
+		return 0, fmt.Errorf("probe %s: %s", redactURL(rawurl), result.Error.Message)
+	}
+	return uint64(result.Result), nil
+}
+
+// pick 按权重与延迟从健康节点中选择, 跳过已尝试节点; 无健康节点时选择错误率最低的节点
+func (p *Pool) pick(tried map[*endpoint]bool) *endpoint {
+	var (
+		candidates []*endpoint
+		weights    []float64
+		total      float64
+		fallback   *endpoint
+		fallbackER = 2.0
+	)
+	for _, e := range p.endpoints {
+		if tried[e] {
+			continue
+		}
+		e.mu.Lock()
+		if e.healthy(p.maxLag, p.maxErrorRate) {
+			// 延迟越低权重越高
+			weight := float64(e.weight) * 100 / (100 + e.latency)
+			candidates = append(candidates, e)
+			weights = append(weights, weight)
+			total += weight
+		}
+		if rate := e.errorRate(); rate < fallbackER {
+			fallback, fallbackER = e, rate
+		}
+		e.mu.Unlock()
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	r := rand.Float64() * total
+	for i, e := range candidates {
+		if r < weights[i] {
+			return e
+		}
+		r -= weights[i]
+	}
+	return candidates[len(candidates)-1]
+}
+
+// Health 获取节点健康状态
+func (p *Pool) Health() []*model.RpcEndpointHealth {
+	list := make([]*model.RpcEndpointHealth, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		list = append(list, &model.RpcEndpointHealth{
+			ChainId:     p.chainId,
+			Url:         redactURL(e.url),
+			Weight:      e.weight,
+			Healthy:     e.healthy(p.maxLag, p.maxErrorRate),
+			BlockNumber: e.blockNumber,
+			Lag:         e.lag,
+			LatencyMs:   int64(e.latency),
+			ErrorRate:   e.errorRate(),
+			Requests:    e.requests,
+			Failures:    e.failures,
+			LastError:   e.lastError,
+			LastProbeAt: e.lastProbeAt,
+		})
+		e.mu.Unlock()
+	}
+	return list
+}
+
+// Close 停止探测
+func (p *Pool) Close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+// parseEndpoints 解析节点配置, http(s)节点进入请求池, websocket节点用于订阅, 其他协议忽略
+func parseEndpoints(raw string) ([]EndpointConfig, []string, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, nil, err
+	}
+	configs := make([]EndpointConfig, 0, len(items))
+	wsUrls := make([]string, 0)
+	for _, item := range items {
+		var config EndpointConfig
+		var rawurl string
+		if err := json.Unmarshal(item, &rawurl); err == nil {
+			config.Url = rawurl
+		} else if err := json.Unmarshal(item, &config); err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case isHTTP(config.Url):
+			configs = append(configs, config)
+		case isWS(config.Url):
+			wsUrls = append(wsUrls, config.Url)
+		}
+	}
+	return configs, wsUrls, nil
+}
+
+// isHTTP 判断是否为http(s)节点
+func isHTTP(rawurl string) bool {
+	lower := strings.ToLower(rawurl)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// isWS 判断是否为websocket节点
+func isWS(rawurl string) bool {
+	lower := strings.ToLower(rawurl)
+	return strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://")
+}
+
+// redactURL 隐藏节点地址中的路径、查询参数与凭证, 避免泄露API Key
+func redactURL(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "invalid url"
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if u.Path != "" && u.Path != "/" || u.RawQuery != "" || u.User != nil {
+		redacted += "/***"
+	}
+	return redacted
+}
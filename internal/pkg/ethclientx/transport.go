@@ -0,0 +1,123 @@
+package ethclientx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// nonIdempotentMethods 失败后不可在其他节点重试的方法:
+// 交易广播在原节点可能已受理, 重试会使调用方误判结果; 过滤器与订阅状态绑定在单个节点上
+var nonIdempotentMethods = map[string]bool{
+	"eth_sendRawTransaction":          true,
+	"eth_sendTransaction":             true,
+	"eth_newFilter":                   true,
+	"eth_newBlockFilter":              true,
+	"eth_newPendingTransactionFilter": true,
+	"eth_getFilterChanges":            true,
+	"eth_getFilterLogs":               true,
+	"eth_uninstallFilter":             true,
+	"eth_subscribe":                   true,
+	"eth_unsubscribe":                 true,
+}
+
+// RoundTrip 将JSON-RPC请求转发到节点池选出的节点, 幂等请求在节点不可用时切换节点重试
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := 1
+	if idempotent(body) {
+		attempts = p.maxAttempts
+		if attempts > len(p.endpoints) {
+			attempts = len(p.endpoints)
+		}
+	}
+
+	var (
+		tried   = make(map[*endpoint]bool)
+		lastErr error
+	)
+	for i := 0; i < attempts; i++ {
+		e := p.pick(tried)
+		if e == nil {
+			break
+		}
+		tried[e] = true
+
+		resp, err := p.forward(req, e, body)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if req.Context().Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no rpc endpoint available")
+	}
+	return nil, lastErr
+}
+
+// forward 将请求发送到指定节点, 连接失败、限流与5xx视为节点失败
+func (p *Pool) forward(req *http.Request, e *endpoint, body []byte) (*http.Response, error) {
+	out, err := http.NewRequestWithContext(req.Context(), req.Method, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	out.Header = req.Header.Clone()
+
+	start := time.Now()
+	resp, err := p.httpClient.Do(out)
+	if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError) {
+		resp.Body.Close()
+		err = fmt.Errorf("rpc %s: http status %d", redactURL(e.url), resp.StatusCode)
+	}
+	// 调用方取消不计入节点错误
+	if err != nil && req.Context().Err() != nil {
+		return nil, err
+	}
+	e.record(time.Since(start), err)
+	return resp, err
+}
+
+// idempotent 判断请求(含批量请求)是否全部为可重试方法
+func idempotent(body []byte) bool {
+	var message struct {
+		Method string `json:"method"`
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return false
+		}
+		for _, item := range batch {
+			if err := json.Unmarshal(item, &message); err != nil || !methodIdempotent(message.Method) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := json.Unmarshal(trimmed, &message); err != nil {
+		return false
+	}
+	return methodIdempotent(message.Method)
+}
+
+// methodIdempotent 判断方法是否可重试
+func methodIdempotent(method string) bool {
+	if nonIdempotentMethods[method] {
+		return false
+	}
+	return !strings.HasPrefix(method, "personal_")
+}
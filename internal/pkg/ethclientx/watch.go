@@ -0,0 +1,170 @@
+package ethclientx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	defaultPollInterval = "10s" // 轮询模式的查询间隔
+	defaultMinBackoff   = "1s"  // 订阅断开后的首次重连等待
+	defaultMaxBackoff   = "60s" // 重连等待上限
+	headBufferSize      = 64    // 订阅区块头缓冲
+)
+
+// errNoWebsocket 链未配置websocket节点
+var errNoWebsocket = errors.New("no websocket endpoint")
+
+// HeadHandler 新区块处理函数, head为当前最新高度
+type HeadHandler func(ctx context.Context, head uint64)
+
+// HeadWatcher 持续获取单条链的最新高度:
+// 节点支持eth_subscribe时订阅newHeads, 否则按间隔轮询; 订阅断开后按指数退避重连, 等待期间改为轮询
+type HeadWatcher struct {
+	chainId      uint64
+	client       *ethclient.Client
+	handle       HeadHandler
+	last         uint64 // 最近一次处理的高度
+	pollInterval time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	wsIndex      int
+}
+
+// WatchHeads 启动时及每出现新区块时调用handle, 直到ctx结束; 处理期间到达的多个区块合并为一次调用
+func WatchHeads(ctx context.Context, chainId uint64, handle HeadHandler) error {
+	client, err := GetClientByChainId(ctx, chainId)
+	if err != nil {
+		return err
+	}
+	w := &HeadWatcher{
+		chainId:      chainId,
+		client:       client,
+		handle:       handle,
+		pollInterval: g.Cfg().MustGet(ctx, "watch.pollInterval", defaultPollInterval).Duration(),
+		minBackoff:   g.Cfg().MustGet(ctx, "watch.minBackoff", defaultMinBackoff).Duration(),
+		maxBackoff:   g.Cfg().MustGet(ctx, "watch.maxBackoff", defaultMaxBackoff).Duration(),
+	}
+	w.run(ctx)
+	return ctx.Err()
+}
+
+// run 优先订阅, 订阅不可用或断开时轮询, 并按指数退避重试订阅
+func (w *HeadWatcher) run(ctx context.Context) {
+	backoff := w.minBackoff
+	for ctx.Err() == nil {
+		start := time.Now()
+		err := w.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errNoWebsocket) {
+			w.poll(ctx, 0)
+			return
+		}
+		// 订阅稳定运行超过退避上限后重新从最小退避开始
+		if time.Since(start) > w.maxBackoff {
+			backoff = w.minBackoff
+		}
+		g.Log().Warningf(ctx, "chain %d head subscription ended: %v, polling for %s before reconnect", w.chainId, err, backoff)
+		w.poll(ctx, backoff)
+		backoff *= 2
+		if backoff > w.maxBackoff {
+			backoff = w.maxBackoff
+		}
+	}
+}
+
+// poll 按间隔轮询最新高度, duration为0时持续轮询直到ctx结束
+func (w *HeadWatcher) poll(ctx context.Context, duration time.Duration) {
+	var deadline <-chan time.Time
+	if duration > 0 {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		if head, err := w.client.BlockNumber(ctx); err != nil {
+			if ctx.Err() == nil {
+				g.Log().Warningf(ctx, "chain %d poll head failed: %v", w.chainId, err)
+			}
+		} else {
+			w.deliver(ctx, head)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// subscribe 订阅newHeads, 订阅建立后先按当前高度处理一次, 返回订阅结束的原因
+func (w *HeadWatcher) subscribe(ctx context.Context) error {
+	urls := wsEndpoints(w.chainId)
+	if len(urls) == 0 {
+		return errNoWebsocket
+	}
+	rawurl := urls[w.wsIndex%len(urls)]
+	w.wsIndex++
+
+	//1.连接节点并订阅
+	client, err := ethclient.DialContext(ctx, rawurl)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	heads := make(chan *types.Header, headBufferSize)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	//2.处理订阅断开期间出现的区块
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	g.Log().Infof(ctx, "chain %d head subscription established via %s", w.chainId, redactURL(rawurl))
+	w.deliver(ctx, head)
+
+	//3.处理推送, 只取缓冲中的最新区块
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return err
+		case header := <-heads:
+			for drained := false; !drained; {
+				select {
+				case next := <-heads:
+					header = next
+				default:
+					drained = true
+				}
+			}
+			w.deliver(ctx, header.Number.Uint64())
+		}
+	}
+}
+
+// deliver 高度有变化时调用处理函数
+func (w *HeadWatcher) deliver(ctx context.Context, head uint64) {
+	if head == w.last {
+		return
+	}
+	w.last = head
+	w.handle(ctx, head)
+}
@@ -0,0 +1,130 @@
+package hdwallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// BasePath 以太坊BIP-44基础路径 m/44'/60'/0'/0, 与MetaMask/Ledger保持一致
+const BasePath = "m/44'/60'/0'/0"
+
+// hardenedOffset 硬化派生索引起点
+const hardenedOffset = 0x80000000
+
+var (
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	ErrInvalidKey      = errors.New("derived key is invalid")
+)
+
+// extendedKey BIP-32扩展私钥
+type extendedKey struct {
+	key       []byte
+	chainCode []byte
+}
+
+// AccountPath 返回第index个账户的派生路径 m/44'/60'/0'/0/index
+func AccountPath(index uint32) string {
+	return fmt.Sprintf("%s/%d", BasePath, index)
+}
+
+// DeriveFromMnemonic 按派生路径从助记词派生私钥
+func DeriveFromMnemonic(mnemonic, password, path string) (*ecdsa.PrivateKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	seed := bip39.NewSeed(mnemonic, password)
+	return DeriveFromSeed(seed, path)
+}
+
+// DeriveFromSeed 按派生路径从种子派生私钥
+func DeriveFromSeed(seed []byte, path string) (*ecdsa.PrivateKey, error) {
+	//1.解析派生路径
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	//2.生成主密钥
+	master, err := newMaster(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	//3.逐级派生子密钥
+	current := master
+	for _, index := range derivationPath {
+		current, err = current.child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return crypto.ToECDSA(current.key)
+}
+
+// newMaster 根据种子生成主密钥
+func newMaster(seed []byte) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	if !validKey(sum[:32]) {
+		return nil, ErrInvalidKey
+	}
+	return &extendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// child 派生子私钥(CKDpriv)
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	data := make([]byte, 0, 37)
+	if index >= hardenedOffset {
+		// 硬化派生: 0x00 || ser256(k) || ser32(i)
+		data = append(data, 0x00)
+		data = append(data, k.key...)
+	} else {
+		// 普通派生: serP(point(k)) || ser32(i)
+		privateKey, err := crypto.ToECDSA(k.key)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, crypto.CompressPubkey(&privateKey.PublicKey)...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	if !validKey(sum[:32]) {
+		return nil, ErrInvalidKey
+	}
+
+	// 子私钥 = (IL + kpar) mod n
+	n := crypto.S256().Params().N
+	childKey := new(big.Int).SetBytes(sum[:32])
+	childKey.Add(childKey, new(big.Int).SetBytes(k.key))
+	childKey.Mod(childKey, n)
+	if childKey.Sign() == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	return &extendedKey{
+		key:       childKey.FillBytes(make([]byte, 32)),
+		chainCode: sum[32:],
+	}, nil
+}
+
+// validKey 校验私钥是否在(0, n)范围内
+func validKey(key []byte) bool {
+	v := new(big.Int).SetBytes(key)
+	return v.Sign() > 0 && v.Cmp(crypto.S256().Params().N) < 0
+}
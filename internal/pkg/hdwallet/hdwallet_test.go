@@ -0,0 +1,69 @@
+package hdwallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testMnemonic BIP-39测试助记词, 派生地址与MetaMask、Ledger及Hardhat默认账户一致
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveFromMnemonic(t *testing.T) {
+	tests := []struct {
+		path    string
+		address string
+		key     string
+	}{
+		{AccountPath(0), "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", "1ab42cc412b618bdea3a599e3c9bae199ebf030895b039e9db1e30dafb12b727"},
+		{AccountPath(1), "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0", ""},
+	}
+	for _, tt := range tests {
+		key, err := DeriveFromMnemonic(testMnemonic, "", tt.path)
+		if err != nil {
+			t.Fatalf("DeriveFromMnemonic(%s) error = %v", tt.path, err)
+		}
+		if got := crypto.PubkeyToAddress(key.PublicKey).Hex(); got != tt.address {
+			t.Errorf("DeriveFromMnemonic(%s) address = %s, want %s", tt.path, got, tt.address)
+		}
+		if tt.key != "" {
+			if got := hex.EncodeToString(crypto.FromECDSA(key)); got != tt.key {
+				t.Errorf("DeriveFromMnemonic(%s) key = %s, want %s", tt.path, got, tt.key)
+			}
+		}
+	}
+}
+
+func TestDeriveFromMnemonicInvalid(t *testing.T) {
+	if _, err := DeriveFromMnemonic("abandon abandon abandon", "", AccountPath(0)); err != ErrInvalidMnemonic {
+		t.Errorf("invalid mnemonic error = %v, want %v", err, ErrInvalidMnemonic)
+	}
+	if _, err := DeriveFromMnemonic(testMnemonic, "", "m/44'/60'/x"); err == nil {
+		t.Error("invalid path error = nil")
+	}
+}
+
+// TestDeriveFromSeed BIP-32测试向量1
+func TestDeriveFromSeed(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tt := range tests {
+		key, err := DeriveFromSeed(seed, tt.path)
+		if err != nil {
+			t.Fatalf("DeriveFromSeed(%s) error = %v", tt.path, err)
+		}
+		if got := hex.EncodeToString(crypto.FromECDSA(key)); got != tt.key {
+			t.Errorf("DeriveFromSeed(%s) = %s, want %s", tt.path, got, tt.key)
+		}
+	}
+}
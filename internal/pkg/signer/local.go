@@ -0,0 +1,86 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// KeyLoader 按地址加载并解密私钥
+type KeyLoader func(ctx context.Context, address common.Address) (*ecdsa.PrivateKey, error)
+
+// LocalSigner 本地加密密钥库签名器, 私钥仅在签名期间解密驻留内存
+type LocalSigner struct {
+	loadKey KeyLoader
+}
+
+// NewLocalSigner 创建本地签名器
+func NewLocalSigner(loader KeyLoader) *LocalSigner {
+	return &LocalSigner{loadKey: loader}
+}
+
+func (s *LocalSigner) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	key, err := s.key(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+}
+
+func (s *LocalSigner) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	key, err := s.key(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+	return crypto.Sign(hash, key)
+}
+
+func (s *LocalSigner) SignText(ctx context.Context, address common.Address, data []byte) ([]byte, error) {
+	signature, err := s.SignHash(ctx, address, accounts.TextHash(data))
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+func (s *LocalSigner) SignTypedData(ctx context.Context, address common.Address, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.SignHash(ctx, address, hash)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// key 加载私钥并校验与地址一致
+func (s *LocalSigner) key(ctx context.Context, address common.Address) (*ecdsa.PrivateKey, error) {
+	key, err := s.loadKey(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyToAddress(key.PublicKey) != address {
+		zeroKey(key)
+		return nil, ErrSignerMismatch
+	}
+	return key, nil
+}
+
+// zeroKey 清除内存中的私钥
+func zeroKey(key *ecdsa.PrivateKey) {
+	if key != nil && key.D != nil {
+		key.D.SetUint64(0)
+	}
+}
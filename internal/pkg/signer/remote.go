@@ -0,0 +1,138 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const (
+	ProtocolWeb3Signer = "web3signer"
+	ProtocolClef       = "clef"
+)
+
+// RemoteSigner 远程签名器, 通过Web3Signer或Clef的JSON-RPC接口签名, 私钥不进入本进程
+type RemoteSigner struct {
+	client   *rpc.Client
+	protocol string
+}
+
+// NewRemoteSigner 连接远程签名服务
+func NewRemoteSigner(ctx context.Context, url, protocol string) (*RemoteSigner, error) {
+	if protocol != ProtocolWeb3Signer && protocol != ProtocolClef {
+		return nil, fmt.Errorf("unsupported signer protocol: %s", protocol)
+	}
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{client: client, protocol: protocol}, nil
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := toSendTxArgs(address, tx, chainID)
+
+	var signed types.Transaction
+	switch s.protocol {
+	case ProtocolClef:
+		// Clef返回 {raw, tx}
+		var result struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}
+		if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+			return nil, err
+		}
+		if err := signed.UnmarshalBinary(result.Raw); err != nil {
+			return nil, err
+		}
+	default:
+		// Web3Signer返回RLP编码的签名交易
+		var raw hexutil.Bytes
+		if err := s.client.CallContext(ctx, &raw, "eth_signTransaction", args); err != nil {
+			return nil, err
+		}
+		if err := signed.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	// 校验远程签名器返回的交易内容未被修改
+	txSigner := types.LatestSignerForChainID(chainID)
+	if txSigner.Hash(&signed) != txSigner.Hash(tx) {
+		return nil, ErrSignerMismatch
+	}
+	if err := checkSender(address, &signed, chainID); err != nil {
+		return nil, err
+	}
+	return &signed, nil
+}
+
+// SignHash Web3Signer与Clef均不提供裸哈希签名接口(eth_sign与account_signData均会加前缀),
+// 需要裸哈希签名的跨链桥验证者只能使用本地签名器, 启动时由BridgeLogic.CheckValidator校验
+func (s *RemoteSigner) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func (s *RemoteSigner) SignText(ctx context.Context, address common.Address, data []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	var err error
+	if s.protocol == ProtocolClef {
+		err = s.client.CallContext(ctx, &signature, "account_signData", "text/plain", common.NewMixedcaseAddress(address), hexutil.Bytes(data))
+	} else {
+		// Web3Signer的eth_sign按EIP-191加前缀后签名
+		err = s.client.CallContext(ctx, &signature, "eth_sign", address, hexutil.Bytes(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+func (s *RemoteSigner) SignTypedData(ctx context.Context, address common.Address, typedData apitypes.TypedData) ([]byte, error) {
+	var signature hexutil.Bytes
+	method := "eth_signTypedData"
+	if s.protocol == ProtocolClef {
+		method = "account_signTypedData"
+	}
+	if err := s.client.CallContext(ctx, &signature, method, common.NewMixedcaseAddress(address), typedData); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// Close 关闭连接
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+// toSendTxArgs 将交易转换为签名请求参数
+func toSendTxArgs(address common.Address, tx *types.Transaction, chainID *big.Int) apitypes.SendTxArgs {
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+	if tx.Type() == types.LegacyTxType {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+	return args
+}
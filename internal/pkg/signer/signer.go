@@ -0,0 +1,41 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	ErrUnsupported     = errors.New("operation not supported by signer")
+	ErrSignerMismatch  = errors.New("signed transaction sender mismatch")
+	ErrAccountNotFound = errors.New("signer account not found")
+)
+
+// Signer 签名器, 统一本地密钥与远程托管签名
+type Signer interface {
+	// SignTx 签名交易
+	SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// SignHash 对32字节哈希直接签名, 返回[R || S || V], V为0/1
+	SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error)
+	// SignText 按EIP-191(personal_sign)签名消息, 返回[R || S || V], V为27/28
+	SignText(ctx context.Context, address common.Address, data []byte) ([]byte, error)
+	// SignTypedData 按EIP-712签名结构化数据, 返回[R || S || V], V为27/28
+	SignTypedData(ctx context.Context, address common.Address, typedData apitypes.TypedData) ([]byte, error)
+}
+
+// checkSender 校验签名后交易的发送方
+func checkSender(address common.Address, tx *types.Transaction, chainID *big.Int) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return err
+	}
+	if sender != address {
+		return ErrSignerMismatch
+	}
+	return nil
+}
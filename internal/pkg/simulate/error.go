@@ -0,0 +1,81 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-wallet-defi/internal/model"
+)
+
+// CallError 模拟执行回滚的结构化错误
+type CallError struct {
+	To     string              // 目标地址
+	Method string              // 调用方法
+	Revert *model.RevertReason // 回滚原因
+}
+
+func (e *CallError) Error() string {
+	target := e.To
+	if target == "" {
+		target = "contract creation"
+	}
+	if e.Method != "" {
+		target += " (" + e.Method + ")"
+	}
+	return fmt.Sprintf("call to %s reverted: %s", target, e.Revert.Reason)
+}
+
+// DryRunError 模拟执行完成, 携带模拟结果中断发送流程
+type DryRunError struct {
+	Result *model.SimulationResult
+}
+
+func (e *DryRunError) Error() string {
+	return "dry run: transaction not sent"
+}
+
+type dryRunKey struct{}
+
+// dryRunState 模拟执行状态
+type dryRunState struct {
+	approvals []*model.SimulationResult // 模拟执行中未上链的授权交易
+}
+
+// WithDryRun 标记上下文为模拟执行, 发送路径只模拟不广播
+func WithDryRun(ctx context.Context, dryRun bool) context.Context {
+	if !dryRun {
+		return ctx
+	}
+	return context.WithValue(ctx, dryRunKey{}, &dryRunState{})
+}
+
+// IsDryRun 上下文是否为模拟执行
+func IsDryRun(ctx context.Context) bool {
+	_, ok := ctx.Value(dryRunKey{}).(*dryRunState)
+	return ok
+}
+
+// AddApproval 记录模拟执行中的授权交易, 授权交易未上链, 之后依赖该授权的交易无法在当前链上状态模拟
+func AddApproval(ctx context.Context, result *model.SimulationResult) {
+	if state, ok := ctx.Value(dryRunKey{}).(*dryRunState); ok {
+		state.approvals = append(state.approvals, result)
+	}
+}
+
+// Approvals 获取模拟执行中未上链的授权交易
+func Approvals(ctx context.Context) []*model.SimulationResult {
+	if state, ok := ctx.Value(dryRunKey{}).(*dryRunState); ok {
+		return state.approvals
+	}
+	return nil
+}
+
+// AsDryRun 从错误中取出模拟结果
+func AsDryRun(err error) (*model.SimulationResult, bool) {
+	var dryRunErr *DryRunError
+	if errors.As(err, &dryRunErr) {
+		return dryRunErr.Result, true
+	}
+	return nil, false
+}
@@ -0,0 +1,111 @@
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go-wallet-defi/internal/model"
+)
+
+var (
+	// errorSelector Error(string)
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// panicSelector Panic(uint256)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// panicReasons Solidity Panic错误码
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array encoding",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+// RevertData 从eth_call/eth_estimateGas错误中提取回滚数据, 非回滚错误返回false
+func RevertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if s, ok := dataErr.ErrorData().(string); ok {
+			if data, err := hexutil.Decode(s); err == nil {
+				return data, true
+			}
+		}
+	}
+	if strings.Contains(err.Error(), "revert") {
+		return nil, true
+	}
+	return nil, false
+}
+
+// DecodeRevert 解码回滚数据, 依次尝试Error(string)、Panic(uint256)与ABI中的自定义错误
+func DecodeRevert(data []byte, abis ...*abi.ABI) *model.RevertReason {
+	reason := &model.RevertReason{
+		Kind: model.RevertKindUnknown,
+		Data: hexutil.Encode(data),
+	}
+	if len(data) < 4 {
+		reason.Reason = "execution reverted"
+		return reason
+	}
+	selector, payload := data[:4], data[4:]
+	reason.Selector = hexutil.Encode(selector)
+
+	//1.Error(string)
+	if string(selector) == string(errorSelector) {
+		if message, err := abi.UnpackRevert(data); err == nil {
+			reason.Kind = model.RevertKindError
+			reason.Name = "Error"
+			reason.Reason = message
+			return reason
+		}
+	}
+
+	//2.Panic(uint256)
+	if string(selector) == string(panicSelector) && len(payload) >= 32 {
+		code := new(big.Int).SetBytes(payload[:32])
+		reason.Kind = model.RevertKindPanic
+		reason.Name = "Panic"
+		reason.Reason = fmt.Sprintf("panic 0x%x", code)
+		if code.IsUint64() {
+			if text, ok := panicReasons[code.Uint64()]; ok {
+				reason.Reason += ": " + text
+			}
+		}
+		return reason
+	}
+
+	//3.自定义错误
+	for _, parsed := range abis {
+		if parsed == nil {
+			continue
+		}
+		for _, abiErr := range parsed.Errors {
+			if string(abiErr.ID[:4]) != string(selector) {
+				continue
+			}
+			reason.Kind = model.RevertKindCustom
+			reason.Name = abiErr.Name
+			reason.Reason = abiErr.Sig
+			args := make(map[string]interface{})
+			if err := abiErr.Inputs.UnpackIntoMap(args, payload); err == nil && len(args) > 0 {
+				reason.Args = args
+			}
+			return reason
+		}
+	}
+
+	reason.Reason = "execution reverted with unknown error " + reason.Selector
+	return reason
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type IAdmin interface {
+	// RpcHealth 获取RPC节点健康状态
+	RpcHealth(ctx context.Context) []*model.RpcEndpointHealth
+	// WorkerJobs 获取后台任务的租约与运行状态
+	WorkerJobs(ctx context.Context) ([]*model.WorkerJob, error)
+}
+
+// Admin 获取运维管理服务
+func Admin() IAdmin {
+	if localAdmin == nil {
+		localAdmin = &logic.AdminLogic{}
+	}
+	return localAdmin
+}
+
+var localAdmin IAdmin
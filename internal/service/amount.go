@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type IAmount interface {
+	// Parse 按单位解析金额, 代币地址为空时使用链原生代币精度; unit为decimal或raw, 为空时使用配置的默认单位
+	Parse(ctx context.Context, chainId uint64, tokenAddress, amount, unit string) (*model.TokenAmount, error)
+
+	// Format 按代币精度格式化最小单位金额
+	Format(ctx context.Context, chainId uint64, tokenAddress, raw string) *model.TokenAmount
+
+	// TokenMeta 获取代币符号与精度
+	TokenMeta(ctx context.Context, chainId uint64, tokenAddress string) (*model.TokenMeta, error)
+}
+
+// Amount 获取金额解析服务
+func Amount() IAmount {
+	if localAmount == nil {
+		localAmount = &logic.AmountLogic{}
+	}
+	return localAmount
+}
+
+var localAmount IAmount
@@ -8,7 +8,7 @@ import (
 
 type IBridge interface {
 	// CrossTransfer 跨链转账
-	CrossTransfer(ctx context.Context, fromChainId, toChainId uint64, fromAddress, toAddress, tokenAddress, amount string) (hash string, nonce uint64, err error)
+	CrossTransfer(ctx context.Context, fromChainId, toChainId uint64, fromAddress, toAddress, tokenAddress, amount, unit string) (hash string, nonce uint64, err error)
 
 	// GetCrossTransfers 获取跨链交易列表
 	GetCrossTransfers(ctx context.Context, fromChainId, toChainId uint64, address string, status, page, pageSize int) ([]*model.CrossTransfer, int, error)
@@ -18,6 +18,9 @@ type IBridge interface {
 
 	// ProcessUnlockEvent 处理解锁事件
 	ProcessUnlockEvent(ctx context.Context, chainId uint64, token, to string, amount string, fromChainId uint64, nonce uint64, hash string) error
+
+	// CheckValidator 检查验证者签名配置, 配置了bridge.validator时要求使用local签名器
+	CheckValidator(ctx context.Context) error
 }
 
 // Bridge 获取跨链桥服务
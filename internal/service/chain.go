@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type IChain interface {
+	// SyncRegistry 将链注册表文件同步到chain表
+	SyncRegistry(ctx context.Context) error
+	// GetActiveList 获取所有启用的链
+	GetActiveList(ctx context.Context) ([]*model.Chain, error)
+}
+
+// Chain 获取链注册表服务
+func Chain() IChain {
+	if localChain == nil {
+		localChain = &logic.ChainLogic{}
+	}
+	return localChain
+}
+
+var localChain IChain
@@ -8,13 +8,13 @@ import (
 
 type IContract interface {
 	// Deploy 部署合约
-	Deploy(ctx context.Context, name, abi, bytecode, from, args, network string) (hash, address string, err error)
+	Deploy(ctx context.Context, chainId uint64, name, abi, bytecode, from, args string) (hash, address string, err error)
 
 	// Call 调用合约
-	Call(ctx context.Context, address, method, args, from, value string) (result interface{}, err error)
+	Call(ctx context.Context, chainId uint64, address, method, args, from, value string) (result interface{}, err error)
 
 	// GetEvents 获取合约事件
-	GetEvents(ctx context.Context, address, eventName string, fromBlock, toBlock int64, page, pageSize int) ([]*model.ContractEvent, int, error)
+	GetEvents(ctx context.Context, chainId uint64, address, eventName string, fromBlock, toBlock int64, page, pageSize int) ([]*model.ContractEvent, int, error)
 }
 
 // Contract 获取合约服务
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/cryptox"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+type CryptoService struct{}
+
+var Crypto = &CryptoService{}
+
+// RotateWalletKeys 使用当前主密钥重新加密所有钱包的助记词和私钥
+// 密钥环同时持有新旧密钥, 轮换期间服务可正常读取新旧密文, 无需停机
+func (s *CryptoService) RotateWalletKeys(ctx context.Context, batchSize int) (*model.KeyRotationResult, error) {
+	keyring, err := cryptox.Default()
+	if err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	result := &model.KeyRotationResult{}
+	var lastId uint64
+	for {
+		//1.分批读取钱包
+		wallets, err := dao.Wallet.GetSecretsAfterId(ctx, lastId, batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(wallets) == 0 {
+			break
+		}
+
+		for _, wallet := range wallets {
+			lastId = wallet.Id
+			result.Scanned++
+
+			//2.重新加密
+			mnemonic, mnemonicChanged, err := keyring.Rotate(wallet.Mnemonic)
+			if err != nil {
+				result.Failed++
+				g.Log().Errorf(ctx, "rotate mnemonic of wallet %d failed: %v", wallet.Id, err)
+				continue
+			}
+			privateKey, privateKeyChanged, err := keyring.Rotate(wallet.PrivateKey)
+			if err != nil {
+				result.Failed++
+				g.Log().Errorf(ctx, "rotate private key of wallet %d failed: %v", wallet.Id, err)
+				continue
+			}
+			if !mnemonicChanged && !privateKeyChanged {
+				result.Skipped++
+				continue
+			}
+
+			//3.乐观更新, 被并发修改的行跳过
+			updated, err := dao.Wallet.UpdateSecrets(ctx, wallet, mnemonic, privateKey)
+			if err != nil {
+				return result, err
+			}
+			if updated {
+				result.Rotated++
+			} else {
+				result.Skipped++
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type IDeadLetter interface {
+	// Get 获取失败的事件日志
+	Get(ctx context.Context, id uint64) (*model.EventDeadLetter, error)
+
+	// GetList 获取失败的事件日志列表, status小于0表示全部状态
+	GetList(ctx context.Context, chainId uint64, status int, page, pageSize int) ([]*model.EventDeadLetter, int, error)
+
+	// Retry 立即重试一条待重试或达到最大重试次数的记录, 返回重试后的记录
+	Retry(ctx context.Context, id uint64) (*model.EventDeadLetter, error)
+
+	// Discard 丢弃记录, 不再重试
+	Discard(ctx context.Context, id uint64) error
+
+	// Poll 重试到期的记录
+	Poll(ctx context.Context) error
+}
+
+// DeadLetter 获取事件死信服务
+func DeadLetter() IDeadLetter {
+	if localDeadLetter == nil {
+		localDeadLetter = &logic.DeadLetterLogic{}
+	}
+	return localDeadLetter
+}
+
+var localDeadLetter IDeadLetter
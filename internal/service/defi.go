@@ -41,6 +41,12 @@ type IDefi interface {
 
 	// WithdrawVault 提取机枪池
 	WithdrawVault(ctx context.Context, chainId uint64, vault string, shares string, fromAddress string) (hash string, amount string, err error)
+
+	// StakeToken 获取收益农场的质押代币地址
+	StakeToken(ctx context.Context, chainId uint64, pool string) (string, error)
+
+	// VaultToken 获取机枪池的存款代币地址
+	VaultToken(ctx context.Context, chainId uint64, vault string) (string, error)
 }
 
 // Defi 获取DeFi服务
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/pkg/devchain"
+)
+
+type IDev interface {
+	// Setup 初始化dev环境数据库并写入模拟链、模拟合约与预置账户钱包
+	Setup(ctx context.Context, node *devchain.Node, deployments []*devchain.Deployment, mnemonic string) error
+}
+
+// Dev 获取dev模式服务
+func Dev() IDev {
+	if localDev == nil {
+		localDev = &logic.DevLogic{}
+	}
+	return localDev
+}
+
+var localDev IDev
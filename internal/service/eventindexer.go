@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+)
+
+type IEventIndexer interface {
+	// Chains 获取分组中存在事件来源的链
+	Chains(ctx context.Context, group string) ([]uint64, error)
+	// Index 将分组在指定链上的全部来源从各自游标处索引到安全高度
+	Index(ctx context.Context, group string, chainId uint64) error
+}
+
+// EventIndexer 获取合约事件索引服务
+func EventIndexer() IEventIndexer {
+	if localEventIndexer == nil {
+		localEventIndexer = &logic.EventIndexerLogic{}
+	}
+	return localEventIndexer
+}
+
+var localEventIndexer IEventIndexer
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+)
+
+type IIndexer interface {
+	// Poll 扫描所有启用链的新区块, 记录托管钱包的收支
+	Poll(ctx context.Context) error
+}
+
+// Indexer 获取交易索引服务
+func Indexer() IIndexer {
+	if localIndexer == nil {
+		localIndexer = &logic.IndexerLogic{}
+	}
+	return localIndexer
+}
+
+var localIndexer IIndexer
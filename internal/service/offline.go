@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type IOffline interface {
+	// Build 生成待签名交易信封
+	Build(ctx context.Context, params *model.OfflineTxParams) (*model.TxEnvelope, error)
+
+	// Submit 校验外部签名的原始交易与信封一致后广播
+	Submit(ctx context.Context, id uint64, rawTx string) (string, error)
+}
+
+// Offline 获取离线签名服务
+func Offline() IOffline {
+	if localOffline == nil {
+		localOffline = &logic.OfflineLogic{}
+	}
+	return localOffline
+}
+
+var localOffline IOffline
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type IPayout interface {
+	// Create 校验全部输入行并创建批量付款任务, 存在无效行时返回行错误
+	Create(ctx context.Context, params *model.PayoutParams) (*model.PayoutJob, []*model.PayoutRowError, error)
+
+	// Detail 获取任务及明细行
+	Detail(ctx context.Context, id uint64) (*model.PayoutJob, []*model.PayoutItem, error)
+
+	// GetJobs 获取用户的批量付款任务列表
+	GetJobs(ctx context.Context, userId uint64, page, pageSize int) ([]*model.PayoutJob, int, error)
+
+	// Poll 推进待执行与执行中的任务
+	Poll(ctx context.Context) error
+}
+
+// Payout 获取批量付款服务
+func Payout() IPayout {
+	if localPayout == nil {
+		localPayout = &logic.PayoutLogic{}
+	}
+	return localPayout
+}
+
+var localPayout IPayout
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type IReindex interface {
+	// Create 校验目标与区块范围并创建重新索引任务
+	Create(ctx context.Context, params *model.ReindexParams) (*model.ReindexJob, error)
+
+	// Get 获取重新索引任务
+	Get(ctx context.Context, id uint64) (*model.ReindexJob, error)
+
+	// GetList 获取重新索引任务列表
+	GetList(ctx context.Context, page, pageSize int) ([]*model.ReindexJob, int, error)
+
+	// Cancel 取消待执行或执行中的任务
+	Cancel(ctx context.Context, id uint64) error
+
+	// Poll 执行待执行的任务, 并接管执行实例中断的任务
+	Poll(ctx context.Context) error
+
+	// Run 在当前进程中认领并执行任务直到完成
+	Run(ctx context.Context, id uint64) (*model.ReindexJob, error)
+}
+
+// Reindex 获取重新索引服务
+func Reindex() IReindex {
+	if localReindex == nil {
+		localReindex = &logic.ReindexLogic{}
+	}
+	return localReindex
+}
+
+var localReindex IReindex
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-wallet-defi/internal/dao"
+	"go-wallet-defi/internal/model"
+	"go-wallet-defi/internal/pkg/cronx"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	defaultScheduleBatchSize    = 50  // 每轮处理的定时转账与重试数
+	defaultScheduleStaleTimeout = 600 // 执行中超过该秒数视为进程中断
+)
+
+type ScheduleService struct{}
+
+var Schedule = &ScheduleService{}
+
+// Create 创建定时转账
+func (s *ScheduleService) Create(ctx context.Context, params *model.ScheduleParams) (*model.ScheduledTransfer, error) {
+	//1.校验地址与金额
+	if !common.IsHexAddress(params.To) {
+		return nil, fmt.Errorf("invalid to address %q", params.To)
+	}
+	if params.Token != "" && !common.IsHexAddress(params.Token) {
+		return nil, fmt.Errorf("invalid token address %q", params.Token)
+	}
+	amount, ok := new(big.Int).SetString(params.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+	wallet, err := dao.Wallet.GetByAddress(ctx, params.From)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, errors.New("wallet not found")
+	}
+
+	//2.校验执行计划
+	schedule, err := cronx.Parse(params.Cron)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	startAt := params.StartAt
+	if startAt < now {
+		startAt = now
+	}
+	if params.EndAt != 0 && params.EndAt <= startAt {
+		return nil, errors.New("end time must be after start time")
+	}
+	if params.MaxRuns < 0 || params.MaxRetries < 0 || params.RetryInterval < 0 {
+		return nil, errors.New("max runs and retry policy must not be negative")
+	}
+	nextRunAt := s.nextRunAt(schedule, startAt-1)
+	if nextRunAt == 0 || (params.EndAt != 0 && nextRunAt > params.EndAt) {
+		return nil, errors.New("cron has no run time within the schedule window")
+	}
+
+	//3.保存
+	transfer := &model.ScheduledTransfer{
+		UserId:        wallet.UserId,
+		ChainId:       params.ChainId,
+		FromAddress:   params.From,
+		ToAddress:     params.To,
+		TokenAddress:  params.Token,
+		Amount:        amount.String(),
+		Cron:          params.Cron,
+		GasStrategy:   params.GasStrategy,
+		StartAt:       startAt,
+		EndAt:         params.EndAt,
+		MaxRuns:       params.MaxRuns,
+		MaxRetries:    params.MaxRetries,
+		RetryInterval: params.RetryInterval,
+		NextRunAt:     nextRunAt,
+		Status:        model.ScheduleStatusActive,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := dao.Schedule.Insert(ctx, transfer); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// Pause 暂停定时转账
+func (s *ScheduleService) Pause(ctx context.Context, id uint64) error {
+	transfer, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if transfer.Status != model.ScheduleStatusActive {
+		return fmt.Errorf("schedule %d is not active", id)
+	}
+	return dao.Schedule.Update(ctx, id, g.Map{"status": model.ScheduleStatusPaused})
+}
+
+// Resume 恢复定时转账, 暂停期间错过的执行不再补发
+func (s *ScheduleService) Resume(ctx context.Context, id uint64) error {
+	transfer, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if transfer.Status != model.ScheduleStatusPaused {
+		return fmt.Errorf("schedule %d is not paused", id)
+	}
+	schedule, err := cronx.Parse(transfer.Cron)
+	if err != nil {
+		return err
+	}
+	from := time.Now().Unix()
+	if transfer.StartAt > from {
+		from = transfer.StartAt - 1
+	}
+	data := g.Map{"status": model.ScheduleStatusActive}
+	nextRunAt := s.nextRunAt(schedule, from)
+	if s.finished(transfer, transfer.RunCount, nextRunAt) {
+		data["status"] = model.ScheduleStatusFinished
+	} else {
+		data["next_run_at"] = nextRunAt
+	}
+	return dao.Schedule.Update(ctx, id, data)
+}
+
+// Cancel 取消定时转账, 已登记的重试不再执行
+func (s *ScheduleService) Cancel(ctx context.Context, id uint64) error {
+	transfer, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == model.ScheduleStatusFinished || transfer.Status == model.ScheduleStatusCancelled {
+		return fmt.Errorf("schedule %d is already closed", id)
+	}
+	return dao.Schedule.Update(ctx, id, g.Map{"status": model.ScheduleStatusCancelled})
+}
+
+// GetList 获取用户的定时转账列表
+func (s *ScheduleService) GetList(ctx context.Context, userId uint64, page, pageSize int) ([]*model.ScheduledTransfer, int, error) {
+	return dao.Schedule.GetList(ctx, userId, page, pageSize)
+}
+
+// GetRuns 获取定时转账的执行记录
+func (s *ScheduleService) GetRuns(ctx context.Context, id uint64, page, pageSize int) ([]*model.ScheduledRun, int, error) {
+	return dao.Schedule.GetRuns(ctx, id, page, pageSize)
+}
+
+// Poll 执行到期的定时转账与待重试的执行记录
+func (s *ScheduleService) Poll(ctx context.Context) error {
+	now := time.Now().Unix()
+	limit := g.Cfg().MustGet(ctx, "schedule.batchSize", defaultScheduleBatchSize).Int()
+
+	//1.进程中断遗留的执行中记录, 交易可能已广播, 标记失败待人工核对
+	staleTimeout := g.Cfg().MustGet(ctx, "schedule.staleTimeout", defaultScheduleStaleTimeout).Int64()
+	if err := dao.Schedule.FailStaleRuns(ctx, now-staleTimeout, "run interrupted, check transaction history before retrying"); err != nil {
+		g.Log().Warningf(ctx, "fail stale scheduled runs failed: %v", err)
+	}
+
+	//2.重试失败的执行
+	runs, err := dao.Schedule.GetRetryRuns(ctx, now, limit)
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		transfer, err := dao.Schedule.GetById(ctx, run.ScheduleId)
+		if err != nil {
+			return err
+		}
+		if transfer == nil || transfer.Status == model.ScheduleStatusCancelled {
+			if err := dao.Schedule.UpdateRun(ctx, run.Id, g.Map{"status": model.ScheduledRunStatusFailed}); err != nil {
+				return err
+			}
+			continue
+		}
+		// 暂停期间保留重试, 恢复后继续
+		if transfer.Status == model.ScheduleStatusPaused {
+			continue
+		}
+		ok, err := dao.Schedule.ClaimRun(ctx, run.Id, model.ScheduledRunStatusRetrying)
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.execute(ctx, transfer, run)
+		}
+	}
+
+	//3.登记并执行到期的定时转账
+	transfers, err := dao.Schedule.GetDue(ctx, now, limit)
+	if err != nil {
+		return err
+	}
+	for _, transfer := range transfers {
+		if err := s.runDue(ctx, transfer, now); err != nil {
+			g.Log().Warningf(ctx, "run schedule %d failed: %v", transfer.Id, err)
+		}
+	}
+	return nil
+}
+
+// runDue 登记本次执行并推进下次执行时间, 停机期间错过的多个计划时间只补执行一次
+func (s *ScheduleService) runDue(ctx context.Context, transfer *model.ScheduledTransfer, now int64) error {
+	//1.结束时间之后不再执行
+	if transfer.EndAt != 0 && transfer.NextRunAt > transfer.EndAt {
+		return dao.Schedule.Update(ctx, transfer.Id, g.Map{"status": model.ScheduleStatusFinished})
+	}
+
+	//2.计算下次执行时间
+	schedule, err := cronx.Parse(transfer.Cron)
+	if err != nil {
+		return err
+	}
+	data := g.Map{"last_run_at": now}
+	nextRunAt := s.nextRunAt(schedule, now)
+	if s.finished(transfer, transfer.RunCount+1, nextRunAt) {
+		data["status"] = model.ScheduleStatusFinished
+	} else {
+		data["next_run_at"] = nextRunAt
+	}
+
+	//3.登记执行
+	run := &model.ScheduledRun{
+		ScheduleId:  transfer.Id,
+		ScheduledAt: transfer.NextRunAt,
+		Status:      model.ScheduledRunStatusRunning,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	ok, err := dao.Schedule.Claim(ctx, transfer.Id, transfer.NextRunAt, run, data)
+	if err != nil || !ok {
+		return err
+	}
+
+	//4.执行
+	s.execute(ctx, transfer, run)
+	return nil
+}
+
+// execute 经限额与风控检查后执行转账, 失败时按重试策略登记重试
+func (s *ScheduleService) execute(ctx context.Context, transfer *model.ScheduledTransfer, run *model.ScheduledRun) {
+	run.Attempt++
+
+	//1.与手动转账相同的限额与风控检查
+	var hash string
+	err := Security.CheckTransfer(ctx, transfer.FromAddress, transfer.ToAddress, transfer.TokenAddress, transfer.Amount)
+
+	//2.转账
+	if err == nil {
+		fees := &model.TxFeeParams{Strategy: transfer.GasStrategy}
+		if transfer.TokenAddress == "" {
+			hash, err = Transaction().TransferEth(ctx, transfer.ChainId, transfer.FromAddress, transfer.ToAddress, transfer.Amount, fees, 0)
+		} else {
+			hash, err = Transaction().TransferToken(ctx, transfer.ChainId, transfer.FromAddress, transfer.ToAddress, transfer.Amount, transfer.TokenAddress, fees, 0)
+		}
+	}
+
+	//3.记录结果
+	data := g.Map{"attempt": run.Attempt}
+	switch {
+	case err == nil:
+		data["status"] = model.ScheduledRunStatusSent
+		data["hash"] = hash
+		data["error"] = ""
+	case run.Attempt <= transfer.MaxRetries:
+		// 第n次重试间隔为 retryInterval * 2^(n-1)
+		delay := transfer.RetryInterval << uint(run.Attempt-1)
+		data["status"] = model.ScheduledRunStatusRetrying
+		data["error"] = err.Error()
+		data["next_retry_at"] = time.Now().Unix() + delay
+	default:
+		data["status"] = model.ScheduledRunStatusFailed
+		data["error"] = err.Error()
+	}
+	if err != nil {
+		g.Log().Warningf(ctx, "scheduled transfer %d attempt %d failed: %v", transfer.Id, run.Attempt, err)
+	}
+	if updateErr := dao.Schedule.UpdateRun(ctx, run.Id, data); updateErr != nil {
+		g.Log().Errorf(ctx, "save scheduled run %d failed: %v", run.Id, updateErr)
+	}
+}
+
+// get 获取定时转账
+func (s *ScheduleService) get(ctx context.Context, id uint64) (*model.ScheduledTransfer, error) {
+	transfer, err := dao.Schedule.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, errors.New("schedule not found")
+	}
+	return transfer, nil
+}
+
+// nextRunAt 计算from之后的下次执行时间, 无可执行时间时返回0
+func (s *ScheduleService) nextRunAt(schedule *cronx.Schedule, from int64) int64 {
+	next := schedule.Next(time.Unix(from, 0))
+	if next.IsZero() {
+		return 0
+	}
+	return next.Unix()
+}
+
+// finished 判断执行runCount次后是否已结束
+func (s *ScheduleService) finished(transfer *model.ScheduledTransfer, runCount int, nextRunAt int64) bool {
+	if transfer.MaxRuns > 0 && runCount >= transfer.MaxRuns {
+		return true
+	}
+	return nextRunAt == 0 || (transfer.EndAt != 0 && nextRunAt > transfer.EndAt)
+}
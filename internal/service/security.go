@@ -266,8 +266,17 @@ func (s *SecurityService) CreateWhitelist(ctx context.Context, userId uint64, ad
 	return dao.Security.CreateWhitelist(ctx, whitelist)
 }
 
-// CreateTransactionLimit 创建交易限额
-func (s *SecurityService) CreateTransactionLimit(ctx context.Context, userId uint64, tokenAddress string, singleLimit, dailyLimit, weeklyLimit, monthlyLimit string) error {
+// CreateTransactionLimit 创建交易限额, 限额按单位解析后以最小单位保存
+func (s *SecurityService) CreateTransactionLimit(ctx context.Context, userId, chainId uint64, tokenAddress string, singleLimit, dailyLimit, weeklyLimit, monthlyLimit, unit string) error {
+	limits := []*string{&singleLimit, &dailyLimit, &weeklyLimit, &monthlyLimit}
+	for _, limit := range limits {
+		amount, err := Amount().Parse(ctx, chainId, tokenAddress, *limit, unit)
+		if err != nil {
+			return err
+		}
+		*limit = amount.Raw
+	}
+
 	limit := &model.TransactionLimit{
 		UserId:       userId,
 		TokenAddress: tokenAddress,
@@ -280,72 +289,106 @@ func (s *SecurityService) CreateTransactionLimit(ctx context.Context, userId uin
 	return dao.Security.CreateTransactionLimit(ctx, limit)
 }
 
-// CheckTransactionLimit 检查交易限额
-func (s *SecurityService) CheckTransactionLimit(ctx context.Context, userId uint64, tokenAddress string, amount string) error {
-	// 获取限额配置
-	limit, err := dao.Security.GetTransactionLimit(ctx, userId)
+// CheckTransfer 转账前的限额与风控检查, 手动转账与定时转账共用
+func (s *SecurityService) CheckTransfer(ctx context.Context, from, to, tokenAddress, amount string) error {
+	//1.获取钱包所属用户
+	wallet, err := dao.Wallet.GetByAddress(ctx, from)
 	if err != nil {
 		return err
 	}
-
-	// 检查单笔限额
-	amountBig, _ := new(big.Int).SetString(amount, 10)
-	singleLimitBig, _ := new(big.Int).SetString(limit.SingleLimit, 10)
-	if amountBig.Cmp(singleLimitBig) > 0 {
-		return errors.New("exceed single limit")
+	if wallet == nil {
+		return errors.New("wallet not found")
 	}
 
-	// 检查日限额
-	now := time.Now()
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
-	todayEnd := todayStart.Add(24 * time.Hour)
-
-	dailyAmount, err := dao.Security.GetUserTxAmount(ctx, userId, tokenAddress, todayStart.Unix(), todayEnd.Unix())
-	if err != nil {
+	//2.检查限额
+	if err := s.CheckTransactionLimit(ctx, wallet.UserId, tokenAddress, amount); err != nil {
 		return err
 	}
 
-	dailyAmountBig, _ := new(big.Int).SetString(dailyAmount, 10)
-	dailyLimitBig, _ := new(big.Int).SetString(limit.DailyLimit, 10)
-
-	total := new(big.Int).Add(dailyAmountBig, amountBig)
-	if total.Cmp(dailyLimitBig) > 0 {
-		return errors.New("exceed daily limit")
-	}
+	//3.检查风控规则
+	return s.CheckRiskRules(ctx, wallet.UserId, map[string]interface{}{
+		"address": to,
+		"amount":  amount,
+		"token":   tokenAddress,
+	})
+}
 
-	// 检查周限额
-	weekStart := todayStart.AddDate(0, 0, -int(todayStart.Weekday()))
-	weekAmount, err := dao.Security.GetUserTxAmount(ctx, userId, tokenAddress, weekStart.Unix(), todayEnd.Unix())
+// CheckTransactionLimit 检查交易限额, 未配置限额或限额为空时不限制
+func (s *SecurityService) CheckTransactionLimit(ctx context.Context, userId uint64, tokenAddress string, amount string) error {
+	// 获取限额配置
+	limit, err := dao.Security.GetUserTransactionLimit(ctx, userId, tokenAddress)
 	if err != nil {
 		return err
 	}
-
-	weekAmountBig, _ := new(big.Int).SetString(weekAmount, 10)
-	weeklyLimitBig, _ := new(big.Int).SetString(limit.WeeklyLimit, 10)
-
-	total = new(big.Int).Add(weekAmountBig, amountBig)
-	if total.Cmp(weeklyLimitBig) > 0 {
-		return errors.New("exceed weekly limit")
+	if limit == nil {
+		return nil
 	}
 
-	// 检查月限额
-	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
-	monthAmount, err := dao.Security.GetUserTxAmount(ctx, userId, tokenAddress, monthStart.Unix(), todayEnd.Unix())
+	// 检查单笔限额
+	amountBig, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q", amount)
+	}
+	exceeded, err := exceedLimit(big.NewInt(0), amountBig, limit.SingleLimit)
 	if err != nil {
 		return err
 	}
+	if exceeded {
+		return errors.New("exceed single limit")
+	}
 
-	monthAmountBig, _ := new(big.Int).SetString(monthAmount, 10)
-	monthlyLimitBig, _ := new(big.Int).SetString(limit.MonthlyLimit, 10)
+	// 检查日限额
+	now := time.Now()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	todayEnd := todayStart.Add(24 * time.Hour)
 
-	total = new(big.Int).Add(monthAmountBig, amountBig)
-	if total.Cmp(monthlyLimitBig) > 0 {
-		return errors.New("exceed monthly limit")
+	windows := []struct {
+		start int64
+		limit string
+		err   string
+	}{
+		{todayStart.Unix(), limit.DailyLimit, "exceed daily limit"},
+		// 检查周限额
+		{todayStart.AddDate(0, 0, -int(todayStart.Weekday())).Unix(), limit.WeeklyLimit, "exceed weekly limit"},
+		// 检查月限额
+		{time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).Unix(), limit.MonthlyLimit, "exceed monthly limit"},
+	}
+	for _, window := range windows {
+		if window.limit == "" {
+			continue
+		}
+		used, err := dao.Security.GetUserTxAmount(ctx, userId, tokenAddress, window.start, todayEnd.Unix())
+		if err != nil {
+			return err
+		}
+		usedBig, ok := new(big.Int).SetString(used, 10)
+		if !ok {
+			usedBig = big.NewInt(0)
+		}
+		exceeded, err := exceedLimit(usedBig, amountBig, window.limit)
+		if err != nil {
+			return err
+		}
+		if exceeded {
+			return errors.New(window.err)
+		}
 	}
 
 	return nil
 }
 
+// exceedLimit 判断已用金额加本次金额是否超过限额, 限额为空时不限制, 无法解析时返回错误
+func exceedLimit(used, amount *big.Int, limit string) (bool, error) {
+	if limit == "" {
+		return false, nil
+	}
+	limitBig, ok := new(big.Int).SetString(limit, 10)
+	if !ok {
+		return false, fmt.Errorf("invalid transaction limit %q", limit)
+	}
+	return new(big.Int).Add(used, amount).Cmp(limitBig) > 0, nil
+}
+
 // CreateRiskRule 创建风控规则
 func (s *SecurityService) CreateRiskRule(ctx context.Context, name string, ruleType int, content map[string]interface{}, action int) error {
 	contentJson, _ := json.Marshal(content)
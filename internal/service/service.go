@@ -9,9 +9,17 @@ import (
 // 定义接口管理器
 type IWallet interface {
 	Create(ctx context.Context, chain string) (*model.Wallet, error)
-	Import(ctx context.Context, chain, mnemonic string) (*model.Wallet, error)
-	GetList(ctx context.Context, page, pageSize int) ([]*model.Wallet, error)
-	GetBalance(ctx context.Context, address string) (string, error)
+	Import(ctx context.Context, chain, mnemonic string, index uint32) (*model.Wallet, error)
+	Derive(ctx context.Context, address string, index uint32) (*model.Wallet, error)
+	ImportPrivateKey(ctx context.Context, chain, privateKey string) (*model.Wallet, error)
+	ImportKeystore(ctx context.Context, chain, keystoreJson, password string) (*model.Wallet, error)
+	ExportKeystore(ctx context.Context, address, password string) (string, error)
+	GetList(ctx context.Context, filter *model.WalletFilter, page, pageSize int) ([]*model.Wallet, int, error)
+	AddWatchOnly(ctx context.Context, chain, address, label string, tags []string, groupId uint64) (*model.Wallet, error)
+	UpdateMeta(ctx context.Context, address, label string, tags []string, groupId uint64) error
+	CreateGroup(ctx context.Context, userId uint64, name, description string) (*model.WalletGroup, error)
+	GetGroups(ctx context.Context, userId uint64) ([]*model.WalletGroup, error)
+	GetBalance(ctx context.Context, address, fiat string) (*model.WalletBalance, error)
 }
 
 var (
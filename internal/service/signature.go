@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"go-wallet-defi/internal/model"
+)
+
+type ISignature interface {
+	// SignMessage EIP-191消息签名
+	SignMessage(ctx context.Context, address, message string) (signature string, err error)
+
+	// SignTypedData EIP-712结构化数据签名
+	SignTypedData(ctx context.Context, address, typedData string) (signature, hash string, err error)
+
+	// Verify 验证签名, 支持EOA与EIP-1271合约钱包
+	Verify(ctx context.Context, chainId uint64, address, message, typedData, signature string) (*model.SignatureVerification, error)
+}
+
+// Signature 获取签名服务
+func Signature() ISignature {
+	if localSignature == nil {
+		localSignature = &logic.SignatureLogic{}
+	}
+	return localSignature
+}
+
+var localSignature ISignature
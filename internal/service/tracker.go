@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+)
+
+type ITracker interface {
+	// Poll 登记待确认交易并推进跟踪状态
+	Poll(ctx context.Context) error
+
+	// TrackHash 立即推进指定交易的跟踪状态
+	TrackHash(ctx context.Context, chainId uint64, hash string) error
+}
+
+// Tracker 获取交易跟踪服务
+func Tracker() ITracker {
+	if localTracker == nil {
+		localTracker = &logic.TrackerLogic{}
+	}
+	return localTracker
+}
+
+var localTracker ITracker
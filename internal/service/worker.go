@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"go-wallet-defi/internal/logic"
+	"time"
+)
+
+type IWorker interface {
+	// Acquire 获取或续约任务租约, 返回当前实例是否为该任务的leader
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release 释放任务租约
+	Release(ctx context.Context, name, holder string) error
+	// Start 记录任务开始执行
+	Start(ctx context.Context, name, holder string) error
+	// Finish 记录任务执行结束
+	Finish(ctx context.Context, name, holder string, runErr error) error
+}
+
+// Worker 获取后台任务租约服务
+func Worker() IWorker {
+	if localWorker == nil {
+		localWorker = &logic.WorkerLogic{}
+	}
+	return localWorker
+}
+
+var localWorker IWorker
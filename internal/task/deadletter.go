@@ -0,0 +1,20 @@
+package task
+
+import (
+	"context"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 按指数退避重试处理失败的事件日志
+func init() {
+	Register(&Job{
+		Name:        "deadletter",
+		Brief:       "retry failed event handlers",
+		IntervalKey: "deadletter.interval",
+		Interval:    "30s",
+		Run: func(ctx context.Context) error {
+			return service.DeadLetter().Poll(ctx)
+		},
+	})
+}
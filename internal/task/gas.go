@@ -0,0 +1,32 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 更新所有启用链的Gas价格与Gas策略, 单条链失败不影响其他链
+func init() {
+	Register(&Job{
+		Name:        "gas",
+		Brief:       "update gas prices",
+		IntervalKey: "gas.interval",
+		Interval:    "15s",
+		Run: func(ctx context.Context) error {
+			chains, err := service.Chain().GetActiveList(ctx)
+			if err != nil {
+				return err
+			}
+			var errs []error
+			for _, chain := range chains {
+				if err := service.Gas.UpdateGasPrices(ctx, chain.ChainId); err != nil {
+					errs = append(errs, fmt.Errorf("chain %d: %w", chain.ChainId, err))
+				}
+			}
+			return errors.Join(errs...)
+		},
+	})
+}
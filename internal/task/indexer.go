@@ -0,0 +1,20 @@
+package task
+
+import (
+	"context"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 扫描区块, 将托管钱包的转入转出记录到交易表
+func init() {
+	Register(&Job{
+		Name:        "indexer",
+		Brief:       "index managed wallet transfers",
+		IntervalKey: "indexer.interval",
+		Interval:    "15s",
+		Run: func(ctx context.Context) error {
+			return service.Indexer().Poll(ctx)
+		},
+	})
+}
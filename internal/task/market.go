@@ -0,0 +1,20 @@
+package task
+
+import (
+	"context"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 更新市场指标数据
+func init() {
+	Register(&Job{
+		Name:        "market",
+		Brief:       "update market indicators",
+		IntervalKey: "market.interval",
+		Interval:    "5m",
+		Run: func(ctx context.Context) error {
+			return service.Analysis.UpdateMarketData(ctx)
+		},
+	})
+}
@@ -0,0 +1,20 @@
+package task
+
+import (
+	"context"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 执行批量付款任务, 进程重启后从已保存的批次状态继续
+func init() {
+	Register(&Job{
+		Name:        "payout",
+		Brief:       "send bulk payouts",
+		IntervalKey: "payout.interval",
+		Interval:    "10s",
+		Run: func(ctx context.Context) error {
+			return service.Payout().Poll(ctx)
+		},
+	})
+}
@@ -0,0 +1,20 @@
+package task
+
+import (
+	"context"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 执行通过reindex命令或运维接口创建的重新索引任务
+func init() {
+	Register(&Job{
+		Name:        "reindex",
+		Brief:       "run reindex jobs",
+		IntervalKey: "reindex.interval",
+		Interval:    "10s",
+		Run: func(ctx context.Context) error {
+			return service.Reindex().Poll(ctx)
+		},
+	})
+}
@@ -0,0 +1,20 @@
+package task
+
+import (
+	"context"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 执行到期的定时转账与失败重试
+func init() {
+	Register(&Job{
+		Name:        "schedule",
+		Brief:       "run scheduled transfers",
+		IntervalKey: "schedule.interval",
+		Interval:    "30s",
+		Run: func(ctx context.Context) error {
+			return service.Schedule.Poll(ctx)
+		},
+	})
+}
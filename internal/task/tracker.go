@@ -0,0 +1,20 @@
+package task
+
+import (
+	"context"
+
+	"go-wallet-defi/internal/service"
+)
+
+// 跟踪交易生命周期, 推进确认数并处理丢弃、替换与重组
+func init() {
+	Register(&Job{
+		Name:        "tracker",
+		Brief:       "track transaction lifecycle",
+		IntervalKey: "tracker.interval",
+		Interval:    "10s",
+		Run: func(ctx context.Context) error {
+			return service.Tracker().Poll(ctx)
+		},
+	})
+}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/pkg/ethclientx"
+	"go-wallet-defi/internal/service"
+)
+
+// chainWatcher 单条链上运行中的事件索引
+type chainWatcher struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// runEventIndexer 定期加载分组中存在事件来源的链, 每条链在出现新区块时从游标处索引一轮;
+// 节点支持时通过newHeads订阅获知新区块, 否则轮询; ctx结束时停止全部链的索引后返回
+func runEventIndexer(ctx context.Context, group string) {
+	refresh := g.Cfg().MustGet(ctx, "watch.refreshInterval", "30s").Duration()
+	watchers := make(map[uint64]*chainWatcher)
+	defer func() {
+		for _, watcher := range watchers {
+			watcher.stop()
+		}
+	}()
+
+	for {
+		chainIds, err := service.EventIndexer().Chains(ctx, group)
+		if err != nil {
+			if ctx.Err() == nil {
+				g.Log().Error(ctx, err)
+			}
+			if !sleep(ctx, refresh) {
+				return
+			}
+			continue
+		}
+
+		//1.停止已无事件来源的链
+		active := make(map[uint64]bool, len(chainIds))
+		for _, chainId := range chainIds {
+			active[chainId] = true
+		}
+		for chainId, watcher := range watchers {
+			if !active[chainId] {
+				watcher.stop()
+				delete(watchers, chainId)
+			}
+		}
+
+		//2.启动新链, 重启已退出的链
+		for _, chainId := range chainIds {
+			if watcher, ok := watchers[chainId]; ok && !watcher.exited() {
+				continue
+			}
+			watchCtx, cancel := context.WithCancel(ctx)
+			watcher := &chainWatcher{cancel: cancel, done: make(chan struct{})}
+			watchers[chainId] = watcher
+			go func(chainId uint64) {
+				defer close(watcher.done)
+				watchChain(watchCtx, group, chainId)
+			}(chainId)
+		}
+
+		if !sleep(ctx, refresh) {
+			return
+		}
+	}
+}
+
+// watchChain 每出现新区块时索引一轮
+func watchChain(ctx context.Context, group string, chainId uint64) {
+	err := ethclientx.WatchHeads(ctx, chainId, func(ctx context.Context, head uint64) {
+		if err := service.EventIndexer().Index(ctx, group, chainId); err != nil && ctx.Err() == nil {
+			g.Log().Warningf(ctx, "%s indexer on chain %d at head %d failed: %v", group, chainId, head, err)
+		}
+	})
+	if err != nil && ctx.Err() == nil {
+		g.Log().Errorf(ctx, "%s indexer on chain %d stopped: %v", group, chainId, err)
+	}
+}
+
+// stop 停止索引并等待退出, 避免新旧索引同时处理同一日志
+func (w *chainWatcher) stop() {
+	w.cancel()
+	<-w.done
+}
+
+// exited 判断索引是否已退出
+func (w *chainWatcher) exited() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
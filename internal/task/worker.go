@@ -0,0 +1,248 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"go-wallet-defi/internal/service"
+)
+
+// minLeaseTTL 租约有效期下限, 续约间隔为有效期的1/3
+const minLeaseTTL = 3 * time.Second
+
+// Job 后台任务; 多个worker实例同时运行时, 每个任务只在持有其租约的实例上执行
+type Job struct {
+	Name        string                          // 任务名称, 同时作为租约名称
+	Brief       string                          // 任务说明
+	IntervalKey string                          // 执行间隔配置项, 为空表示常驻任务
+	Interval    string                          // 默认执行间隔
+	Run         func(ctx context.Context) error // 执行一轮; 常驻任务持续运行直到ctx结束
+}
+
+// jobs 已注册的后台任务, 按注册顺序
+var jobs []*Job
+
+// Register 注册后台任务, 名称重复时panic
+func Register(job *Job) {
+	for _, registered := range jobs {
+		if registered.Name == job.Name {
+			panic(fmt.Sprintf("job %s already registered", job.Name))
+		}
+	}
+	jobs = append(jobs, job)
+}
+
+// Jobs 获取已注册的后台任务
+func Jobs() []*Job {
+	return jobs
+}
+
+// worker 当前实例的任务运行器
+type worker struct {
+	instance string        // 实例标识, 作为租约持有者
+	ttl      time.Duration // 租约有效期
+	renew    time.Duration // 续约及竞争租约的间隔
+}
+
+// RunWorker 运行指定名称的后台任务直到ctx结束, names为空时运行全部已注册任务;
+// 每个任务独立竞争租约, 同一时刻只有一个实例执行, 不同任务可分散在多个实例上; 退出时释放租约以便其他实例立即接管
+func RunWorker(ctx context.Context, names []string) error {
+	//1.选择任务
+	selected, err := selectJobs(names)
+	if err != nil {
+		return err
+	}
+
+	//2.加载租约配置
+	w := &worker{
+		instance: g.Cfg().MustGet(ctx, "worker.instance").String(),
+		ttl:      g.Cfg().MustGet(ctx, "worker.leaseTTL", "30s").Duration(),
+	}
+	if w.instance == "" {
+		w.instance = defaultInstance()
+	}
+	if w.ttl < minLeaseTTL {
+		return fmt.Errorf("worker.leaseTTL must be at least %s", minLeaseTTL)
+	}
+	w.renew = w.ttl / 3
+
+	//3.每个任务独立竞争租约
+	selectedNames := make([]string, 0, len(selected))
+	for _, job := range selected {
+		selectedNames = append(selectedNames, job.Name)
+	}
+	g.Log().Infof(ctx, "worker %s running jobs: %s", w.instance, strings.Join(selectedNames, ","))
+
+	var wg sync.WaitGroup
+	for _, job := range selected {
+		wg.Add(1)
+		go func(job *Job) {
+			defer wg.Done()
+			w.contend(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+	return nil
+}
+
+// selectJobs 按名称选择已注册的任务
+func selectJobs(names []string) ([]*Job, error) {
+	if len(names) == 0 {
+		return jobs, nil
+	}
+	registered := make(map[string]*Job, len(jobs))
+	available := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		registered[job.Name] = job
+		available = append(available, job.Name)
+	}
+	selected := make([]*Job, 0, len(names))
+	for _, name := range names {
+		job, ok := registered[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown job %s, available jobs: %s", name, strings.Join(available, ","))
+		}
+		selected = append(selected, job)
+	}
+	return selected, nil
+}
+
+// defaultInstance 默认实例标识, 在kubernetes中主机名即pod名称
+func defaultInstance() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// contend 竞争任务租约, 成为leader后执行任务直到失去租约, 之后继续竞争
+func (w *worker) contend(ctx context.Context, job *Job) {
+	for {
+		leader, err := service.Worker().Acquire(ctx, job.Name, w.instance, w.ttl)
+		if err != nil && ctx.Err() == nil {
+			g.Log().Warningf(ctx, "job %s acquire lease failed: %v", job.Name, err)
+		}
+		if leader {
+			w.lead(ctx, job)
+		}
+		if !sleep(ctx, w.renew) {
+			return
+		}
+	}
+}
+
+// lead 持有租约期间执行任务并定期续约;
+// 租约被其他实例接管, 或数据库不可用导致下次续约前租约就会过期时, 停止任务并等待其退出
+func (w *worker) lead(ctx context.Context, job *Job) {
+	g.Log().Infof(ctx, "job %s lease acquired by %s", job.Name, w.instance)
+	renewed := time.Now()
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.execute(jobCtx, job)
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+
+	ticker := time.NewTicker(w.renew)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+			if err := service.Worker().Release(context.WithoutCancel(ctx), job.Name, w.instance); err != nil {
+				g.Log().Warningf(ctx, "job %s release lease failed: %v", job.Name, err)
+			}
+			return
+		case <-ticker.C:
+			start := time.Now()
+			leader, err := service.Worker().Acquire(ctx, job.Name, w.instance, w.ttl)
+			if err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				g.Log().Warningf(ctx, "job %s renew lease failed: %v", job.Name, err)
+				if time.Since(renewed)+w.renew < w.ttl {
+					continue
+				}
+				g.Log().Errorf(ctx, "job %s lease about to expire, stopping", job.Name)
+				stop()
+				return
+			}
+			if !leader {
+				g.Log().Warningf(ctx, "job %s lease taken over by another instance, stopping", job.Name)
+				stop()
+				return
+			}
+			renewed = start
+		}
+	}
+}
+
+// execute 执行任务直到ctx结束: 周期任务每轮结束后按间隔等待, 常驻任务退出时等待续约间隔后重启
+func (w *worker) execute(ctx context.Context, job *Job) {
+	var interval time.Duration
+	if job.IntervalKey != "" {
+		interval = g.Cfg().MustGet(ctx, job.IntervalKey, job.Interval).Duration()
+	}
+
+	for {
+		if err := service.Worker().Start(ctx, job.Name, w.instance); err != nil && ctx.Err() == nil {
+			g.Log().Warningf(ctx, "job %s report start failed: %v", job.Name, err)
+		}
+		err := w.run(ctx, job)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && interval == 0 {
+			err = errors.New("job exited unexpectedly")
+		}
+		if err != nil {
+			g.Log().Errorf(ctx, "job %s failed: %v", job.Name, err)
+		}
+		if err := service.Worker().Finish(ctx, job.Name, w.instance, err); err != nil && ctx.Err() == nil {
+			g.Log().Warningf(ctx, "job %s report finish failed: %v", job.Name, err)
+		}
+
+		wait := interval
+		if wait == 0 {
+			wait = w.renew
+		}
+		if !sleep(ctx, wait) {
+			return
+		}
+	}
+}
+
+// run 执行一轮任务, panic转换为错误, 避免单个任务导致整个worker退出
+func (w *worker) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// sleep 等待指定时长, ctx结束时提前返回false
+func sleep(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,24 @@
+-- nonce管理(MySQL): 首次发送时以 INSERT IGNORE 创建nonce账户, 依赖(chain_id, address)唯一索引,
+-- 缺少该索引时并发的首次发送会创建重复账户并分配相同的nonce
+CREATE TABLE IF NOT EXISTS `nonce_account` (
+    `id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `chain_id`   BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID',
+    `address`    VARCHAR(42)     NOT NULL DEFAULT '' COMMENT '地址',
+    `next_nonce` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '下一个待分配的nonce',
+    `updated_at` BIGINT          NOT NULL DEFAULT 0 COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_nonce_account_chain_id_address` (`chain_id`, `address`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '地址nonce账户';
+
+CREATE TABLE IF NOT EXISTS `nonce_reservation` (
+    `id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `chain_id`   BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID',
+    `address`    VARCHAR(42)     NOT NULL DEFAULT '' COMMENT '地址',
+    `nonce`      BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '预留的nonce',
+    `tx_hash`    VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '交易哈希',
+    `status`     TINYINT         NOT NULL DEFAULT 0 COMMENT '状态: 0已预留 1已广播 2已释放',
+    `created_at` BIGINT          NOT NULL DEFAULT 0 COMMENT '创建时间',
+    `updated_at` BIGINT          NOT NULL DEFAULT 0 COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_nonce_reservation_chain_id_address_nonce` (`chain_id`, `address`, `nonce`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = 'nonce预留记录';
@@ -0,0 +1,34 @@
+-- 合约按链ID区分(替代原network网络名称列): 新增chain_id并按网络名称回填, 合约调用记录同步新增chain_id;
+-- chain_id为0的合约不会被事件索引, 按地址调用时也查不到, 执行后请检查第4步的查询结果
+ALTER TABLE `contract` ADD COLUMN `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID' AFTER `bytecode`;
+ALTER TABLE `contract_call` ADD COLUMN `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID' AFTER `contract_id`;
+
+-- 1.按链注册表中的链名称匹配
+UPDATE `contract` c JOIN `chain` ch ON LOWER(ch.`name`) = LOWER(c.`network`)
+SET c.`chain_id` = ch.`chain_id`
+WHERE c.`chain_id` = 0;
+
+-- 2.常用网络别名, 旧版本部署接口未传network时为ethereum
+UPDATE `contract` SET `chain_id` = CASE LOWER(`network`)
+    WHEN '' THEN 1
+    WHEN 'ethereum' THEN 1
+    WHEN 'mainnet' THEN 1
+    WHEN 'bsc' THEN 56
+    WHEN 'bnb' THEN 56
+    WHEN 'polygon' THEN 137
+    WHEN 'matic' THEN 137
+    WHEN 'sepolia' THEN 11155111
+    ELSE 0
+END
+WHERE `chain_id` = 0;
+
+-- 3.合约调用记录使用所属合约的链, 待确认的调用由交易跟踪任务在正确的链上查询回执
+UPDATE `contract_call` cc JOIN `contract` c ON c.`id` = cc.`contract_id`
+SET cc.`chain_id` = c.`chain_id`
+WHERE cc.`chain_id` = 0;
+
+-- 4.无法识别网络的合约, 需手动设置chain_id
+SELECT `id`, `name`, `address`, `network` FROM `contract` WHERE `chain_id` = 0;
+
+-- 5.确认回填无误后删除network列
+-- ALTER TABLE `contract` DROP COLUMN `network`;
@@ -0,0 +1,2 @@
+-- 跨链解锁交易在广播前保存已签名的原始交易, 重复处理同一锁定事件时重新广播该交易而不是签名新的解锁交易
+ALTER TABLE `cross_transfer` ADD COLUMN `unlock_tx` TEXT NULL COMMENT '已签名的解锁交易(hex)' AFTER `to_hash`;
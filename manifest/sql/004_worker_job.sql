@@ -0,0 +1,19 @@
+-- 后台任务租约(MySQL): 各worker实例以 INSERT IGNORE 创建任务行后竞争同一行租约, 依赖name唯一索引,
+-- 缺少该索引时每个实例各自插入一行并都能获取租约, 同一任务会在多个副本上同时执行
+CREATE TABLE IF NOT EXISTS `worker_job` (
+    `id`               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `name`             VARCHAR(64)     NOT NULL DEFAULT '' COMMENT '任务名称',
+    `holder`           VARCHAR(128)    NOT NULL DEFAULT '' COMMENT '租约持有实例, 释放后为空',
+    `lease_expires_at` BIGINT          NOT NULL DEFAULT 0 COMMENT '租约到期时间',
+    `lease_version`    BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '每次获取或续约递增',
+    `state`            VARCHAR(16)     NOT NULL DEFAULT '' COMMENT '运行状态',
+    `runs`             BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '累计执行次数',
+    `failures`         BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '累计失败次数',
+    `last_started_at`  BIGINT          NOT NULL DEFAULT 0 COMMENT '最近一次开始时间',
+    `last_finished_at` BIGINT          NOT NULL DEFAULT 0 COMMENT '最近一次结束时间',
+    `last_error`       TEXT            NULL COMMENT '最近一次错误',
+    `last_error_at`    BIGINT          NOT NULL DEFAULT 0 COMMENT '最近一次错误时间',
+    `updated_at`       BIGINT          NOT NULL DEFAULT 0 COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_worker_job_name` (`name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '后台任务租约与运行状态';
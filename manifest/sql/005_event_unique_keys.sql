@@ -0,0 +1,32 @@
+-- 事件索引幂等写入(MySQL): 重新索引时以 INSERT IGNORE 写入合约事件与NFT转移记录,
+-- 依赖(chain_id, tx_hash/hash, log_index)唯一索引, 缺少该索引时重复索引同一区块范围会写入重复记录
+ALTER TABLE `contract_event` ADD COLUMN `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID' AFTER `contract_id`;
+ALTER TABLE `nft_transfer`
+    ADD COLUMN `chain_id`  BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID, 仅链上索引的记录设置' AFTER `hash`,
+    ADD COLUMN `log_index` INT UNSIGNED    NOT NULL DEFAULT 0 COMMENT '日志索引, 仅链上索引的记录设置' AFTER `chain_id`;
+
+-- 1.合约事件使用所属合约的链(需先执行002_contract_chain_id.sql回填合约chain_id)
+UPDATE `contract_event` e JOIN `contract` c ON c.`id` = e.`contract_id`
+SET e.`chain_id` = c.`chain_id`
+WHERE e.`chain_id` = 0;
+
+-- 2.删除此前重复索引写入的合约事件, 保留ID最小的一条
+DELETE e FROM `contract_event` e
+JOIN `contract_event` k ON k.`chain_id` = e.`chain_id` AND k.`tx_hash` = e.`tx_hash` AND k.`log_index` = e.`log_index` AND k.`id` < e.`id`;
+
+-- 3.删除内容完全相同的NFT转移记录, 保留ID最小的一条
+DELETE t FROM `nft_transfer` t
+JOIN `nft_transfer` k ON k.`hash` = t.`hash` AND k.`nft_id` = t.`nft_id` AND k.`from` = t.`from` AND k.`to` = t.`to`
+    AND k.`amount` = t.`amount` AND k.`type` = t.`type` AND k.`chain_id` = t.`chain_id` AND k.`log_index` = t.`log_index` AND k.`id` < t.`id`;
+
+-- 4.历史记录没有日志索引, 同一交易内的多条转移按ID依次编号以满足唯一索引;
+-- 这些记录的chain_id为0, 不会与链上索引写入的记录冲突
+UPDATE `nft_transfer` t JOIN (
+    SELECT `id`, ROW_NUMBER() OVER (PARTITION BY `hash` ORDER BY `id`) - 1 AS `seq`
+    FROM `nft_transfer` WHERE `chain_id` = 0
+) r ON r.`id` = t.`id`
+SET t.`log_index` = r.`seq`;
+
+-- 5.创建唯一索引
+ALTER TABLE `contract_event` ADD UNIQUE KEY `uk_contract_event_chain_id_tx_hash_log_index` (`chain_id`, `tx_hash`, `log_index`);
+ALTER TABLE `nft_transfer` ADD UNIQUE KEY `uk_nft_transfer_chain_id_hash_log_index` (`chain_id`, `hash`, `log_index`);
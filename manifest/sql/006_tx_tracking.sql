@@ -0,0 +1,23 @@
+-- 交易生命周期跟踪(MySQL): 以 INSERT IGNORE 登记待跟踪交易, 依赖(source, source_id, tx_hash)唯一索引,
+-- 缺少该索引时同一交易会被重复登记, 每轮跟踪重复查询并回写业务表
+CREATE TABLE IF NOT EXISTS `tx_tracking` (
+    `id`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `chain_id`      BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID',
+    `tx_hash`       VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '交易哈希',
+    `source`        VARCHAR(64)     NOT NULL DEFAULT '' COMMENT '来源表',
+    `source_id`     BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '来源记录ID',
+    `from_address`  VARCHAR(42)     NOT NULL DEFAULT '' COMMENT '发送地址, 节点首次返回交易后记录',
+    `nonce`         BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '交易nonce',
+    `state`         TINYINT         NOT NULL DEFAULT 0 COMMENT '状态: 0已广播 1已打包 2已确认 3已最终确定 4执行失败 5被丢弃 6被替换',
+    `block_number`  BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '所在区块高度',
+    `block_hash`    VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '所在区块哈希',
+    `confirmations` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '确认数',
+    `replaced_by`   VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '替换交易哈希',
+    `error`         TEXT            NULL COMMENT '错误信息',
+    `created_at`    BIGINT          NOT NULL DEFAULT 0 COMMENT '创建时间',
+    `updated_at`    BIGINT          NOT NULL DEFAULT 0 COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_tx_tracking_source_source_id_tx_hash` (`source`, `source_id`, `tx_hash`),
+    KEY `idx_tx_tracking_chain_id_tx_hash` (`chain_id`, `tx_hash`),
+    KEY `idx_tx_tracking_state` (`state`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '交易生命周期跟踪';
@@ -0,0 +1,11 @@
+-- 事件索引游标(MySQL): 以 ON DUPLICATE KEY UPDATE 推进游标, 依赖(chain_id, source)唯一索引,
+-- 缺少该索引时每次保存都会插入新行, 读取到的游标不再前进, 同一区块范围被反复索引
+CREATE TABLE IF NOT EXISTS `indexer_cursor` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `chain_id`     BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID',
+    `source`       VARCHAR(128)    NOT NULL DEFAULT '' COMMENT '索引来源',
+    `block_number` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '已处理的最高区块',
+    `updated_at`   BIGINT          NOT NULL DEFAULT 0 COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_indexer_cursor_chain_id_source` (`chain_id`, `source`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '索引游标';
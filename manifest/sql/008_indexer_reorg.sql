@@ -0,0 +1,30 @@
+-- 重组检测(MySQL): 已处理区块以 ON DUPLICATE KEY UPDATE 写入, 已处理日志以 INSERT IGNORE 写入,
+-- 依赖以下唯一索引; 缺少时同一高度保存多个哈希导致误判重组, 回滚时同一日志被重复撤销
+CREATE TABLE IF NOT EXISTS `indexer_block` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `chain_id`     BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID',
+    `event_group`  VARCHAR(32)     NOT NULL DEFAULT '' COMMENT '事件索引分组',
+    `block_number` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '区块高度',
+    `block_hash`   VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '处理时的区块哈希',
+    `created_at`   BIGINT          NOT NULL DEFAULT 0 COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_indexer_block_chain_id_event_group_block_number` (`chain_id`, `event_group`, `block_number`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '事件索引已处理区块';
+
+CREATE TABLE IF NOT EXISTS `indexer_log` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `chain_id`     BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID',
+    `source`       VARCHAR(128)    NOT NULL DEFAULT '' COMMENT '事件来源',
+    `address`      VARCHAR(42)     NOT NULL DEFAULT '' COMMENT '合约地址',
+    `topics`       TEXT            NULL COMMENT '主题, 逗号分隔',
+    `data`         MEDIUMTEXT      NULL COMMENT '日志数据(hex)',
+    `block_number` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '区块高度',
+    `block_hash`   VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '区块哈希',
+    `tx_hash`      VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '交易哈希',
+    `tx_index`     INT UNSIGNED    NOT NULL DEFAULT 0 COMMENT '交易索引',
+    `log_index`    INT UNSIGNED    NOT NULL DEFAULT 0 COMMENT '日志索引',
+    `created_at`   BIGINT          NOT NULL DEFAULT 0 COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_indexer_log_chain_id_source_tx_hash_log_index` (`chain_id`, `source`, `tx_hash`, `log_index`),
+    KEY `idx_indexer_log_chain_id_block_number` (`chain_id`, `block_number`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '重组窗口内已处理的事件日志';
@@ -0,0 +1,26 @@
+-- 事件死信(MySQL): 同一日志再次失败时按(chain_id, source, tx_hash, log_index)查找并更新已有记录,
+-- 该唯一索引保证同一日志只有一条记录, 重试认领以该行的status与attempts为条件
+CREATE TABLE IF NOT EXISTS `event_dead_letter` (
+    `id`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID',
+    `chain_id`      BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链ID',
+    `event_group`   VARCHAR(32)     NOT NULL DEFAULT '' COMMENT '事件索引分组',
+    `source`        VARCHAR(128)    NOT NULL DEFAULT '' COMMENT '事件来源',
+    `address`       VARCHAR(42)     NOT NULL DEFAULT '' COMMENT '合约地址',
+    `topics`        TEXT            NULL COMMENT '主题, 逗号分隔',
+    `data`          MEDIUMTEXT      NULL COMMENT '日志数据(hex)',
+    `block_number`  BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '区块高度',
+    `block_hash`    VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '区块哈希',
+    `tx_hash`       VARCHAR(66)     NOT NULL DEFAULT '' COMMENT '交易哈希',
+    `tx_index`      INT UNSIGNED    NOT NULL DEFAULT 0 COMMENT '交易索引',
+    `log_index`     INT UNSIGNED    NOT NULL DEFAULT 0 COMMENT '日志索引',
+    `replay`        TINYINT(1)      NOT NULL DEFAULT 0 COMMENT '是否在回放模式下失败',
+    `attempts`      INT             NOT NULL DEFAULT 0 COMMENT '已执行次数, 包括首次处理',
+    `error`         TEXT            NULL COMMENT '最近一次错误',
+    `status`        TINYINT         NOT NULL DEFAULT 0 COMMENT '状态: 0待重试 1重试成功 2已丢弃 3达到最大重试次数 4重试中',
+    `next_retry_at` BIGINT          NOT NULL DEFAULT 0 COMMENT '下次重试时间',
+    `created_at`    BIGINT          NOT NULL DEFAULT 0 COMMENT '创建时间',
+    `updated_at`    BIGINT          NOT NULL DEFAULT 0 COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_event_dead_letter_chain_id_source_tx_hash_log_index` (`chain_id`, `source`, `tx_hash`, `log_index`),
+    KEY `idx_event_dead_letter_status_next_retry_at` (`status`, `next_retry_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '处理失败的事件日志';